@@ -0,0 +1,619 @@
+// Package book turns the lessons in this repository into a book that can be read in a browser.
+//
+// Each lesson is parsed with go/parser. Comments that sit on their own lines at the top level of a section's function
+// become prose and everything else becomes a code listing. Sections are split wherever the code calls
+// tutorial.Section, so each heading a lesson prints when it runs becomes a heading in the book. Lessons appear in the
+// README's reading order, with the titles the README gives them.
+package book
+
+import (
+	"github.com/benhalstead/gotraining/tutorial"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Book is every lesson in reading order
+type Book struct {
+	Chapters []Chapter
+}
+
+// A Chapter is a titled group of lessons
+type Chapter struct {
+	Title   string
+	Lessons []*Lesson
+}
+
+// A Lesson is the parsed content of one lesson's source file
+type Lesson struct {
+	ID    string
+	Title string
+
+	// The path of the source file, relative to the root of the repository
+	Source string
+
+	// Prose from the comments in the lesson's main function
+	Intro []Block
+
+	Parts []Part
+
+	// Packages from outside this repository that the lesson imports
+	Imports []string
+}
+
+// A Part is the code between one call to tutorial.Section and the next
+type Part struct {
+	// The name the section was registered with (e.g. select), so it can be run with gotraining run lesson#section.
+	// Empty for code that isn't part of a registered section.
+	Section string
+
+	Heading string
+	Blocks  []Block
+}
+
+// BlockKind distinguishes prose from code
+type BlockKind string
+
+const (
+	Prose BlockKind = "prose"
+	Code  BlockKind = "code"
+)
+
+// A Block is a run of prose or code. Prose has had its comment markers removed.
+type Block struct {
+	Kind BlockKind
+	Text string
+}
+
+// Load parses every lesson in the reading order. root is the directory containing this repository.
+func Load(root string) (*Book, error) {
+
+	titles := readmeTitles(root)
+
+	b := new(Book)
+
+	for _, c := range tutorial.ReadingOrder {
+
+		ch := Chapter{Title: c.Title}
+
+		for _, id := range c.Lessons {
+
+			l, err := ParseLesson(root, id)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if t, okay := titles[id]; okay {
+				l.Title = t
+			}
+
+			ch.Lessons = append(ch.Lessons, l)
+		}
+
+		b.Chapters = append(b.Chapters, ch)
+	}
+
+	return b, nil
+}
+
+// Lessons returns every lesson in the book in reading order
+func (b *Book) Lessons() []*Lesson {
+
+	var ls []*Lesson
+
+	for _, c := range b.Chapters {
+		ls = append(ls, c.Lessons...)
+	}
+
+	return ls
+}
+
+// A link to a lesson's source in the README's reading order, e.g. [Maps](structures/maps.go)
+var readmeLink = regexp.MustCompile(`\[([^\]]+)\]\(([\w/]+?)(_test)?\.go\)`)
+
+// readmeTitles returns the title the README gives each lesson, keyed by ID
+func readmeTitles(root string) map[string]string {
+
+	titles := make(map[string]string)
+
+	b, err := os.ReadFile(filepath.Join(root, "README.md"))
+
+	if err != nil {
+		return titles
+	}
+
+	for _, m := range readmeLink.FindAllStringSubmatch(string(b), -1) {
+		titles[m[2]] = m[1]
+	}
+
+	return titles
+}
+
+// ParseLesson parses the source of the lesson with the supplied ID (e.g. concurrency/channels)
+func ParseLesson(root, id string) (*Lesson, error) {
+
+	l := &Lesson{
+		ID:     id,
+		Title:  id,
+		Source: id + ".go",
+	}
+
+	p, err := parseFile(root, l.Source)
+
+	if err != nil {
+		return nil, err
+	}
+
+	f := p.file
+
+	for _, i := range f.Imports {
+
+		if ip, err := strconv.Unquote(i.Path.Value); err == nil && !strings.HasPrefix(ip, "github.com/benhalstead/gotraining") {
+			l.Imports = append(l.Imports, ip)
+		}
+	}
+
+	sections := p.sections()
+	used := make(map[string]bool)
+
+	for _, s := range sections {
+
+		fd := p.funcDecl(s.function)
+
+		if fd == nil {
+			continue
+		}
+
+		used[s.function] = true
+		l.Parts = append(l.Parts, p.split(s.name, fd.Body)...)
+	}
+
+	if main := p.funcDecl("main"); main != nil && f.Name.Name == "main" {
+
+		used["main"] = true
+
+		for _, b := range p.blocks(main.Body, main.Body.Lbrace+1, main.Body.Rbrace) {
+			if b.Kind == Prose {
+				l.Intro = append(l.Intro, b)
+			}
+		}
+	}
+
+	// Everything else (types and functions the sections use, or the whole file if it isn't a runnable lesson) is
+	// listed at the end
+	if other := p.decls(used); len(other) > 0 {
+
+		heading := "Supporting code"
+
+		if len(sections) == 0 {
+			heading = "Code"
+		}
+
+		l.Parts = append(l.Parts, Part{Heading: heading, Blocks: other})
+	}
+
+	// Lessons that aren't programs (like unittests/code) are explained by their tests
+	if f.Name.Name != "main" {
+
+		tests, err := parseFile(root, id+"_test.go")
+
+		if err == nil {
+			l.Parts = append(l.Parts, Part{Heading: "Tests", Blocks: tests.decls(nil)})
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// Docs returns links to the documentation for the packages the lesson imports and any the lesson's comments cite
+func (l *Lesson) Docs() []string {
+
+	seen := make(map[string]bool)
+	var docs []string
+
+	add := func(u string) {
+		if !seen[u] {
+			seen[u] = true
+			docs = append(docs, u)
+		}
+	}
+
+	for _, i := range l.Imports {
+		add("https://pkg.go.dev/" + i)
+	}
+
+	for _, b := range l.allBlocks() {
+		if b.Kind == Prose {
+			for _, u := range urlPattern.FindAllString(b.Text, -1) {
+				if pkgDoc.MatchString(u) {
+					add(u)
+				}
+			}
+		}
+	}
+
+	return docs
+}
+
+func (l *Lesson) allBlocks() []Block {
+
+	bs := append([]Block(nil), l.Intro...)
+
+	for _, p := range l.Parts {
+		bs = append(bs, p.Blocks...)
+	}
+
+	return bs
+}
+
+// Page returns the path of the lesson's page in a book, relative to the book's root
+func (l *Lesson) Page(ext string) string {
+	return l.ID + ext
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s()<>"]+[^\s()<>".,;:'!?]`)
+
+// A link to the documentation of a package in the standard library
+var pkgDoc = regexp.MustCompile(`^https?://(golang\.org/pkg|pkg\.go\.dev)/`)
+
+type lessonParser struct {
+	fs   *token.FileSet
+	file *ast.File
+	src  []byte
+}
+
+func parseFile(root, name string) (*lessonParser, error) {
+
+	src, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, name, src, parser.ParseComments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &lessonParser{fs: fs, file: f, src: src}, nil
+}
+
+// decls returns a code block for each declaration in the file, apart from imports and the functions in used
+func (p *lessonParser) decls(used map[string]bool) []Block {
+
+	var bs []Block
+
+	for _, d := range p.file.Decls {
+
+		if gd, okay := d.(*ast.GenDecl); okay && gd.Tok == token.IMPORT {
+			continue
+		}
+
+		if fd, okay := d.(*ast.FuncDecl); okay && fd.Recv == nil && used[fd.Name.Name] {
+			continue
+		}
+
+		bs = append(bs, Block{Kind: Code, Text: p.declSource(d)})
+	}
+
+	return bs
+}
+
+type registration struct {
+	name     string
+	function string
+	pos      token.Pos
+}
+
+// sections finds the calls to Section in the chain that registers the lesson, returning them in the order they are
+// registered
+func (p *lessonParser) sections() []registration {
+
+	var regs []registration
+
+	ast.Inspect(p.file, func(n ast.Node) bool {
+
+		c, okay := n.(*ast.CallExpr)
+
+		if !okay || len(c.Args) != 2 {
+			return true
+		}
+
+		sel, okay := c.Fun.(*ast.SelectorExpr)
+
+		if !okay || sel.Sel.Name != "Section" {
+			return true
+		}
+
+		name, okay := stringLit(c.Args[0])
+		fn, isIdent := c.Args[1].(*ast.Ident)
+
+		if okay && isIdent {
+			regs = append(regs, registration{name: name, function: fn.Name, pos: c.Args[0].Pos()})
+		}
+
+		return true
+	})
+
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].pos < regs[j].pos
+	})
+
+	return regs
+}
+
+func (p *lessonParser) funcDecl(name string) *ast.FuncDecl {
+
+	for _, d := range p.file.Decls {
+		if fd, okay := d.(*ast.FuncDecl); okay && fd.Recv == nil && fd.Name.Name == name && fd.Body != nil {
+			return fd
+		}
+	}
+
+	return nil
+}
+
+// heading returns the argument to tutorial.Section if s is a call to it
+func heading(s ast.Stmt) (string, bool) {
+
+	es, okay := s.(*ast.ExprStmt)
+
+	if !okay {
+		return "", false
+	}
+
+	c, okay := es.X.(*ast.CallExpr)
+
+	if !okay || len(c.Args) != 1 {
+		return "", false
+	}
+
+	sel, okay := c.Fun.(*ast.SelectorExpr)
+
+	if !okay || sel.Sel.Name != "Section" {
+		return "", false
+	}
+
+	if pkg, okay := sel.X.(*ast.Ident); !okay || pkg.Name != "tutorial" {
+		return "", false
+	}
+
+	return stringLit(c.Args[0])
+}
+
+func stringLit(e ast.Expr) (string, bool) {
+
+	bl, okay := e.(*ast.BasicLit)
+
+	if !okay || bl.Kind != token.STRING {
+		return "", false
+	}
+
+	s, err := strconv.Unquote(bl.Value)
+
+	return s, err == nil
+}
+
+// split divides a section's function into a Part for each call to tutorial.Section. Anything before the first call
+// belongs to the first part.
+func (p *lessonParser) split(section string, body *ast.BlockStmt) []Part {
+
+	var headings []ast.Stmt
+
+	for _, s := range body.List {
+		if _, okay := heading(s); okay {
+			headings = append(headings, s)
+		}
+	}
+
+	if len(headings) == 0 {
+		return []Part{{Section: section, Heading: section, Blocks: p.blocks(body, body.Lbrace+1, body.Rbrace)}}
+	}
+
+	var parts []Part
+
+	for i, h := range headings {
+
+		from, to := h.End(), body.Rbrace
+
+		if i == 0 {
+			from = body.Lbrace + 1
+		}
+
+		if i+1 < len(headings) {
+			to = headings[i+1].Pos()
+		}
+
+		text, _ := heading(h)
+
+		parts = append(parts, Part{Section: section, Heading: text, Blocks: p.blocks(body, from, to)})
+	}
+
+	return parts
+}
+
+// blocks divides the lines of body that start between from and to into prose and code. A comment is prose if it is on
+// lines of its own and isn't inside one of the body's statements (so comments inside closures stay with their code).
+func (p *lessonParser) blocks(body *ast.BlockStmt, from, to token.Pos) []Block {
+
+	tf := p.fs.File(body.Pos())
+
+	// The comment that starts on each line that is prose
+	prose := make(map[int]*ast.CommentGroup)
+	proseEnd := make(map[int]int)
+
+	for _, c := range p.file.Comments {
+
+		if c.Pos() < from || c.Pos() >= to || p.insideStatement(body, c) || !p.startsLine(c.Pos()) {
+			continue
+		}
+
+		start := tf.Line(c.Pos())
+		prose[start] = c
+		proseEnd[start] = tf.Line(c.End())
+	}
+
+	var bs []Block
+	var code []string
+
+	flushCode := func() {
+
+		if t := dedent(code); t != "" {
+			bs = append(bs, Block{Kind: Code, Text: t})
+		}
+
+		code = nil
+	}
+
+	for line := tf.Line(from); line <= tf.Line(to) && line <= tf.LineCount(); line++ {
+
+		start := tf.LineStart(line)
+		text := p.lineText(tf, line)
+		first := start + token.Pos(len(text)-len(strings.TrimLeft(text, " \t")))
+
+		if first < from || first >= to || isHeading(body, first) {
+			continue
+		}
+
+		if c, okay := prose[line]; okay {
+
+			flushCode()
+
+			t := strings.TrimRight(c.Text(), "\n")
+
+			if n := len(bs); n > 0 && bs[n-1].Kind == Prose {
+				bs[n-1].Text += "\n\n" + t
+			} else {
+				bs = append(bs, Block{Kind: Prose, Text: t})
+			}
+
+			line = proseEnd[line]
+			continue
+		}
+
+		code = append(code, text)
+	}
+
+	flushCode()
+
+	return bs
+}
+
+// isHeading reports whether pos is part of a call to tutorial.Section
+func isHeading(body *ast.BlockStmt, pos token.Pos) bool {
+
+	for _, s := range body.List {
+		if _, okay := heading(s); okay && pos >= s.Pos() && pos < s.End() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *lessonParser) insideStatement(body *ast.BlockStmt, c *ast.CommentGroup) bool {
+
+	for _, s := range body.List {
+		if c.Pos() > s.Pos() && c.End() <= s.End() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// startsLine reports whether there is nothing but whitespace before pos on its line
+func (p *lessonParser) startsLine(pos token.Pos) bool {
+
+	tf := p.fs.File(pos)
+	start := tf.Offset(tf.LineStart(tf.Line(pos)))
+
+	return strings.TrimSpace(string(p.src[start:tf.Offset(pos)])) == ""
+}
+
+func (p *lessonParser) lineText(tf *token.File, line int) string {
+
+	start := tf.Offset(tf.LineStart(line))
+	end := len(p.src)
+
+	if line < tf.LineCount() {
+		end = tf.Offset(tf.LineStart(line+1)) - 1
+	}
+
+	return strings.TrimRight(string(p.src[start:end]), " \t\r")
+}
+
+// declSource returns the source of a declaration, including its doc comment
+func (p *lessonParser) declSource(d ast.Decl) string {
+
+	start := d.Pos()
+
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+	}
+
+	tf := p.fs.File(start)
+
+	return string(p.src[tf.Offset(start):tf.Offset(d.End())])
+}
+
+// dedent removes leading and trailing blank lines and the indentation common to every line
+func dedent(lines []string) string {
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+
+	for _, l := range lines {
+
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+
+		if n := len(l) - len(strings.TrimLeft(l, "\t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	out := make([]string, len(lines))
+
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			out[i] = l[indent:]
+		} else {
+			out[i] = strings.TrimLeft(l, "\t")
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// relative returns the path to target from the page at from, where both are relative to the root of the book
+func relative(from, target string) string {
+	return strings.Repeat("../", strings.Count(path.Clean(from), "/")) + target
+}
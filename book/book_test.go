@@ -0,0 +1,102 @@
+package book
+
+import (
+	"strings"
+	"testing"
+)
+
+// The repository root, relative to this package
+const root = ".."
+
+func TestLessonIsSplitAtSections(t *testing.T) {
+
+	l, err := ParseLesson(root, "concurrency/channels")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var headings []string
+
+	for _, p := range l.Parts {
+
+		headings = append(headings, p.Heading)
+
+		for _, b := range p.Blocks {
+			if b.Kind == Code && strings.Contains(b.Text, "tutorial.Section(") {
+				t.Errorf("Calls to tutorial.Section should not appear in listings, found in %q", p.Heading)
+			}
+		}
+	}
+
+	want := []string{
+		"Initialising channels",
+		"Reading from and writing to blocking (unbuffered) channels",
+		"Reading from and writing to buffered channels",
+	}
+
+	for i, w := range want {
+		if i >= len(headings) || headings[i] != w {
+			t.Fatalf("Expected parts to start %q, got %q", want, headings)
+		}
+	}
+
+	first := l.Parts[0]
+
+	if first.Section != "basics" {
+		t.Errorf("Expected the first part to belong to the basics section, got %q", first.Section)
+	}
+
+	if len(first.Blocks) < 2 || first.Blocks[0].Kind != Prose || first.Blocks[1].Kind != Code {
+		t.Fatalf("Expected the first part to start with prose then code, got %+v", first.Blocks)
+	}
+
+	if !strings.HasPrefix(first.Blocks[1].Text, "var c chan int") {
+		t.Errorf("Expected listings to be unindented, got %q", first.Blocks[1].Text)
+	}
+
+	// Comments inside a closure stay with its code
+	for _, b := range l.Parts[1].Blocks {
+		if b.Kind == Prose && strings.Contains(b.Text, "Write an int to the channel") {
+			t.Errorf("A comment inside a closure was turned into prose")
+		}
+	}
+}
+
+func TestBookFollowsReadingOrder(t *testing.T) {
+
+	b, err := Load(root)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ls := b.Lessons()
+
+	if len(ls) == 0 || ls[0].ID != "output/output" {
+		t.Fatalf("Expected the book to start with output/output")
+	}
+
+	pages, err := b.HTML()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, l := range ls {
+
+		if l.Title == l.ID {
+			t.Errorf("No title found in the README for %s", l.ID)
+		}
+
+		if _, okay := pages[l.Page(".html")]; !okay {
+			t.Errorf("No page was rendered for %s", l.ID)
+		}
+	}
+
+	page := string(pages["essential/regexp.html"])
+
+	if !strings.Contains(page, `<a href="https://golang.org/pkg/regexp/">`) {
+		t.Errorf("Expected the regexp lesson to link to the package documentation it cites")
+	}
+}
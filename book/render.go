@@ -0,0 +1,477 @@
+package book
+
+import (
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"html"
+	"html/template"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// HTML renders the book as a set of HTML pages, keyed by their path relative to the root of the book. The contents
+// are at index.html and each lesson is at its ID with .html added (e.g. concurrency/channels.html).
+func (b *Book) HTML() (map[string][]byte, error) {
+
+	pages := map[string][]byte{"style.css": []byte(stylesheet)}
+
+	var buf bytes.Buffer
+
+	if err := indexTemplate.Execute(&buf, b); err != nil {
+		return nil, err
+	}
+
+	pages["index.html"] = buf.Bytes()
+
+	lessons := b.Lessons()
+
+	for i, l := range lessons {
+
+		page := lessonPage{Lesson: l, Book: b, path: l.Page(".html")}
+
+		if i > 0 {
+			page.Previous = lessons[i-1]
+		}
+
+		if i+1 < len(lessons) {
+			page.Next = lessons[i+1]
+		}
+
+		var buf bytes.Buffer
+
+		if err := lessonTemplate.Execute(&buf, page); err != nil {
+			return nil, fmt.Errorf("rendering %s: %s", l.ID, err.Error())
+		}
+
+		pages[page.path] = buf.Bytes()
+	}
+
+	return pages, nil
+}
+
+// Markdown renders the book as a set of Markdown pages, laid out in the same way as HTML but with .md files
+func (b *Book) Markdown() map[string][]byte {
+
+	pages := make(map[string][]byte)
+
+	var idx strings.Builder
+
+	idx.WriteString("# Go training\n")
+
+	for _, c := range b.Chapters {
+
+		fmt.Fprintf(&idx, "\n## %s\n\n", c.Title)
+
+		for _, l := range c.Lessons {
+			fmt.Fprintf(&idx, "1. [%s](%s)\n", l.Title, l.Page(".md"))
+		}
+	}
+
+	pages["index.md"] = []byte(idx.String())
+
+	lessons := b.Lessons()
+
+	for i, l := range lessons {
+
+		var md strings.Builder
+		p := l.Page(".md")
+
+		fmt.Fprintf(&md, "# %s\n\n", l.Title)
+		fmt.Fprintf(&md, "Source: [%s](%s) · Run it with `gotraining run %s`\n", l.Source, relative(p, "../"+l.Source), l.ID)
+
+		writeMarkdownBlocks(&md, l.Intro)
+
+		var section string
+
+		for _, part := range l.Parts {
+
+			fmt.Fprintf(&md, "\n## %s\n", part.Heading)
+
+			if part.Section != "" && part.Section != section {
+				fmt.Fprintf(&md, "\nRun this section with `gotraining run %s#%s`\n", l.ID, part.Section)
+			}
+
+			section = part.Section
+
+			writeMarkdownBlocks(&md, part.Blocks)
+		}
+
+		if docs := l.Docs(); len(docs) > 0 {
+
+			md.WriteString("\n## Package documentation\n\n")
+
+			for _, d := range docs {
+				fmt.Fprintf(&md, "* <%s>\n", d)
+			}
+		}
+
+		md.WriteString("\n---\n\n")
+
+		if i > 0 {
+			fmt.Fprintf(&md, "Previous: [%s](%s) · ", lessons[i-1].Title, relative(p, lessons[i-1].Page(".md")))
+		}
+
+		fmt.Fprintf(&md, "[Contents](%s)", relative(p, "index.md"))
+
+		if i+1 < len(lessons) {
+			fmt.Fprintf(&md, " · Next: [%s](%s)", lessons[i+1].Title, relative(p, lessons[i+1].Page(".md")))
+		}
+
+		md.WriteString("\n")
+
+		pages[p] = []byte(md.String())
+	}
+
+	return pages
+}
+
+func writeMarkdownBlocks(md *strings.Builder, blocks []Block) {
+
+	for _, b := range blocks {
+
+		if b.Kind == Code {
+			fmt.Fprintf(md, "\n```go\n%s\n```\n", b.Text)
+			continue
+		}
+
+		for _, para := range paragraphs(b.Text) {
+			fmt.Fprintf(md, "\n%s\n", urlPattern.ReplaceAllString(strings.Join(para, "  \n"), "<$0>"))
+		}
+	}
+}
+
+// Write saves rendered pages under dir
+func Write(dir string, pages map[string][]byte) error {
+
+	for p, content := range pages {
+
+		f := filepath.Join(dir, filepath.FromSlash(p))
+
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(f, content, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler serves the book as HTML. The lessons are parsed again for every page requested, so changes to a lesson
+// show up as soon as its page is reloaded.
+func Handler(root string) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		p := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+
+		if p == "" {
+			p = "index.html"
+		}
+
+		b, err := Load(root)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		pages, err := b.HTML()
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		content, okay := pages[p]
+
+		if !okay {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(p)))
+		w.Write(content)
+	})
+}
+
+type lessonPage struct {
+	*Lesson
+	Book     *Book
+	Previous *Lesson
+	Next     *Lesson
+	path     string
+}
+
+// Rel returns the path from this page to another page in the book
+func (lp lessonPage) Rel(target string) string {
+	return relative(lp.path, target)
+}
+
+// SourceLink returns the path from this page to the lesson's source, assuming the book is written to a directory in
+// the root of the repository
+func (lp lessonPage) SourceLink() string {
+	return relative(lp.path, "../"+lp.Source)
+}
+
+// FirstOf reports whether the part at index i is the first part of its section
+func (lp lessonPage) FirstOf(i int) bool {
+	return lp.Parts[i].Section != "" && (i == 0 || lp.Parts[i-1].Section != lp.Parts[i].Section)
+}
+
+var funcs = template.FuncMap{
+	"prose": proseHTML,
+	"code":  highlight,
+	"anchor": func(s string) string {
+		return strings.Trim(nonWord.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	},
+}
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// paragraphs splits prose into paragraphs, each made up of the lines that should be kept on lines of their own. Lines
+// are joined together unless they look like items in a list, links or indented text.
+func paragraphs(text string) [][]string {
+
+	var paras [][]string
+
+	for _, p := range strings.Split(trimIndent(text), "\n\n") {
+
+		var lines []string
+
+		for _, l := range strings.Split(p, "\n") {
+
+			t := strings.TrimSpace(l)
+
+			if t == "" {
+				continue
+			}
+
+			if len(lines) == 0 || startsOwnLine(l) {
+				lines = append(lines, t)
+			} else {
+				lines[len(lines)-1] += " " + t
+			}
+		}
+
+		if len(lines) > 0 {
+			paras = append(paras, lines)
+		}
+	}
+
+	return paras
+}
+
+// trimIndent removes the indentation common to every line of text (e.g. from a /* */ comment inside a function)
+func trimIndent(text string) string {
+
+	lines := strings.Split(text, "\n")
+	indent := -1
+
+	for _, l := range lines {
+
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+
+		if n := len(l) - len(strings.TrimLeft(l, " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[i] = l[indent:]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+var listItem = regexp.MustCompile(`^(\s+|[-*•]\s|\d+[.)]\s|https?://)`)
+
+func startsOwnLine(l string) bool {
+	return listItem.MatchString(l)
+}
+
+// proseHTML renders prose as HTML paragraphs, turning any URLs into links
+func proseHTML(text string) template.HTML {
+
+	var b strings.Builder
+
+	for _, para := range paragraphs(text) {
+
+		b.WriteString("<p>")
+
+		for i, l := range para {
+
+			if i > 0 {
+				b.WriteString("<br>\n")
+			}
+
+			b.WriteString(linkify(l))
+		}
+
+		b.WriteString("</p>\n")
+	}
+
+	return template.HTML(b.String())
+}
+
+// linkify escapes text for HTML, turning any URLs into links
+func linkify(text string) string {
+
+	var b strings.Builder
+	last := 0
+
+	for _, m := range urlPattern.FindAllStringIndex(text, -1) {
+
+		u := text[m[0]:m[1]]
+
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(u), html.EscapeString(u))
+
+		last = m[1]
+	}
+
+	b.WriteString(html.EscapeString(text[last:]))
+
+	return b.String()
+}
+
+// highlight escapes Go source for HTML, wrapping keywords, literals and comments in spans so they can be styled
+func highlight(src string) template.HTML {
+
+	fs := token.NewFileSet()
+	f := fs.AddFile("", fs.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(f, []byte(src), nil, scanner.ScanComments)
+
+	var b strings.Builder
+	last := 0
+
+	for {
+
+		pos, tok, lit := s.Scan()
+
+		if tok == token.EOF {
+			break
+		}
+
+		class := ""
+
+		switch {
+		case tok.IsKeyword():
+			class = "kw"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "str"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "num"
+		case tok == token.COMMENT:
+			class = "com"
+		}
+
+		if class == "" {
+			continue
+		}
+
+		start := f.Offset(pos)
+		end := start + len(lit)
+
+		if tok.IsKeyword() {
+			end = start + len(tok.String())
+		}
+
+		b.WriteString(html.EscapeString(src[last:start]))
+		fmt.Fprintf(&b, `<span class="%s">%s</span>`, class, html.EscapeString(src[start:end]))
+
+		last = end
+	}
+
+	b.WriteString(html.EscapeString(src[last:]))
+
+	return template.HTML(b.String())
+}
+
+var indexTemplate = template.Must(template.New("index").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Go training</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<h1>Go training</h1>
+{{range .Chapters}}
+<h2>{{.Title}}</h2>
+<ol>
+{{- range .Lessons}}
+<li><a href="{{.Page ".html"}}">{{.Title}}</a></li>
+{{- end}}
+</ol>
+{{end}}
+</body>
+</html>
+`))
+
+var lessonTemplate = template.Must(template.New("lesson").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Go training</title>
+<link rel="stylesheet" href="{{.Rel "style.css"}}">
+</head>
+<body>
+<nav>
+{{- with .Previous}}<a href="{{$.Rel (.Page ".html")}}">&larr; {{.Title}}</a> · {{end -}}
+<a href="{{.Rel "index.html"}}">Contents</a>
+{{- with .Next}} · <a href="{{$.Rel (.Page ".html")}}">{{.Title}} &rarr;</a>{{end}}
+</nav>
+<h1>{{.Title}}</h1>
+<p class="run">Source: <a href="{{.SourceLink}}">{{.Source}}</a> · Run it with <code>gotraining run {{.ID}}</code></p>
+{{range .Intro}}{{prose .Text}}{{end}}
+{{- range $i, $p := .Parts}}
+<h2 id="{{anchor .Heading}}">{{.Heading}}</h2>
+{{- if $.FirstOf $i}}
+<p class="run">Run this section with <code>gotraining run {{$.ID}}#{{.Section}}</code></p>
+{{- end}}
+{{range .Blocks}}{{if eq .Kind "code"}}<pre><code>{{code .Text}}</code></pre>
+{{else}}{{prose .Text}}{{end}}{{end}}
+{{- end}}
+{{- with .Docs}}
+<h2 id="package-documentation">Package documentation</h2>
+<ul>
+{{- range .}}
+<li><a href="{{.}}">{{.}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+<nav>
+{{- with .Previous}}<a href="{{$.Rel (.Page ".html")}}">&larr; {{.Title}}</a> · {{end -}}
+<a href="{{.Rel "index.html"}}">Contents</a>
+{{- with .Next}} · <a href="{{$.Rel (.Page ".html")}}">{{.Title}} &rarr;</a>{{end}}
+</nav>
+</body>
+</html>
+`))
+
+const stylesheet = `body { max-width: 50em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; color: #222; }
+nav { margin: 1em 0; font-size: 0.9em; }
+pre { background: #f6f8fa; padding: 0.75em 1em; overflow-x: auto; border-radius: 4px; }
+code { font-family: Menlo, Consolas, monospace; font-size: 0.9em; }
+.run { color: #666; font-size: 0.9em; }
+.kw { color: #a626a4; }
+.str { color: #50a14f; }
+.num { color: #986801; }
+.com { color: #a0a1a7; font-style: italic; }
+`
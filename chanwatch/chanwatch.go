@@ -0,0 +1,462 @@
+// Package chanwatch wraps a channel so you can see what is happening to it: how full it is, how many values have ever
+// been waiting to be received, how long senders and receivers have spent blocked, and which sends and receives are
+// blocked right now.
+//
+// A program that hangs is usually stuck on a channel operation that will never complete, like a send on an
+// unbuffered channel that nobody reads any more. If a watched channel is given a threshold, any send or receive that
+// stays blocked for longer than the threshold is reported (logged, or passed to a callback) along with the file, line
+// and function it was called from.
+//
+//	jobs := chanwatch.New[Job](10, chanwatch.Options{Name: "jobs", Threshold: 5 * time.Second})
+//
+//	jobs.Send(job) // logs "chanwatch: send on jobs blocked for 5s at server.go:81 (main.enqueue)" if nobody is reading
+//
+// Watching can be turned off for the whole program with SetEnabled(false), after which each operation costs little
+// more than using the channel directly (an atomic load and a function call).
+package chanwatch
+
+import (
+	"context"
+	"fmt"
+	"github.com/benhalstead/gotraining/clock"
+	"log"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var enabled int32 = 1
+
+// SetEnabled turns watching on or off for every channel. When it is off, operations aren't counted, timed or
+// reported.
+func SetEnabled(on bool) {
+
+	v := int32(0)
+
+	if on {
+		v = 1
+	}
+
+	atomic.StoreInt32(&enabled, v)
+}
+
+// Enabled reports whether channels are being watched
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+// Op is a channel operation
+type Op string
+
+const (
+	Send    Op = "send"
+	Receive Op = "receive"
+)
+
+// Blocked describes a send or receive that has been blocked for a while
+type Blocked struct {
+	Channel string
+	Op      Op
+
+	// Where the operation was called from
+	File     string
+	Line     int
+	Function string
+
+	// How long it had been blocked when it was reported
+	Waited time.Duration
+}
+
+func (b Blocked) String() string {
+	return fmt.Sprintf("%s on %s blocked for %v at %s:%d (%s)", b.Op, b.Channel, b.Waited, filepath.Base(b.File), b.Line, b.Function)
+}
+
+// Options configure a watched channel
+type Options struct {
+	// Used in reports (optional)
+	Name string
+
+	// Sends and receives blocked for longer than this are reported (never, if zero)
+	Threshold time.Duration
+
+	// Called with each report. If nil, reports are written with log.Printf. Must be safe to call concurrently.
+	OnBlocked func(Blocked)
+
+	// The clock used to time operations
+	Clock clock.Clock
+}
+
+// Stats are a snapshot of what has happened to a channel
+type Stats struct {
+	// The number of values in the channel's buffer and the buffer's size
+	Len int
+	Cap int
+
+	// The most values there have been waiting to be received when a send started: the values in the buffer, plus the
+	// one being sent. It is one more than Cap if a send has found the buffer full, so it is 1 for an unbuffered channel
+	// that has been sent on.
+	HighWater int
+
+	// Completed operations
+	Sends    int64
+	Receives int64
+
+	// The total time spent blocked by completed operations
+	SendBlocked    time.Duration
+	ReceiveBlocked time.Duration
+
+	// Operations blocked right now
+	Blocked int
+}
+
+// A Chan is a channel of T that records what happens to it. Use Send, Receive and Close rather than the channel
+// itself.
+type Chan[T any] struct {
+	ch   chan T
+	opts Options
+
+	sends, receives int64
+	highWater       int64
+
+	// In nanoseconds
+	sendBlocked, receiveBlocked int64
+
+	mu      sync.Mutex
+	waiting map[*waiter]bool
+
+	// Blocked operations that haven't been reported yet
+	unreported int
+
+	// Fires when the longest blocked operation that hasn't been reported passes the threshold (nil until an operation
+	// first blocks)
+	timer clock.Timer
+}
+
+// A send or receive that is blocked
+type waiter struct {
+	Blocked
+	start    time.Time
+	reported bool
+}
+
+// New creates a watched channel with a buffer of size values (0 for an unbuffered channel)
+func New[T any](size int, opts Options) *Chan[T] {
+
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("chan %T", *new(T))
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = clock.RealClock{}
+	}
+
+	if opts.OnBlocked == nil {
+		opts.OnBlocked = func(b Blocked) {
+			log.Printf("chanwatch: %s", b)
+		}
+	}
+
+	return &Chan[T]{ch: make(chan T, size), opts: opts, waiting: make(map[*waiter]bool)}
+}
+
+// C returns the underlying channel, for use in select statements. Operations on it aren't watched.
+func (c *Chan[T]) C() chan T {
+	return c.ch
+}
+
+// Len returns the number of values in the channel's buffer
+func (c *Chan[T]) Len() int {
+	return len(c.ch)
+}
+
+// Cap returns the size of the channel's buffer
+func (c *Chan[T]) Cap() int {
+	return cap(c.ch)
+}
+
+// Send sends v on the channel, like c <- v
+func (c *Chan[T]) Send(v T) {
+	c.send(context.Background(), v)
+}
+
+// SendContext sends v on the channel, giving up and returning ctx's error if ctx is cancelled first
+func (c *Chan[T]) SendContext(ctx context.Context, v T) error {
+	return c.send(ctx, v)
+}
+
+func (c *Chan[T]) send(ctx context.Context, v T) error {
+
+	if !Enabled() {
+
+		if ctx.Done() == nil {
+			c.ch <- v
+			return nil
+		}
+
+		select {
+		case c.ch <- v:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	c.offered()
+
+	// Most of the time there's no need to wait (or to spend time finding the caller)
+	select {
+	case c.ch <- v:
+		c.sent()
+		return nil
+	default:
+	}
+
+	w := c.block(Send)
+	defer c.unblock(w, &c.sendBlocked)
+
+	select {
+	case c.ch <- v:
+		c.sent()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// offered updates the high water mark as a send starts, before a receiver can take the value
+func (c *Chan[T]) offered() {
+
+	n := int64(len(c.ch)) + 1
+
+	for hw := atomic.LoadInt64(&c.highWater); n > hw; hw = atomic.LoadInt64(&c.highWater) {
+		if atomic.CompareAndSwapInt64(&c.highWater, hw, n) {
+			return
+		}
+	}
+}
+
+func (c *Chan[T]) sent() {
+	atomic.AddInt64(&c.sends, 1)
+}
+
+// Receive receives a value from the channel, like v, okay := <-c. okay is false if the channel is closed and empty.
+func (c *Chan[T]) Receive() (v T, okay bool) {
+
+	v, okay, _ = c.receive(context.Background())
+
+	return v, okay
+}
+
+// ReceiveContext receives a value from the channel, giving up and returning ctx's error if ctx is cancelled first
+func (c *Chan[T]) ReceiveContext(ctx context.Context) (v T, okay bool, err error) {
+
+	v, okay, gaveUp := c.receive(ctx)
+
+	if gaveUp {
+		return v, false, ctx.Err()
+	}
+
+	return v, okay, nil
+}
+
+func (c *Chan[T]) receive(ctx context.Context) (v T, okay bool, gaveUp bool) {
+
+	if !Enabled() {
+
+		if ctx.Done() == nil {
+			v, okay = <-c.ch
+			return v, okay, false
+		}
+
+		select {
+		case v, okay = <-c.ch:
+			return v, okay, false
+		case <-ctx.Done():
+			return v, false, true
+		}
+	}
+
+	select {
+	case v, okay = <-c.ch:
+		c.received(okay)
+		return v, okay, false
+	default:
+	}
+
+	w := c.block(Receive)
+	defer c.unblock(w, &c.receiveBlocked)
+
+	select {
+	case v, okay = <-c.ch:
+		c.received(okay)
+		return v, okay, false
+	case <-ctx.Done():
+		return v, false, true
+	}
+}
+
+func (c *Chan[T]) received(okay bool) {
+
+	if okay {
+		atomic.AddInt64(&c.receives, 1)
+	}
+}
+
+// Close closes the channel, like close(c)
+func (c *Chan[T]) Close() {
+	close(c.ch)
+}
+
+// block records that an operation is about to block, and starts the channel's timer if it is the only one waiting to
+// be reported. It must be called from send or receive, so it can find their caller.
+func (c *Chan[T]) block(op Op) *waiter {
+
+	w := &waiter{Blocked: Blocked{Channel: c.opts.Name, Op: op}, start: c.opts.Clock.Now()}
+
+	// 0 is runtime.Callers, 1 is block, 2 is send or receive, 3 is the exported method and 4 is what called it
+	pc := make([]uintptr, 1)
+
+	if runtime.Callers(4, pc) == 1 {
+		frame, _ := runtime.CallersFrames(pc).Next()
+		w.File, w.Line, w.Function = frame.File, frame.Line, frame.Function
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.waiting[w] = true
+
+	if c.opts.Threshold > 0 {
+
+		c.unreported++
+
+		// Otherwise the timer is already set for an operation that blocked earlier
+		if c.unreported == 1 {
+			c.wake(c.opts.Threshold)
+		}
+	}
+
+	return w
+}
+
+// wake makes the channel's timer fire once d has passed. Must be called with the lock held.
+func (c *Chan[T]) wake(d time.Duration) {
+
+	if c.timer == nil {
+		c.timer = c.opts.Clock.AfterFunc(d, c.report)
+	} else {
+		c.timer.Reset(d)
+	}
+}
+
+// report reports the operations that have been blocked for longer than the threshold, and sets the timer for the next
+// one to pass it
+func (c *Chan[T]) report() {
+
+	now := c.opts.Clock.Now()
+
+	var overdue []Blocked
+	var next time.Time
+
+	c.mu.Lock()
+
+	for w := range c.waiting {
+
+		if w.reported {
+			continue
+		}
+
+		if waited := now.Sub(w.start); waited >= c.opts.Threshold {
+			w.reported = true
+			c.unreported--
+
+			b := w.Blocked
+			b.Waited = waited
+			overdue = append(overdue, b)
+
+		} else if next.IsZero() || w.start.Before(next) {
+			next = w.start
+		}
+	}
+
+	if !next.IsZero() {
+		c.wake(next.Add(c.opts.Threshold).Sub(now))
+	}
+
+	c.mu.Unlock()
+
+	sort.Slice(overdue, func(i, j int) bool {
+		return overdue[i].Waited > overdue[j].Waited
+	})
+
+	for _, b := range overdue {
+		c.opts.OnBlocked(b)
+	}
+}
+
+// unblock records that an operation has finished blocking, and adds the time it spent blocked to total
+func (c *Chan[T]) unblock(w *waiter, total *int64) {
+
+	atomic.AddInt64(total, int64(c.opts.Clock.Now().Sub(w.start)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.waiting, w)
+
+	if c.opts.Threshold > 0 && !w.reported {
+
+		c.unreported--
+
+		// If other operations are waiting to be reported, the timer wakes for the next one when it fires
+		if c.unreported == 0 {
+			c.timer.Stop()
+		}
+	}
+}
+
+// Blocked returns the operations blocked right now, longest blocked first
+func (c *Chan[T]) Blocked() []Blocked {
+
+	now := c.opts.Clock.Now()
+
+	c.mu.Lock()
+
+	blocked := make([]Blocked, 0, len(c.waiting))
+
+	for w := range c.waiting {
+		b := w.Blocked
+		b.Waited = now.Sub(w.start)
+		blocked = append(blocked, b)
+	}
+
+	c.mu.Unlock()
+
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].Waited > blocked[j].Waited
+	})
+
+	return blocked
+}
+
+// Stats returns a snapshot of what has happened to the channel. Operations are only counted while watching is enabled.
+func (c *Chan[T]) Stats() Stats {
+
+	c.mu.Lock()
+	blocked := len(c.waiting)
+	c.mu.Unlock()
+
+	return Stats{
+		Len:            len(c.ch),
+		Cap:            cap(c.ch),
+		HighWater:      int(atomic.LoadInt64(&c.highWater)),
+		Sends:          atomic.LoadInt64(&c.sends),
+		Receives:       atomic.LoadInt64(&c.receives),
+		SendBlocked:    time.Duration(atomic.LoadInt64(&c.sendBlocked)),
+		ReceiveBlocked: time.Duration(atomic.LoadInt64(&c.receiveBlocked)),
+		Blocked:        blocked,
+	}
+}
@@ -0,0 +1,255 @@
+package chanwatch
+
+import (
+	"context"
+	"github.com/benhalstead/gotraining/clock"
+	"github.com/benhalstead/gotraining/leak"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCounts(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := New[int](3, Options{})
+
+	for i := 0; i < 3; i++ {
+		c.Send(i)
+	}
+
+	if v, okay := c.Receive(); v != 0 || !okay {
+		t.Fatalf("Expected the first value sent, got %d %v", v, okay)
+	}
+
+	c.Send(3)
+	c.Close()
+
+	for range c.C() {
+	}
+
+	if _, okay := c.Receive(); okay {
+		t.Errorf("Expected the channel to be closed")
+	}
+
+	// Receives straight from the channel aren't counted
+	s := c.Stats()
+
+	if s.Sends != 4 || s.Receives != 1 || s.HighWater != 3 || s.Cap != 3 || s.Len != 0 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	leak.VerifyNone(t)
+
+	clk := clock.NewVirtualClock(clock.Epoch, 0)
+	reports := make(chan Blocked, 1)
+
+	c := New[string](0, Options{Name: "names", Threshold: time.Second, Clock: clk, OnBlocked: func(b Blocked) {
+		reports <- b
+	}})
+
+	sent := make(chan bool)
+
+	go func() {
+		c.Send("gopher")
+		sent <- true
+	}()
+
+	clk.WaitUntilSleeping(1)
+
+	clk.Advance(500 * time.Millisecond)
+
+	if b := c.Blocked(); len(b) != 1 || b[0].Op != Send || b[0].Waited != 500*time.Millisecond {
+		t.Fatalf("Expected one send blocked for 500ms, got %v", b)
+	}
+
+	clk.Advance(time.Second)
+
+	r := <-reports
+
+	if r.Channel != "names" || r.Op != Send || r.Waited != 1500*time.Millisecond {
+		t.Errorf("Unexpected report %v", r)
+	}
+
+	// The report names the function that called Send
+	if !strings.HasSuffix(r.File, "chanwatch_test.go") || !strings.Contains(r.Function, "TestBlocked") || r.Line == 0 {
+		t.Errorf("Expected the report to name the call site, got %v", r)
+	}
+
+	if v, _ := c.Receive(); v != "gopher" {
+		t.Errorf("Unexpected value %q", v)
+	}
+
+	<-sent
+
+	// The value sent was waiting to be received, although an unbuffered channel never holds it
+	s := c.Stats()
+
+	if s.SendBlocked != 1500*time.Millisecond || s.Blocked != 0 || s.Sends != 1 || s.Receives != 1 || s.HighWater != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
+
+func TestNotReportedUnderThreshold(t *testing.T) {
+	leak.VerifyNone(t)
+
+	clk := clock.NewVirtualClock(clock.Epoch, 0)
+
+	c := New[int](0, Options{Threshold: time.Second, Clock: clk, OnBlocked: func(b Blocked) {
+		t.Errorf("Unexpected report %v", b)
+	}})
+
+	received := make(chan int)
+
+	go func() {
+		v, _ := c.Receive()
+		received <- v
+	}()
+
+	clk.WaitUntilSleeping(1)
+
+	clk.Advance(100 * time.Millisecond)
+	c.Send(7)
+
+	if v := <-received; v != 7 {
+		t.Errorf("Unexpected value %d", v)
+	}
+
+	if s := c.Stats(); s.ReceiveBlocked != 100*time.Millisecond {
+		t.Errorf("Expected the receive to be blocked for 100ms, got %v", s.ReceiveBlocked)
+	}
+
+	// The timer was stopped when the receive finished, so the receive is never reported
+	if n := clk.Sleeping(); n != 0 {
+		t.Errorf("Expected the timer to be stopped, got %d sleeping", n)
+	}
+
+	clk.Advance(time.Second)
+}
+
+func TestReportsShareATimer(t *testing.T) {
+	leak.VerifyNone(t)
+
+	clk := clock.NewVirtualClock(clock.Epoch, 0)
+	reports := make(chan Blocked, 2)
+
+	c := New[int](0, Options{Threshold: time.Second, Clock: clk, OnBlocked: func(b Blocked) {
+		reports <- b
+	}})
+
+	received := make(chan bool)
+
+	receive := func() {
+		c.Receive()
+		received <- true
+	}
+
+	go receive()
+	clk.WaitUntilSleeping(1)
+	clk.Advance(500 * time.Millisecond)
+
+	go receive()
+
+	for c.Stats().Blocked != 2 {
+		runtime.Gosched()
+	}
+
+	if n := clk.Sleeping(); n != 1 {
+		t.Fatalf("Expected both receives to share a timer, got %d sleeping", n)
+	}
+
+	// Each receive is reported once it has been blocked for the threshold
+	clk.Advance(500 * time.Millisecond)
+
+	if r := <-reports; r.Waited != time.Second {
+		t.Errorf("Expected the first receive to be reported after 1s, got %v", r)
+	}
+
+	clk.Advance(500 * time.Millisecond)
+
+	if r := <-reports; r.Waited != time.Second {
+		t.Errorf("Expected the second receive to be reported after 1s, got %v", r)
+	}
+
+	c.Send(1)
+	c.Send(2)
+
+	<-received
+	<-received
+}
+
+func TestContext(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := New[int](0, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := c.SendContext(ctx, 1); err != context.Canceled {
+		t.Errorf("Expected the send to give up, got %v", err)
+	}
+
+	if _, okay, err := c.ReceiveContext(ctx); okay || err != context.Canceled {
+		t.Errorf("Expected the receive to give up, got %v %v", okay, err)
+	}
+
+	if s := c.Stats(); s.Sends != 0 || s.Receives != 0 || s.Blocked != 0 {
+		t.Errorf("Expected nothing to be counted, got %+v", s)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	leak.VerifyNone(t)
+
+	SetEnabled(false)
+	defer SetEnabled(true)
+
+	c := New[int](1, Options{})
+
+	c.Send(1)
+
+	if v, okay := c.Receive(); v != 1 || !okay {
+		t.Errorf("Expected the value sent, got %d %v", v, okay)
+	}
+
+	if s := c.Stats(); s.Sends != 0 || s.Receives != 0 || s.HighWater != 0 {
+		t.Errorf("Expected nothing to be counted, got %+v", s)
+	}
+}
+
+func BenchmarkChannel(b *testing.B) {
+
+	ch := make(chan int, 1)
+
+	for i := 0; i < b.N; i++ {
+		ch <- i
+		<-ch
+	}
+}
+
+func BenchmarkWatched(b *testing.B) {
+
+	c := New[int](1, Options{})
+
+	for i := 0; i < b.N; i++ {
+		c.Send(i)
+		c.Receive()
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+
+	SetEnabled(false)
+	defer SetEnabled(true)
+
+	c := New[int](1, Options{})
+
+	for i := 0; i < b.N; i++ {
+		c.Send(i)
+		c.Receive()
+	}
+}
@@ -0,0 +1,92 @@
+// Package clock tells the time and waits for it to pass, either for real or with a virtual clock that only moves
+// forward when it is told to.
+//
+// Code that waits (like a rate limiter or a job scheduler) takes a Clock rather than calling the time package, so its
+// tests can use a VirtualClock and run in an instant, and the lessons can be replayed without waiting (see
+// tutorial.ClockEnvVar).
+//
+//	c := clock.NewVirtualClock(clock.Epoch, 0)
+//	l := limit.NewLimiter(10, 1, c)
+//
+//	go l.Wait(ctx)
+//
+//	c.WaitUntilSleeping(1)
+//	c.Advance(100 * time.Millisecond) // the Wait returns
+package clock
+
+import (
+	"time"
+)
+
+// A Clock tells the time and lets a goroutine sleep. Packages that take a Clock use the RealClock if they are given
+// nil.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+
+	// After returns a channel that receives the time once d has passed, like time.After. Use NewTimer instead if the
+	// wait might be given up before then.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTimer returns a Timer that fires once d has passed, like time.NewTimer
+	NewTimer(d time.Duration) Timer
+
+	// AfterFunc returns a Timer that calls f in its own goroutine once d has passed, like time.AfterFunc. The Timer's
+	// channel is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// A Timer sends the time on its channel once it fires, like a time.Timer. Stopping or resetting a Timer throws away any
+// time it sent that hasn't been received, so receiving from its channel afterwards never returns a stale time.
+type Timer interface {
+	C() <-chan time.Time
+
+	// Stop stops the Timer firing, and reports whether it did (false if it had already fired or been stopped)
+	Stop() bool
+
+	// Reset makes the Timer fire once d has passed from now, and reports whether it was waiting to fire
+	Reset(d time.Duration) bool
+}
+
+// RealClock is a Clock that uses the time package
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (RealClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r realTimer) Stop() bool {
+	return r.t.Stop()
+}
+
+func (r realTimer) Reset(d time.Duration) bool {
+	return r.t.Reset(d)
+}
+
+// Epoch is the time replayed lessons start at (9am on Wednesday the 1st of January 2020, UTC). Tests can start their
+// VirtualClocks at it too, so the times they see are the same on every run.
+var Epoch = time.Date(2020, time.January, 1, 9, 0, 0, 0, time.UTC)
@@ -0,0 +1,218 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdvanceWakesSleepersInOrder(t *testing.T) {
+
+	c := NewVirtualClock(Epoch, 0)
+
+	woken := make(chan string, 2)
+
+	sleep := func(name string, d time.Duration) {
+		c.Sleep(d)
+		woken <- name
+	}
+
+	go sleep("late", 3*time.Second)
+	go sleep("early", time.Second)
+
+	c.WaitUntilSleeping(2)
+
+	c.Advance(2 * time.Second)
+
+	if w := <-woken; w != "early" {
+		t.Errorf("Expected early to be woken first, got %s", w)
+	}
+
+	if got := c.Now(); !got.Equal(Epoch.Add(2 * time.Second)) {
+		t.Errorf("Expected the clock to read %v, got %v", Epoch.Add(2*time.Second), got)
+	}
+
+	if c.Sleeping() != 1 {
+		t.Errorf("Expected late to still be sleeping")
+	}
+
+	c.Advance(time.Second)
+
+	if w := <-woken; w != "late" {
+		t.Errorf("Expected late to be woken second, got %s", w)
+	}
+}
+
+func TestReplayKeepsEventsInOrder(t *testing.T) {
+
+	c := NewVirtualClock(Epoch, time.Millisecond)
+
+	events := make(chan string, 20)
+	done := make(chan bool)
+
+	tick := func(name string, every time.Duration, count int) {
+		for i := 0; i < count; i++ {
+			c.Sleep(every)
+			events <- name + "@" + c.Now().Sub(Epoch).String()
+		}
+
+		done <- true
+	}
+
+	go tick("fast", 200*time.Millisecond, 4)
+	go tick("slow", 300*time.Millisecond, 2)
+
+	began := time.Now()
+
+	<-done
+	<-done
+	close(events)
+
+	var got []string
+
+	for e := range events {
+		got = append(got, e)
+	}
+
+	// fast and slow are both due at 600ms, but slow went to sleep first
+	want := []string{"fast@200ms", "slow@300ms", "fast@400ms", "slow@600ms", "fast@600ms", "fast@800ms"}
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+
+	if elapsed := time.Since(began); elapsed > 500*time.Millisecond {
+		t.Errorf("Replaying 800ms of virtual time took %v", elapsed)
+	}
+}
+
+func TestReplayWaitsForBusyGoroutines(t *testing.T) {
+
+	c := NewVirtualClock(Epoch, time.Millisecond)
+
+	events := make(chan string, 2)
+	done := make(chan bool)
+
+	go func() {
+		c.Sleep(time.Second)
+		events <- "idle"
+		done <- true
+	}()
+
+	go func() {
+
+		// Works for much longer than the settle time before going to sleep for less time than idle
+		for until := time.Now().Add(50 * time.Millisecond); time.Now().Before(until); {
+		}
+
+		c.Sleep(500 * time.Millisecond)
+		events <- "busy"
+		done <- true
+	}()
+
+	<-done
+	<-done
+
+	if first := <-events; first != "busy" {
+		t.Errorf("Expected busy to be woken first, got %s", first)
+	}
+}
+
+func TestAfter(t *testing.T) {
+
+	c := NewVirtualClock(Epoch, 0)
+
+	// A duration that has already passed is signalled straight away, without waiting for the clock
+	select {
+	case at := <-c.After(0):
+		if !at.Equal(Epoch) {
+			t.Errorf("Expected %v, got %v", Epoch, at)
+		}
+	default:
+		t.Fatalf("Expected After(0) to be signalled straight away")
+	}
+
+	ch := c.After(time.Second)
+
+	if c.Sleeping() != 1 {
+		t.Fatalf("Expected After to wait for the clock")
+	}
+
+	c.Advance(time.Minute)
+
+	// The channel receives the time it was due, not the time the clock was advanced to
+	if at := <-ch; !at.Equal(Epoch.Add(time.Second)) {
+		t.Errorf("Expected %v, got %v", Epoch.Add(time.Second), at)
+	}
+}
+
+func TestTimer(t *testing.T) {
+
+	c := NewVirtualClock(Epoch, 0)
+
+	stopped := c.NewTimer(time.Second)
+	reset := c.NewTimer(time.Second)
+
+	// A stopped timer no longer counts as sleeping, and never fires
+	if !stopped.Stop() || stopped.Stop() || c.Sleeping() != 1 {
+		t.Fatalf("Expected Stop to stop the timer once, with 1 still sleeping, got %d", c.Sleeping())
+	}
+
+	c.Advance(time.Second)
+
+	select {
+	case <-stopped.C():
+		t.Fatalf("Expected the stopped timer not to fire")
+	default:
+	}
+
+	// The time sent when reset fired is thrown away when it is reset, so it isn't received late
+	if reset.Reset(time.Second) {
+		t.Errorf("Expected Reset to report the timer had already fired")
+	}
+
+	c.Advance(500 * time.Millisecond)
+
+	select {
+	case at := <-reset.C():
+		t.Fatalf("Expected the reset timer to wait, got %v", at)
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+
+	if at := <-reset.C(); !at.Equal(Epoch.Add(2 * time.Second)) {
+		t.Errorf("Expected %v, got %v", Epoch.Add(2*time.Second), at)
+	}
+}
+
+func TestAfterFunc(t *testing.T) {
+
+	c := NewVirtualClock(Epoch, 0)
+
+	called := make(chan time.Time, 1)
+	timer := c.AfterFunc(time.Second, func() { called <- c.Now() })
+
+	if timer.C() != nil || c.Sleeping() != 1 {
+		t.Fatalf("Expected a sleeping timer without a channel")
+	}
+
+	c.Advance(time.Second)
+
+	if at := <-called; !at.Equal(Epoch.Add(time.Second)) {
+		t.Errorf("Expected f to be called at %v, got %v", Epoch.Add(time.Second), at)
+	}
+
+	// Once reset, it is called again
+	timer.Reset(time.Second)
+	c.Advance(time.Second)
+
+	if at := <-called; !at.Equal(Epoch.Add(2 * time.Second)) {
+		t.Errorf("Expected f to be called at %v, got %v", Epoch.Add(2*time.Second), at)
+	}
+}
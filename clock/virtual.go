@@ -0,0 +1,353 @@
+package clock
+
+import (
+	"bytes"
+	"container/heap"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A VirtualClock is a Clock whose time only moves forward when it is advanced, either explicitly with Advance or, if
+// it was created with a non-zero settle time, automatically.
+//
+// Sleeping goroutines are woken in order of the time they are due to wake, and goroutines due to wake at the same
+// time are woken in the order they went to sleep. When advancing automatically, the clock waits until no goroutine has
+// used it for the settle time (real time, not virtual) and every other goroutine in the program is blocked, whether
+// on the clock, a channel, a lock or I/O. Only then does it wake the next sleeping goroutine and move its time forward
+// to the time that goroutine was due to wake. A goroutine that is still running (or waiting for a CPU to run on) when
+// the settle time is up holds the clock back, however long it takes, so the order of events is the same as it would
+// be with a RealClock, but nobody has to wait.
+type VirtualClock struct {
+	mu       sync.Mutex
+	now      time.Time
+	settle   time.Duration
+	sleepers sleepers
+	seq      uint64
+	timer    *time.Timer
+
+	// Broadcast whenever a goroutine goes to sleep or is woken
+	changed *sync.Cond
+}
+
+// NewVirtualClock creates a VirtualClock set to start. If settle is zero, time only moves forward when Advance is
+// called.
+func NewVirtualClock(start time.Time, settle time.Duration) *VirtualClock {
+	c := &VirtualClock{now: start, settle: settle}
+	c.changed = sync.NewCond(&c.mu)
+
+	return c
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.touch()
+
+	return c.now
+}
+
+// Sleep blocks until the clock has been advanced by at least d
+func (c *VirtualClock) Sleep(d time.Duration) {
+
+	if d > 0 {
+		<-c.After(d)
+	}
+}
+
+// After returns a channel that receives the time once the clock has been advanced by at least d. Until then it counts
+// as a sleeping goroutine, even if nothing is waiting for the channel.
+func (c *VirtualClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer returns a Timer whose channel receives the time once the clock has been advanced by at least d. Until then
+// (or until it is stopped) it counts as a sleeping goroutine.
+func (c *VirtualClock) NewTimer(d time.Duration) Timer {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Buffered, so the clock never waits for the time to be received
+	t := &virtualTimer{c: c, s: &sleeper{wake: make(chan time.Time, 1), index: -1}}
+	c.schedule(t.s, d)
+
+	return t
+}
+
+// AfterFunc returns a Timer that calls f in its own goroutine once the clock has been advanced by at least d. Until
+// then (or until it is stopped) it counts as a sleeping goroutine.
+func (c *VirtualClock) AfterFunc(d time.Duration, f func()) Timer {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &virtualTimer{c: c, s: &sleeper{fn: f, index: -1}}
+	c.schedule(t.s, d)
+
+	return t
+}
+
+// Advance moves the clock forward by d, waking (in order) every goroutine due to wake before then
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	until := c.now.Add(d)
+
+	for len(c.sleepers) > 0 && !c.sleepers[0].at.After(until) {
+		c.wake()
+	}
+
+	c.now = until
+}
+
+// Sleeping returns the number of goroutines (and timers) waiting for the clock to be advanced
+func (c *VirtualClock) Sleeping() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.sleepers)
+}
+
+// WaitUntilSleeping blocks until exactly n goroutines (and timers) are waiting for the clock to be advanced. Tests call
+// it before Advance, so they know the goroutines they started have gone to sleep.
+func (c *VirtualClock) WaitUntilSleeping(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.sleepers) != n {
+		c.changed.Wait()
+	}
+}
+
+// touch restarts the wait before the clock next advances automatically. Must be called with the lock held.
+func (c *VirtualClock) touch() {
+
+	if c.settle == 0 || len(c.sleepers) == 0 {
+		return
+	}
+
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.settle, c.advanceNext)
+	} else {
+		c.timer.Reset(c.settle)
+	}
+}
+
+// advanceNext wakes the next goroutine due to wake, unless another goroutine is still running
+func (c *VirtualClock) advanceNext() {
+
+	// Checked before taking the lock, so goroutines about to use the clock aren't seen as blocked on it
+	busy := !quiescent()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.sleepers) == 0 {
+		return
+	}
+
+	if !busy {
+		c.wake()
+	}
+
+	c.touch()
+}
+
+// quiescent reports whether every goroutine, apart from the one calling it, is blocked. Goroutines that are running,
+// runnable or in a system call are busy, except os/signal's, which waits for signals in a system call.
+func quiescent() bool {
+
+	buf := make([]byte, 64*1024)
+
+	for {
+		n := runtime.Stack(buf, true)
+
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	// Each goroutine's stack starts with a line like "goroutine 7 [chan receive]:". The first is the calling goroutine.
+	for i, g := range bytes.Split(buf, []byte("\n\n")) {
+
+		if i == 0 {
+			continue
+		}
+
+		header := g
+
+		if end := bytes.IndexByte(g, '\n'); end >= 0 {
+			header = g[:end]
+		}
+
+		open, shut := bytes.IndexByte(header, '['), bytes.IndexByte(header, ']')
+
+		if open < 0 || shut < open {
+			continue
+		}
+
+		// The state can be followed by how long the goroutine has been in it, e.g. [chan receive, 2 minutes]
+		state := string(header[open+1 : shut])
+
+		if comma := strings.IndexByte(state, ','); comma >= 0 {
+			state = state[:comma]
+		}
+
+		switch state {
+		case "running", "runnable":
+			return false
+		case "syscall":
+			if !bytes.Contains(g, []byte("os/signal.signal_recv")) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// schedule puts s to sleep until the clock has been advanced by d, or wakes it straight away if d has already passed.
+// Must be called with the lock held.
+func (c *VirtualClock) schedule(s *sleeper, d time.Duration) {
+
+	if d <= 0 {
+		s.fire(c.now)
+		return
+	}
+
+	s.at, s.seq = c.now.Add(d), c.seq
+	c.seq++
+
+	heap.Push(&c.sleepers, s)
+	c.changed.Broadcast()
+
+	c.touch()
+}
+
+// unschedule stops s from being woken, and reports whether it was still asleep. A time that was sent to s but hasn't
+// been received is thrown away. Must be called with the lock held.
+func (c *VirtualClock) unschedule(s *sleeper) bool {
+
+	asleep := s.index >= 0
+
+	if asleep {
+		heap.Remove(&c.sleepers, s.index)
+		c.changed.Broadcast()
+	}
+
+	select {
+	case <-s.wake:
+	default:
+	}
+
+	return asleep
+}
+
+// wake moves the clock forward to the time the next sleeper is due and wakes it. Must be called with the lock held.
+func (c *VirtualClock) wake() {
+
+	s := heap.Pop(&c.sleepers).(*sleeper)
+	c.changed.Broadcast()
+
+	if s.at.After(c.now) {
+		c.now = s.at
+	}
+
+	s.fire(c.now)
+}
+
+type sleeper struct {
+	at  time.Time
+	seq uint64
+
+	// Receives the time the sleeper woke, or (for AfterFunc) nil and fn is called instead
+	wake chan time.Time
+	fn   func()
+
+	// The sleeper's position in the heap, or -1 once it has been woken or stopped
+	index int
+}
+
+// fire sends now to the sleeper's channel, or calls its function
+func (s *sleeper) fire(now time.Time) {
+
+	if s.fn != nil {
+		go s.fn()
+		return
+	}
+
+	s.wake <- now
+}
+
+type virtualTimer struct {
+	c *VirtualClock
+	s *sleeper
+}
+
+func (t *virtualTimer) C() <-chan time.Time {
+	return t.s.wake
+}
+
+func (t *virtualTimer) Stop() bool {
+
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+
+	return t.c.unschedule(t.s)
+}
+
+func (t *virtualTimer) Reset(d time.Duration) bool {
+
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+
+	asleep := t.c.unschedule(t.s)
+	t.c.schedule(t.s, d)
+
+	return asleep
+}
+
+// sleepers is a heap ordered by the time each sleeper is due, then the order they went to sleep
+type sleepers []*sleeper
+
+func (s sleepers) Len() int {
+	return len(s)
+}
+
+func (s sleepers) Less(i, j int) bool {
+
+	if s[i].at.Equal(s[j].at) {
+		return s[i].seq < s[j].seq
+	}
+
+	return s[i].at.Before(s[j].at)
+}
+
+func (s sleepers) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+	s[i].index, s[j].index = i, j
+}
+
+func (s *sleepers) Push(x interface{}) {
+
+	x.(*sleeper).index = len(*s)
+	*s = append(*s, x.(*sleeper))
+}
+
+func (s *sleepers) Pop() interface{} {
+
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	x.index = -1
+	*s = old[:n-1]
+
+	return x
+}
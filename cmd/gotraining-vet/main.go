@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/benhalstead/gotraining/lint"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+// gotraining-vet runs the analyzers in the lint package as a go vet tool:
+//
+//	go install ./cmd/gotraining-vet
+//	go vet -vettool=$(which gotraining-vet) ./...
+//
+// Run gotraining-vet help to see the analyzers and their flags.
+func main() {
+	unitchecker.Main(lint.Analyzers...)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/benhalstead/gotraining/book"
+	"net/http"
+)
+
+// book writes the lessons out as a book or, with -serve, serves the book over HTTP
+func (r *runner) book(args []string) error {
+
+	fs := flag.NewFlagSet("book", flag.ExitOnError)
+	out := fs.String("out", "_book", "the directory to write the book to")
+	format := fs.String("format", "html", "the format of the book: html or markdown")
+	serve := fs.String("serve", "", "serve the book as HTML from this address (e.g. localhost:8080) instead of writing it")
+
+	fs.Parse(args)
+
+	if *serve != "" {
+		fmt.Printf("Serving the book at http://%s/ (press Ctrl+C to stop)\n", *serve)
+		return http.ListenAndServe(*serve, book.Handler(r.root))
+	}
+
+	b, err := book.Load(r.root)
+
+	if err != nil {
+		return err
+	}
+
+	var pages map[string][]byte
+
+	switch *format {
+	case "html":
+		if pages, err = b.HTML(); err != nil {
+			return err
+		}
+	case "markdown":
+		pages = b.Markdown()
+	default:
+		return fmt.Errorf("unknown format %s (expected html or markdown)", *format)
+	}
+
+	if err := book.Write(*out, pages); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d pages to %s\n", len(pages), *out)
+
+	return nil
+}
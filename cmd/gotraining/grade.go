@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/benhalstead/gotraining/grader"
+	"strings"
+	"time"
+)
+
+// grade runs the reference tests for the exercises in each of the named topics (or every topic), prints a report and
+// records the results in the learner's progress
+func (r *runner) grade(args []string) error {
+
+	fs := flag.NewFlagSet("grade", flag.ExitOnError)
+	workspace := fs.String("workspace", "", "the directory containing your answers, laid out like this repository (default: the repository itself)")
+
+	fs.Parse(args)
+
+	if *workspace == "" {
+		*workspace = r.root
+	}
+
+	topics := fs.Args()
+
+	if len(topics) == 0 {
+
+		var err error
+
+		if topics, err = grader.Topics(r.root); err != nil {
+			return err
+		}
+	}
+
+	s, err := r.progress()
+
+	if err != nil {
+		return err
+	}
+
+	passed, total := 0, 0
+
+	for _, t := range topics {
+
+		results, err := grader.Grade(r.root, *workspace, t)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(t)
+
+		for _, res := range results {
+
+			total++
+
+			if err := s.RecordExercise(r.learner, t, res.Name, res.Passed, time.Now()); err != nil {
+				return err
+			}
+
+			if res.Passed {
+				passed++
+				fmt.Printf("  PASS  %s\n", res.Name)
+				continue
+			}
+
+			fmt.Printf("  FAIL  %s\n", res.Name)
+			printIndented(res.Output)
+
+			if res.Hint != "" {
+				printIndented("Hint: " + res.Hint)
+			}
+		}
+	}
+
+	fmt.Printf("\n%d of %d exercises passed\n", passed, total)
+
+	return nil
+}
+
+func printIndented(s string) {
+
+	for _, l := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		if strings.TrimSpace(l) != "" {
+			fmt.Printf("        %s\n", strings.TrimSpace(l))
+		}
+	}
+}
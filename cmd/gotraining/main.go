@@ -0,0 +1,345 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/benhalstead/gotraining/progress"
+	"github.com/benhalstead/gotraining/tutorial"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gotraining lists and runs the lessons in this repository.
+//
+//	gotraining list                              lists every lesson in the README's reading order
+//	gotraining list concurrency/channels         lists the sections in a lesson
+//	gotraining run concurrency/channels          runs every section in a lesson
+//	gotraining run concurrency/channels#select   runs a single section
+//	gotraining grade functions                   checks your answers to a topic's exercises
+//	gotraining book                              writes the lessons out as an HTML book in ./_book
+//	gotraining book -serve localhost:8080        serves the book from a local web server
+//	gotraining playground                        serves a page for editing and running lessons at localhost:8081
+//	gotraining quiz structures/maps              asks the questions in a lesson's quiz
+//	gotraining next                              shows the next lesson you haven't completed
+//	gotraining status                            shows the lessons and exercises you have completed
+//	gotraining reset                             forgets everything you have completed
+//
+// Running a whole lesson marks it as completed, grading records the result of each exercise and quizzes record your
+// score. Progress is recorded for the learner named by -learner (default: $GOTRAINING_LEARNER or your user name) in the
+// file named by -progress.
+//
+// With -format ndjson, lessons print a stream of JSON events (one per line) instead of text. See tutorial.Output for
+// the fields in each event.
+//
+// With -clock replay, lessons that sleep or measure time use a virtual clock (see tutorial.ClockEnvVar), so they run
+// without waiting and print the same durations every time.
+//
+// Lessons are run with 'go run', so the go tool must be on your PATH. The runner looks for the root of this repository
+// in the current directory and its parents, unless -root is set.
+func main() {
+
+	root := flag.String("root", "", "the directory containing this repository (default: search from the current directory)")
+	format := flag.String("format", "text", "how lessons print their output: text or ndjson")
+	clock := flag.String("clock", "real", "the clock lessons use: real or replay")
+	learner := flag.String("learner", defaultLearner(), "the name of the learner whose progress is recorded")
+	progressPath := flag.String("progress", "", "the file progress is recorded in (default: progress.json in your config directory)")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 || (*format != "text" && *format != "ndjson") || (*clock != "real" && *clock != "replay") {
+		usage()
+		os.Exit(2)
+	}
+
+	r, err := newRunner(*root, *format, *clock, *learner, *progressPath)
+
+	if err != nil {
+		exitWithError(err)
+	}
+
+	args := flag.Args()[1:]
+
+	switch flag.Arg(0) {
+	case "list":
+		err = r.list(args)
+	case "run":
+		err = r.run(args)
+	case "grade":
+		err = r.grade(args)
+	case "book":
+		err = r.book(args)
+	case "playground":
+		err = r.playground(args)
+	case "quiz":
+		err = r.quiz(args)
+	case "next":
+		err = r.next(args)
+	case "status":
+		err = r.status()
+	case "reset":
+		err = r.reset()
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: gotraining [-root dir] list [lesson]\n")
+	fmt.Fprintf(os.Stderr, "       gotraining [-root dir] [-format text|ndjson] [-clock real|replay] run lesson[#section] ...\n")
+	fmt.Fprintf(os.Stderr, "       gotraining [-root dir] grade [-workspace dir] [topic ...]\n")
+	fmt.Fprintf(os.Stderr, "       gotraining [-root dir] book [-out dir] [-format html|markdown] [-serve addr]\n")
+	fmt.Fprintf(os.Stderr, "       gotraining [-root dir] playground [-addr addr] [-timeout d] [-cpu d] [-output bytes]\n")
+	fmt.Fprintf(os.Stderr, "       gotraining [-learner name] [-progress file] quiz [-serve addr] [lesson ...]\n")
+	fmt.Fprintf(os.Stderr, "       gotraining [-learner name] [-progress file] next [-run] | status | reset\n")
+	flag.PrintDefaults()
+}
+
+func exitWithError(err error) {
+
+	// The go tool has already reported why a lesson failed
+	if ee, okay := err.(*exec.ExitError); okay {
+		os.Exit(ee.ExitCode())
+	}
+
+	fmt.Fprintln(os.Stderr, err.Error())
+	os.Exit(1)
+}
+
+type runner struct {
+	root   string
+	format string
+	clock  string
+
+	learner      string
+	progressPath string
+	store        *progress.Store
+}
+
+func newRunner(root, format, clock, learner, progressPath string) (*runner, error) {
+
+	var err error
+
+	if root == "" {
+		if root, err = findRoot(); err != nil {
+			return nil, err
+		}
+	}
+
+	if progressPath == "" {
+		if progressPath, err = progress.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &runner{
+		root:         root,
+		format:       format,
+		clock:        clock,
+		learner:      learner,
+		progressPath: progressPath,
+	}
+
+	return r, nil
+}
+
+// progress opens the progress file the first time it is needed
+func (r *runner) progress() (*progress.Store, error) {
+
+	if r.store == nil {
+
+		s, err := progress.Open(r.progressPath)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read progress from %s: %s", r.progressPath, err.Error())
+		}
+
+		r.store = s
+	}
+
+	return r.store, nil
+}
+
+func defaultLearner() string {
+
+	if l := os.Getenv("GOTRAINING_LEARNER"); l != "" {
+		return l
+	}
+
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return "learner"
+}
+
+// findRoot walks up from the current directory looking for the tutorial package
+func findRoot() (string, error) {
+
+	dir, err := os.Getwd()
+
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "tutorial", "support.go")); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+
+		if parent == dir {
+			return "", fmt.Errorf("could not find the gotraining repository in the current directory or its parents (use -root)")
+		}
+
+		dir = parent
+	}
+}
+
+func (r *runner) list(args []string) error {
+
+	if len(args) == 0 {
+
+		n := 1
+
+		for _, c := range tutorial.ReadingOrder {
+			fmt.Println(c.Title)
+
+			for _, id := range c.Lessons {
+				fmt.Printf("  %2d. %s\n", n, id)
+				n++
+			}
+		}
+
+		return nil
+	}
+
+	for _, id := range args {
+		if err := r.exec(id, "-list"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *runner) run(args []string) error {
+
+	if len(args) == 0 {
+		return fmt.Errorf("run needs at least one lesson, e.g. gotraining run concurrency/channels#select")
+	}
+
+	for _, a := range args {
+
+		id, section := splitLesson(a)
+
+		if section != "" {
+
+			if err := r.exec(id, section); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := r.exec(id); err != nil {
+			return err
+		}
+
+		// Only running a whole lesson counts as completing it
+		s, err := r.progress()
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.CompleteLesson(r.learner, id, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitLesson splits an argument like concurrency/channels#select into a lesson ID and a section name
+func splitLesson(arg string) (id, section string) {
+
+	if i := strings.Index(arg, "#"); i >= 0 {
+		return arg[:i], arg[i+1:]
+	}
+
+	return arg, ""
+}
+
+// exec runs a lesson's source file with 'go run', passing the supplied arguments to the lesson. Lessons that are
+// tests rather than programs (like unittests/code) are run with 'go test' instead.
+func (r *runner) exec(id string, args ...string) error {
+
+	if !known(id) {
+		return fmt.Errorf("%s is not a lesson (see gotraining list)", id)
+	}
+
+	file := filepath.FromSlash(id + ".go")
+
+	main, err := isMain(filepath.Join(r.root, file))
+
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+
+	if main {
+		cmd = exec.Command("go", append([]string{"run", file}, args...)...)
+	} else {
+		cmd = exec.Command("go", "test", "-v", "./"+filepath.Dir(file))
+	}
+
+	cmd.Dir = r.root
+	cmd.Env = append(os.Environ(), tutorial.OutputEnvVar+"="+r.format, tutorial.ClockEnvVar+"="+r.clock)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+
+	if _, okay := err.(*exec.ExitError); okay && !main {
+		// The unit test lesson deliberately contains a failing test, so a test run that fails has still done its job
+		return nil
+	}
+
+	return err
+}
+
+func known(id string) bool {
+
+	for _, k := range tutorial.LessonIDs() {
+		if k == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isMain(path string) (bool, error) {
+
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+
+	if err != nil {
+		return false, err
+	}
+
+	return f.Name.Name == "main", nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/benhalstead/gotraining/playground"
+)
+
+// playground serves a page where learners can edit lessons and run them
+func (r *runner) playground(args []string) error {
+
+	limits := playground.DefaultLimits
+
+	fs := flag.NewFlagSet("playground", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8081", "the address to serve the playground from")
+	concurrent := fs.Int("concurrent", 2, "how many programs may be built or run at once")
+	fs.DurationVar(&limits.RunTimeout, "timeout", limits.RunTimeout, "how long a program may run for")
+	fs.DurationVar(&limits.CPUTime, "cpu", limits.CPUTime, "how much CPU time a program may use")
+	fs.IntVar(&limits.MaxOutput, "output", limits.MaxOutput, "the most a program may print, in bytes")
+
+	fs.Parse(args)
+
+	fmt.Printf("Serving the playground at http://%s/ (press Ctrl+C to stop)\n", *addr)
+	fmt.Printf("Anything submitted to the playground is run as you, so don't make it available to anyone else\n")
+
+	return playground.New(r.root, limits, *concurrent).ListenAndServe(*addr)
+}
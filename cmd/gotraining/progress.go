@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/benhalstead/gotraining/grader"
+	"github.com/benhalstead/gotraining/progress"
+	"github.com/benhalstead/gotraining/quiz"
+	"github.com/benhalstead/gotraining/tutorial"
+)
+
+// The layout used when printing the date something was completed
+const completedLayout = "2 Jan 2006 15:04"
+
+// next prints the first lesson in the reading order that the learner hasn't completed and, with -run, runs it
+func (r *runner) next(args []string) error {
+
+	fs := flag.NewFlagSet("next", flag.ExitOnError)
+	run := fs.Bool("run", false, "run the next lesson (which will mark it as completed)")
+
+	fs.Parse(args)
+
+	s, err := r.progress()
+
+	if err != nil {
+		return err
+	}
+
+	l := s.Learner(r.learner)
+
+	id, okay := l.Next(tutorial.LessonIDs())
+
+	if !okay {
+		fmt.Printf("%s has completed every lesson. Try 'gotraining grade' to check your exercises.\n", r.learner)
+		return nil
+	}
+
+	if *run {
+		return r.run([]string{id})
+	}
+
+	fmt.Printf("Next lesson for %s: %s (%s)\n", r.learner, id, chapterOf(id))
+	fmt.Printf("Read %s.go then run it with 'gotraining run %s'\n", id, id)
+
+	return nil
+}
+
+// status prints every lesson in reading order, marking those the learner has completed, followed by a summary of
+// their exercise results and quiz scores
+func (r *runner) status() error {
+
+	s, err := r.progress()
+
+	if err != nil {
+		return err
+	}
+
+	l := s.Learner(r.learner)
+
+	fmt.Printf("Progress for %s\n\n", r.learner)
+
+	done, total := 0, 0
+
+	for _, c := range tutorial.ReadingOrder {
+
+		fmt.Println(c.Title)
+
+		for _, id := range c.Lessons {
+
+			total++
+
+			if at, okay := l.Lessons[id]; okay {
+				done++
+				fmt.Printf("  [x] %-28s completed %s\n", id, at.Local().Format(completedLayout))
+			} else {
+				fmt.Printf("  [ ] %s\n", id)
+			}
+		}
+	}
+
+	fmt.Printf("\n%d of %d lessons completed\n", done, total)
+
+	topics, err := grader.Topics(r.root)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nExercises\n")
+
+	for _, t := range topics {
+
+		exercises, err := grader.Exercises(r.root, t)
+
+		if err != nil {
+			return err
+		}
+
+		passed, attempted := 0, 0
+
+		for _, e := range exercises {
+
+			res, okay := l.Exercises[progress.ExerciseKey(t, e.Name)]
+
+			if !okay {
+				continue
+			}
+
+			attempted++
+
+			if res.Passed {
+				passed++
+			}
+		}
+
+		fmt.Printf("  %-18s %d of %d passed (%d attempted)\n", t, passed, len(exercises), attempted)
+	}
+
+	fmt.Printf("\nQuizzes\n")
+
+	for _, id := range quiz.Lessons(r.root) {
+
+		if res, okay := l.Quizzes[id]; okay {
+			fmt.Printf("  %-28s best score %d of %d\n", id, res.Best, res.Total)
+		} else {
+			fmt.Printf("  %-28s not taken\n", id)
+		}
+	}
+
+	return nil
+}
+
+// reset forgets everything recorded for the learner
+func (r *runner) reset() error {
+
+	s, err := r.progress()
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.Reset(r.learner); err != nil {
+		return err
+	}
+
+	fmt.Printf("Progress for %s has been reset\n", r.learner)
+
+	return nil
+}
+
+func chapterOf(id string) string {
+
+	for _, c := range tutorial.ReadingOrder {
+		for _, l := range c.Lessons {
+			if l == id {
+				return c.Title
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/benhalstead/gotraining/quiz"
+	"os"
+	"strings"
+	"time"
+)
+
+// quiz asks the questions for each of the named lessons, or lists the lessons with quizzes if none are named (see
+// 'gotraining status' for scores). With
+// -serve, quizzes are taken in a browser instead.
+func (r *runner) quiz(args []string) error {
+
+	fs := flag.NewFlagSet("quiz", flag.ExitOnError)
+	serve := fs.String("serve", "", "serve quizzes from this address (e.g. localhost:8082) instead of asking them here")
+
+	fs.Parse(args)
+
+	s, err := r.progress()
+
+	if err != nil {
+		return err
+	}
+
+	if *serve != "" {
+		fmt.Printf("Serving quizzes at http://%s/ (press Ctrl+C to stop)\n", *serve)
+		return quiz.NewServer(r.root, s, r.learner).ListenAndServe(*serve)
+	}
+
+	if fs.NArg() == 0 {
+
+		for _, id := range quiz.Lessons(r.root) {
+			fmt.Println(id)
+		}
+
+		return nil
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+
+	for _, id := range fs.Args() {
+
+		q, err := quiz.Load(r.root, id)
+
+		if os.IsNotExist(err) {
+			return fmt.Errorf("there is no quiz for %s (run 'gotraining quiz' to see which lessons have quizzes)", id)
+		} else if err != nil {
+			return err
+		}
+
+		res := ask(q, in)
+
+		fmt.Printf("You scored %d out of %d for %s\n\n", res.Score, res.Total, id)
+
+		if err := s.RecordQuiz(r.learner, id, res.Score, res.Total, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ask puts each question to the learner and marks their responses
+func ask(q *quiz.Quiz, in *bufio.Scanner) quiz.Result {
+
+	responses := make(map[string]string)
+
+	for i, qu := range q.Questions {
+
+		fmt.Printf("%d. %s\n", i+1, qu.Prompt)
+
+		if qu.Code != "" {
+			fmt.Println()
+			printCode(qu.Code)
+		}
+
+		fmt.Println()
+
+		var resp string
+
+		switch qu.Kind {
+		case quiz.Choice:
+
+			for j, c := range qu.Choices {
+				fmt.Printf("  %c) %s\n", 'a'+j, c)
+			}
+
+			fmt.Print("\nYour answer: ")
+			resp = readLine(in)
+
+		case quiz.Output:
+
+			fmt.Println("Type the output, followed by an empty line:")
+
+			var lines []string
+
+			for l := readLine(in); l != ""; l = readLine(in) {
+				lines = append(lines, l)
+			}
+
+			resp = strings.Join(lines, "\n")
+
+		default:
+			fmt.Print("Fill in the blank: ")
+			resp = readLine(in)
+		}
+
+		responses[qu.ID] = resp
+
+		if qu.Check(resp) {
+			fmt.Println("Correct!")
+		} else {
+			fmt.Println("Not quite. The answer is:")
+			printCode(qu.Answer)
+		}
+
+		if qu.Explanation != "" {
+			fmt.Println(qu.Explanation)
+		}
+
+		fmt.Println()
+	}
+
+	return q.Mark(responses)
+}
+
+func readLine(in *bufio.Scanner) string {
+
+	if !in.Scan() {
+		return ""
+	}
+
+	return in.Text()
+}
+
+// printCode prints code indented, keeping its own indentation
+func printCode(s string) {
+
+	for _, l := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		fmt.Printf("    %s\n", l)
+	}
+}
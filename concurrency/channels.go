@@ -1,14 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"github.com/benhalstead/gotraining/chanwatch"
+	"github.com/benhalstead/gotraining/fetch"
+	"github.com/benhalstead/gotraining/limit"
+	"github.com/benhalstead/gotraining/metrics"
+	"github.com/benhalstead/gotraining/pipeline"
+	"github.com/benhalstead/gotraining/pubsub"
 	"github.com/benhalstead/gotraining/tutorial"
-	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
 func main() {
 
+	tutorial.Register("concurrency", "channels").
+		Section("basics", channelBasicsExample).
+		Section("types", channelTypesExample).
+		Section("closing", closingExamples).
+		Section("pipelines", pipelineExample).
+		Section("pubsub", pubSubExample).
+		Section("watching", watchingExample).
+		Section("select", selectExample).
+		Run()
+}
+
+func channelBasicsExample() {
+
 	tutorial.Section("Initialising channels")
 
 	// Channels are a type and they can be declared as other variables are...
@@ -70,11 +92,6 @@ func main() {
 	b = <-ic
 
 	fmt.Printf("%d %d\n", a, b)
-
-	channelTypesExample()
-	closingExamples()
-	selectExample()
-
 }
 
 func channelTypesExample() {
@@ -86,6 +103,8 @@ func channelTypesExample() {
 
 	type S struct{}
 
+	// A channel of channels lets one goroutine hand another a channel to reply on, or register to receive values. The
+	// pubsub package (see pubSubExample) grows this idea into a broker that sends each subscriber its own channel.
 	cc := make(chan chan int)
 	tutorial.TypeValue(cc)
 
@@ -129,76 +148,303 @@ func closingExamples() {
 	}
 }
 
+func pipelineExample() {
+	tutorial.Section("Pipelines")
+
+	// Because a goroutine can range over one channel and send what it makes on another, goroutines can be chained
+	// together into a 'pipeline'. Each stage only knows about its input and output channels, and every stage runs at
+	// the same time as the others.
+	//
+	// The hard part is stopping. Every stage must close its output when it is done (so the next stage's range loop
+	// ends), and if the consumer stops reading, or a stage fails, every stage must notice and exit - otherwise they sit
+	// blocked on a send forever (a goroutine leak, see the goroutines lesson).
+	//
+	// The pipeline package has the common stages. Each of them takes a context created by pipeline.New: stopping the
+	// pipeline (or any stage returning an error) cancels the context, and every stage exits and closes its output.
+	p, ctx := pipeline.New(context.Background())
+
+	// Generate runs a function that 'emits' values. This one would go on forever if nothing stopped it
+	words := []string{"apple", "banana", "cherry", "damson", "elderberry", "fig", "grape"}
+
+	source := pipeline.Generate(ctx, func(ctx context.Context, emit func(string) bool) error {
+
+		for i := 0; ; i++ {
+			if !emit(words[i%len(words)]) {
+				return nil
+			}
+		}
+	})
+
+	// Filter keeps some values, Map transforms each value (possibly into a different type)
+	short := pipeline.Filter(ctx, source, func(ctx context.Context, w string) (bool, error) {
+		return len(w) <= 6, nil
+	})
+
+	// FanOut shares values between several goroutines, which is useful when one stage is much slower than the others,
+	// and Merge (a 'fan in') combines several channels back into one. Values come out of Merge in no particular order.
+	var shouted []<-chan string
+
+	for _, c := range pipeline.FanOut(ctx, short, 3) {
+		shouted = append(shouted, pipeline.Map(ctx, c, func(ctx context.Context, w string) (string, error) {
+			return strings.ToUpper(w), nil
+		}))
+	}
+
+	// Batch groups values into slices
+	batches := pipeline.Batch(ctx, pipeline.Merge(ctx, shouted...), 4, 0)
+
+	// We only want three batches, so we stop the pipeline once we have them. Every stage exits, even though the
+	// source would otherwise run forever
+	var received []string
+
+	for b := range batches {
+
+		received = append(received, b...)
+
+		if len(received) == 12 {
+			p.Stop()
+			break
+		}
+	}
+
+	if err := p.Wait(); err != nil {
+		fmt.Printf("Pipeline failed: %s\n", err.Error())
+	}
+
+	// Which values we received depends on how the goroutines in the fan out were scheduled, but they are all short
+	// and upper case
+	allShouted := true
+
+	for _, w := range received {
+		allShouted = allShouted && len(w) <= 6 && w == strings.ToUpper(w)
+	}
+
+	fmt.Printf("Received %d values, all short and upper case: %t\n", len(received), allShouted)
+
+	// If a stage returns an error, the whole pipeline stops and Wait returns the error
+	p, ctx = pipeline.New(context.Background())
+
+	checked := pipeline.Map(ctx, pipeline.Generate(ctx, func(ctx context.Context, emit func(string) bool) error {
+
+		for _, w := range words {
+			if !emit(w) {
+				return nil
+			}
+		}
+
+		return nil
+	}), func(ctx context.Context, w string) (string, error) {
+
+		if len(w) > 8 {
+			return "", fmt.Errorf("%s is too long", w)
+		}
+
+		return w, nil
+	})
+
+	for w := range checked {
+		fmt.Printf("Checked %s\n", w)
+	}
+
+	if err := p.Wait(); err != nil {
+		fmt.Printf("Pipeline failed: %s\n", err.Error())
+	}
+
+	// The package also has Tee, which sends every value to two consumers, and OrDone, which reads from a channel that
+	// isn't part of the pipeline until the pipeline stops
+}
+
+func pubSubExample() {
+	tutorial.Section("Publish and subscribe")
+
+	// A value sent on a channel is received by exactly one receiver. To 'broadcast' a value to several goroutines, each
+	// of them needs its own channel and something has to send the value on all of them. The pubsub package does this:
+	// goroutines subscribe to topics and each subscription has its own channel.
+	broker := pubsub.New()
+
+	// Topics are dot-separated words. In a pattern, * matches any one word and > matches everything after it
+	created, err := broker.Subscribe("orders.*.created", pubsub.Options{})
+
+	if err != nil {
+		fmt.Printf("Couldn't subscribe: %s\n", err.Error())
+		return
+	}
+
+	// Each subscription has a buffer. If the subscriber falls behind and the buffer fills up, the policy decides what
+	// happens: wait for room (the default), throw away the oldest or newest message, or disconnect the subscriber
+	audit, err := broker.Subscribe("orders.>", pubsub.Options{Buffer: 100, Policy: pubsub.DropOldest})
+
+	if err != nil {
+		fmt.Printf("Couldn't subscribe: %s\n", err.Error())
+		return
+	}
+
+	var wg sync.WaitGroup
+	received := make([][]string, 2)
+
+	for i, s := range []*pubsub.Subscription{created, audit} {
+
+		wg.Add(1)
+
+		go func(i int, s *pubsub.Subscription) {
+			defer wg.Done()
+
+			// The channel is closed when the broker shuts down, which ends the loop
+			for m := range s.C() {
+				received[i] = append(received[i], fmt.Sprintf("%s=%v", m.Topic, m.Payload))
+			}
+		}(i, s)
+	}
+
+	ctx := context.Background()
+
+	broker.Publish(ctx, "orders.eu.created", 1001)
+	broker.Publish(ctx, "orders.eu.shipped", 1001)
+	broker.Publish(ctx, "orders.us.created", 1002)
+
+	// Shutdown waits until every subscriber has received what was published (or ctx is cancelled) then closes their
+	// channels. Close would throw away anything not yet received instead.
+	if err := broker.Shutdown(ctx); err != nil {
+		fmt.Printf("Shutdown failed: %s\n", err.Error())
+	}
+
+	wg.Wait()
+
+	fmt.Printf("%s received %v\n", created.Pattern(), received[0])
+	fmt.Printf("%s received %v\n", audit.Pattern(), received[1])
+}
+
+func watchingExample() {
+	tutorial.Section("Watching channels")
+
+	// A program that hangs is usually stuck sending on a channel that nothing reads any more, or receiving from one that
+	// nothing writes to (or closes). Go doesn't tell you which. The chanwatch package wraps a channel so that any send or
+	// receive blocked for longer than a threshold is reported, along with where it was called from. It also counts
+	// sends and receives, remembers the most values that have been waiting to be received and adds up the time spent
+	// blocked.
+	//
+	// Reports are logged unless you provide OnBlocked. Like the Limiter in the goroutines lesson, the channel is given
+	// the tutorial's clock.
+	results := chanwatch.New[int](0, chanwatch.Options{
+		Name:      "results",
+		Threshold: 50 * time.Millisecond,
+		Clock:     tutorial.CurrentClock(),
+		OnBlocked: func(b chanwatch.Blocked) {
+			// b.File and b.Line say exactly where the operation is
+			fmt.Printf("%s on %s blocked for %v in %s\n", b.Op, b.Channel, b.Waited, b.Function)
+		},
+	})
+
+	done := make(chan bool)
+
+	go func() {
+		for i := 1; i <= 3; i++ {
+			results.Send(i)
+		}
+
+		results.Close()
+		done <- true
+	}()
+
+	// This receiver is slow, so every send on the unbuffered channel waits for it
+	for {
+		tutorial.Sleep(100 * time.Millisecond)
+
+		v, okay := results.Receive()
+
+		if !okay {
+			break
+		}
+
+		fmt.Printf("Received %d\n", v)
+	}
+
+	<-done
+
+	stats := results.Stats()
+	fmt.Printf("%d sends and %d receives, senders were blocked for %v\n", stats.Sends, stats.Receives, stats.SendBlocked)
+
+	// Watching costs very little when a send or receive doesn't have to wait, and chanwatch.SetEnabled(false) turns it
+	// off everywhere, leaving just the channel
+}
+
 func selectExample() {
 	tutorial.Section("Select and example")
 
-	// In this example we fetch three web pages concurrently using a 'get' function, channels
-	// and the channel specific 'select' control structure to detect when the work is done
+	// In this example we fetch three web pages concurrently and use the channel specific 'select' control structure to
+	// deal with each page as it arrives, report progress and give up if the pages take too long
 
-	// We will create a separate 'done' channel for each page we're requesting
+	// The fetch package does the concurrent part for us. It starts a goroutine for each page (but no more than Parallel
+	// at once), sends the result of each request on a channel and closes the channel when every page is done
+	//
+	// The Limiter stops the Fetcher starting more than 2 requests a second, which matters more when fetching many pages
+	// from the same host (see the limits section of the goroutines lesson)
+	//
+	// The Fetcher also records how long each request took, and counts the ones that failed, in a metrics Registry (see
+	// the atomic section of the mutex lesson). A real program would serve these to its monitoring system.
+	reg := metrics.NewRegistry()
 
-	// NOTE: this is an artificial example - if you were doing something this simple in real life
-	// you would use a sync.WaitGroup
-	// https://stackoverflow.com/questions/19208725/example-for-sync-waitgroup-correct
-	// https://golang.org/pkg/sync/#WaitGroup
+	f := &fetch.Fetcher{Parallel: 2, RequestTimeout: 5 * time.Second, Limiter: limit.NewLimiter(2, 2, nil), Metrics: reg}
 
-	// When each request is done, the method will use the channel to indicate how long the request took
-	googleDone := make(chan time.Duration)
-	bbcDone := make(chan time.Duration)
-	cfDone := make(chan time.Duration)
+	// Cancelling the context stops any requests that haven't finished (see the context lesson)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	start := time.Now()
 
-	// Spawn goroutines to fetch each URL, passing a pointer to an object to store the result in
-	go get("http://www.google.com", googleDone)
-	go get("http://www.bbc.com", bbcDone)
-	go get("http://www.cloudfactory.com", cfDone)
+	results := f.Fetch(ctx, []string{"http://www.google.com", "http://www.bbc.com", "http://www.cloudfactory.com"})
+
+	// time.After returns a channel that receives a value once the duration has passed, and a Ticker's channel receives
+	// a value every time the duration passes
+	giveUp := time.After(10 * time.Second)
 
-	waitingFor := 3
+	progress := time.NewTicker(time.Second)
+	defer progress.Stop()
 
 WaitLoop:
 	for {
 		// The select control structure is only useful for channels
-		// Each case in the select will fire if a value can be read from the channel
+		// It waits until one of its cases can proceed (if more than one can, one of them is picked at random)
 		select {
-		case t := <-bbcDone:
-			waitingFor--
-			printTimeTaken("BBC", t)
-		case t := <-googleDone:
-			waitingFor--
-			printTimeTaken("Google", t)
-		case t := <-cfDone:
-			waitingFor--
-			printTimeTaken("CF", t)
-		default:
-			// The default case will fire if none of the other cases do
-			if waitingFor == 0 {
+		case r, okay := <-results:
+
+			if !okay {
+				// Closed, so every page has been fetched
 				break WaitLoop
 			}
 
-			//In these circumstances it's common to sleep for a short time to stop the surrounding
-			//for loop consuming resources doing nothing but checks This time should be proportional
-			// to the activity in question
-			time.Sleep(time.Millisecond * 50)
-		}
+			if r.Err != nil {
+				fmt.Printf("Error reading %s: %s\n", r.URL, r.Err.Error())
+			} else {
+				fmt.Printf("%s returned %d (%d bytes)\n", r.URL, r.Status, r.Bytes)
+				printTimeTaken(r.URL, r.Duration)
+			}
 
+		case <-progress.C:
+			fmt.Println("Still waiting...")
+		case <-giveUp:
+			fmt.Println("Giving up")
+			break WaitLoop
+		}
 	}
 
-	elapsed := time.Since(start)
-	printTimeTaken("Overall", elapsed)
-}
+	printTimeTaken("Overall", time.Since(start))
 
-func get(url string, finish chan time.Duration) {
+	// Each timer is a histogram of request durations for one host
+	fmt.Println()
+	reg.WriteText(os.Stdout)
 
-	start := time.Now()
+	// A select with a default case never waits: the default case fires if none of the other cases can proceed. It is
+	// tempting to put a select with a default case in a loop (and sleep in the default case so the loop doesn't use all
+	// of a CPU) but that just delays noticing that something has happened. Waiting in select, as above, is better.
+	idle := make(chan bool)
 
-	if _, err := http.Get(url); err != nil {
-		fmt.Printf("Error reading %s: %s\n", url, err.Error())
+	select {
+	case <-idle:
+		fmt.Println("Received a value")
+	default:
+		fmt.Println("Nothing to receive")
 	}
-
-	elapsed := time.Since(start)
-
-	finish <- elapsed
 }
 
 func printTimeTaken(label string, t time.Duration) {
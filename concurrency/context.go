@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/benhalstead/gotraining/ctxkey"
+	"github.com/benhalstead/gotraining/group"
 	"github.com/benhalstead/gotraining/tutorial"
+	"github.com/benhalstead/gotraining/worker"
+	"time"
 )
 
 func main() {
@@ -33,11 +38,13 @@ func main() {
 
 	// The decision to solve two different problems with one data structure means that the documentation for contexts is unfortunately confusing
 
-	creatingContextsExample()
-	avoidingKeyCollisionsExample()
-
-	cancellationExample()
-
+	tutorial.Register("concurrency", "context").
+		Section("creating", creatingContextsExample).
+		Section("collisions", avoidingKeyCollisionsExample).
+		Section("cancellation", cancellationExample).
+		Section("workers", workersExample).
+		Section("groups", groupsExample).
+		Run()
 }
 
 func creatingContextsExample() {
@@ -52,19 +59,37 @@ func creatingContextsExample() {
 	ctx := context.Background()
 
 	// When the context is manipulated (say, when adding a variable) a new context is DERIVED from the existing one
-	// the functions context.With* are used for this
-	ctx = context.WithValue(ctx, "authToken", "DFASDAS;X1]a")
-	ctx = context.WithValue(ctx, "permissions", "AbbC,A11d")
+	// the functions context.With* are used for this. Here we use keys from the ctxkey package (declared below), which
+	// call context.WithValue for us
+	ctx = authToken.With(ctx, "DFASDAS;X1]a")
+	ctx = permissions.With(ctx, []string{"AbbC", "A11d"})
 
 	// Any values present in the parent context are copied into the derived context
-	// Values can be read from the context
+	// Values can be read from the context. context.Context.Value returns type interface{}, but the key knows the type
+	// of its value so returns a string or []string
+
+	fmt.Println(authToken.Get(ctx))
+	fmt.Println(permissions.Get(ctx))
+
+	// Lookup also tells you whether the context has a value for the key
+	if _, okay := requestID.Lookup(ctx); !okay {
+		fmt.Println("No request ID")
+	}
 
-	at := ctx.Value("authToken")
-	println(at)
-	// but will be returned as type interface{} it is common to provide helper functions for reading values back out of a context (see other examples)
+	// A key can have a default value, which is used when the context has no value for it
+	fmt.Println(locale.Get(ctx))
 
+	// MustGet is for values that have to be there. It panics if the context has no value (and the key has no default)
+	fmt.Println(authToken.MustGet(ctx))
 }
 
+// Keys for values stored in contexts. Every key created by ctxkey.New is different from every other key, even if they
+// have the same name, so no other code can overwrite these values (see the next section for why that matters)
+var authToken = ctxkey.New[string]("authToken")
+var permissions = ctxkey.New[[]string]("permissions")
+var requestID = ctxkey.New[string]("requestID")
+var locale = ctxkey.WithDefault("locale", "en-GB")
+
 type key string
 
 var authAsKey = key("authToken")
@@ -95,47 +120,211 @@ func avoidingKeyCollisionsExample() {
 	tutorial.TypeValue(j)
 
 	// For this to work, your type must remain unexported (otherwise other code to use your type and overwrite your value)
-	// This means that you will need to declare helper functions to read your data out of a context
+	// This means that you will need to declare helper functions to read your data out of a context.
+	//
+	// The ctxkey package used in the previous section does all of this for you: each key is a pointer to a different
+	// variable, so no two keys are ever equal and only code that can see a key can read or replace its value
 
-	fmt.Println(ReadAuthTokenZeroVal(ctx))
+	ctx = authToken.With(ctx, "WXYZ")
 
-	if v, okay := ReadAuthTokenOkay(ctx); okay {
-		fmt.Println(v)
-	}
+	fmt.Println(authToken.Get(ctx))
+	fmt.Println(ctx.Value(authAsString))
+	fmt.Println(ctx.Value(authAsKey))
 
 }
 
-// A helper function for reading a value from a context that returns a zero value if the key is not found or is not the expected type
-func ReadAuthTokenZeroVal(ctx context.Context) string {
+func cancellationExample() {
+
+	tutorial.Section("Cancelling goroutines")
 
-	result := ""
+	// context.WithCancel derives a new context and returns a function that cancels it. Cancelling a context closes the
+	// channel returned by its Done method, which is how a goroutine finds out it should stop
+	ctx, cancel := context.WithCancel(context.Background())
 
-	if v := ctx.Value(authAsString); v != nil {
+	work := make(chan int)
+	stopped := make(chan bool)
 
-		if s, okay := v.(string); okay {
-			result = s
+	go func() {
+
+		for {
+			// A goroutine that can be cancelled waits on ctx.Done() alongside whatever else it is waiting for
+			select {
+			case <-ctx.Done():
+				// Err explains why the context ended
+				fmt.Println("Stopping:", ctx.Err())
+				stopped <- true
+				return
+			case i := <-work:
+				fmt.Println("Working on", i)
+			}
 		}
+	}()
+
+	work <- 1
+	work <- 2
+
+	cancel()
+	<-stopped
+
+	// Cancelling is a request, not an order. A goroutine that never checks ctx.Done() (or a function that doesn't
+	// accept a context) carries on regardless.
+
+	tutorial.Section("Timeouts and deadlines")
+
+	// context.WithTimeout and context.WithDeadline derive contexts that cancel themselves after a time. You must still
+	// call the cancel function they return (usually with defer) to release their resources if you finish early.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
 
+	select {
+	case <-ctx.Done():
+		fmt.Println("Gave up waiting:", ctx.Err())
+	case <-time.After(time.Second):
+		fmt.Println("Finished the slow work")
 	}
 
-	return result
+	deadline := time.Now().Add(-time.Minute)
+	ctx, cancel = context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	// A deadline in the past means the context is done before anyone has looked at it
+	fmt.Println("Already done:", ctx.Err())
+
+	// Cancellation flows down the hierarchy of contexts: cancelling a context cancels every context derived from it,
+	// but cancelling a derived context doesn't affect its parent
+	parent, cancelParent := context.WithCancel(context.Background())
+	child, cancelChild := context.WithCancel(parent)
+	defer cancelChild()
+
+	cancelParent()
+
+	<-child.Done()
+	fmt.Println("Child:", child.Err())
 }
 
-// A helper function for reading a value from a context that returns an additional bool to indicate if a key is missing
-// or not the expected type
-func ReadAuthTokenOkay(ctx context.Context) (string, bool) {
+func workersExample() {
 
-	if v := ctx.Value(authAsString); v != nil {
+	tutorial.Section("Workers")
 
-		if s, okay := v.(string); okay {
-			return s, true
-		}
+	// The worker package wraps up the pattern above: each worker runs a function in its own goroutine with a context
+	// it is expected to honour, and records why it stopped
+	w := worker.StartWithTimeout(context.Background(), "poller", 50*time.Millisecond, poll)
+
+	fmt.Println(w.Name(), "stopped:", w.Wait())
+
+	w = worker.Start(context.Background(), "poller", poll)
+
+	fmt.Println(w.Name(), "stopped:", w.Stop())
+
+	w = worker.Start(context.Background(), "counter", func(ctx context.Context) error {
+		fmt.Println("Counted to 3")
+		return nil
+	})
+
+	fmt.Println(w.Name(), "stopped:", w.Wait())
+
+	tutorial.Section("Nested workers")
+
+	// Workers started with another worker's context belong to it. Stopping (or timing out) the parent stops them too,
+	// and the parent doesn't finish stopping until they have.
+	busy := make(chan bool)
+
+	server := worker.Start(context.Background(), "server", func(ctx context.Context) error {
+
+		worker.Start(ctx, "request 1", poll)
+		worker.Start(ctx, "request 2", poll)
+
+		// This request times out by itself, without affecting the server or the other requests
+		slow := worker.StartWithTimeout(ctx, "request 3", 10*time.Millisecond, poll)
+		slow.Wait()
 
+		busy <- true
+
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	<-busy
+
+	fmt.Println(server.Name(), "stopped:", server.Stop())
+
+	for _, r := range server.Children() {
+		fmt.Println(r.Name(), "stopped:", r.Reason())
+	}
+}
+
+func groupsExample() {
+
+	tutorial.Section("Groups")
+
+	// Waiting for several goroutines with a channel each or a sync.WaitGroup works, but neither brings back errors. A
+	// group.Group runs functions in their own goroutines, waits for them and collects their errors. Every function is
+	// given the group's context, which is cancelled as soon as one of them fails.
+	//
+	// Here we build a product page from three services. If any of them fails there is no page, so there's no point
+	// waiting for the others.
+	g, _ := group.New(context.Background())
+
+	for _, service := range []string{"stock", "prices", "reviews"} {
+
+		// Each closure needs its own copy of service (see the closures in the goroutines lesson)
+		service := service
+
+		g.Go(func(ctx context.Context) error {
+
+			if service == "prices" {
+				worker.Sleep(ctx, 10*time.Millisecond)
+				return fmt.Errorf("%s is unavailable", service)
+			}
+
+			// These would take a second, but give up as soon as prices fails
+			return worker.Sleep(ctx, time.Second)
+		})
 	}
 
-	return "", false
+	// Wait returns the first error
+	fmt.Println("Page failed:", g.Wait())
+
+	// WaitAll returns every error. The functions that gave up because of the first error return the context's error.
+	g, _ = group.New(context.Background())
+
+	g.Go(func(ctx context.Context) error {
+		return errors.New("stock is unavailable")
+	})
+
+	g.Go(func(ctx context.Context) error {
+		return worker.Sleep(ctx, time.Second)
+	})
+
+	fmt.Println("Page failed:", g.WaitAll())
+
+	// A panic in a goroutine normally crashes the whole program. A panic in a group's function is recovered and
+	// returned as a *group.PanicError, which includes the stack trace of the goroutine that panicked.
+	g, _ = group.New(context.Background())
+
+	g.Go(func(ctx context.Context) error {
+
+		var reviews map[string]int
+		reviews["5 stars"]++
+
+		return nil
+	})
+
+	var p *group.PanicError
+
+	if err := g.Wait(); errors.As(err, &p) {
+		fmt.Println("Recovered from panic:", p.Value)
+	}
+
+	// g.SetLimit(n) stops more than n functions running at once: Go waits until there is room and TryGo returns false
 }
 
-func cancellationExample() {
-	// ??
+// poll pretends to check for new work every 10 milliseconds until it is told to stop
+func poll(ctx context.Context) error {
+
+	for {
+		if err := worker.Sleep(ctx, 10*time.Millisecond); err != nil {
+			return err
+		}
+	}
 }
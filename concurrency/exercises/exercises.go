@@ -0,0 +1,56 @@
+// Package exercises contains exercises for the concurrency lessons (concurrency/*.go). Replace the body of each
+// function so that it does what its comment describes, then check your answers with:
+//
+//	gotraining grade concurrency
+package exercises
+
+import (
+	"context"
+	"time"
+)
+
+// ParallelSum adds up nums by splitting them between the given number of goroutines, each of which sums its share and
+// sends the result back on a channel
+func ParallelSum(nums []int, workers int) int {
+	// TODO
+	return 0
+}
+
+// Counter is a count that can be safely incremented from many goroutines at once
+type Counter struct {
+	n int
+}
+
+// Inc adds one to the count
+func (c *Counter) Inc() {
+	// TODO
+}
+
+// Value returns the current count
+func (c *Counter) Value() int {
+	// TODO
+	return 0
+}
+
+// Merge returns a channel that receives every value sent on a and b. The returned channel must be closed once a and b
+// have both been closed.
+func Merge(a, b <-chan int) <-chan int {
+	// TODO
+	return nil
+}
+
+// ReceiveWithin waits up to d for a value on c. It returns the value and true, or 0 and false if nothing arrived in
+// time.
+func ReceiveWithin(c <-chan int, d time.Duration) (int, bool) {
+	// TODO
+	return 0, false
+}
+
+// Countdown sends n, n-1, ... 1 on the returned channel and then closes it. If ctx is cancelled first, it stops
+// sending and closes the channel straight away.
+func Countdown(ctx context.Context, n int) <-chan int {
+	// TODO
+	c := make(chan int)
+	close(c)
+	return c
+}
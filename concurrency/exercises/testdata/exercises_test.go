@@ -0,0 +1,161 @@
+package exercises
+
+import (
+	"context"
+	"github.com/benhalstead/gotraining/leak"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Hint: give each goroutine a sub-slice like nums[start:end] and read exactly one result per goroutine from the channel.
+func TestParallelSum(t *testing.T) {
+
+	// Fails the test if any goroutines are still running after it has finished
+	leak.VerifyNone(t)
+
+	nums := make([]int, 1000)
+
+	for i := range nums {
+		nums[i] = i + 1
+	}
+
+	for _, w := range []int{1, 3, 8} {
+		if s := ParallelSum(nums, w); s != 500500 {
+			t.Errorf("Expected 500500 with %d workers but found %d", w, s)
+		}
+	}
+}
+
+// Hint: add a sync.Mutex to Counter and hold it while reading or changing n. See exampleMutex in mutex.go.
+func TestCounter(t *testing.T) {
+
+	var c Counter
+	var wg sync.WaitGroup
+
+	for i := 0; i < 100; i++ {
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				c.Inc()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if v := c.Value(); v != 100000 {
+		t.Errorf("Expected 100000 but found %d (some increments were lost)", v)
+	}
+}
+
+// Hint: start a goroutine per input channel that ranges over it, and use a sync.WaitGroup to know when to close the output.
+func TestMerge(t *testing.T) {
+
+	leak.VerifyNone(t)
+
+	a := make(chan int)
+	b := make(chan int)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			a <- 1
+		}
+		close(a)
+	}()
+
+	go func() {
+		for i := 0; i < 4; i++ {
+			b <- 10
+		}
+		close(b)
+	}()
+
+	m := Merge(a, b)
+
+	if m == nil {
+		t.Fatalf("Expected a channel but found nil")
+	}
+
+	total := 0
+
+	done := time.After(5 * time.Second)
+
+	for {
+		select {
+		case v, open := <-m:
+			if !open {
+				if total != 43 {
+					t.Errorf("Expected the values to add up to 43 but found %d", total)
+				}
+				return
+			}
+			total += v
+		case <-done:
+			t.Fatalf("The merged channel was not closed")
+		}
+	}
+}
+
+// Hint: select on the channel and time.After(d). See selectExample in channels.go.
+func TestReceiveWithin(t *testing.T) {
+
+	c := make(chan int, 1)
+	c <- 7
+
+	if v, okay := ReceiveWithin(c, time.Second); v != 7 || !okay {
+		t.Errorf("Expected 7, true but found %d, %t", v, okay)
+	}
+
+	start := time.Now()
+
+	if v, okay := ReceiveWithin(c, 50*time.Millisecond); v != 0 || okay {
+		t.Errorf("Expected 0, false but found %d, %t", v, okay)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Errorf("ReceiveWithin waited far longer than it was asked to")
+	}
+}
+
+// Hint: in the sending goroutine, select between sending the next value and <-ctx.Done(), and defer close(c).
+//
+// The test fails if the sending goroutine is still blocked trying to send a value nobody will receive once it has finished.
+func TestCountdown(t *testing.T) {
+
+	leak.VerifyNone(t)
+
+	var got []int
+
+	for v := range Countdown(context.Background(), 3) {
+		got = append(got, v)
+	}
+
+	if len(got) != 3 || got[0] != 3 || got[2] != 1 {
+		t.Errorf("Expected [3 2 1] but found %v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := Countdown(ctx, 1000000)
+
+	<-c
+	cancel()
+
+	// Give Countdown time to notice it has been cancelled while nobody is receiving
+	time.Sleep(100 * time.Millisecond)
+
+	n := 0
+
+	for range c {
+		n++
+	}
+
+	if n > 1 {
+		t.Errorf("Expected Countdown to stop soon after being cancelled, but it sent %d more values", n)
+	}
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"github.com/benhalstead/gotraining/leak"
+	"github.com/benhalstead/gotraining/limit"
+	"github.com/benhalstead/gotraining/schedule"
 	"github.com/benhalstead/gotraining/tutorial"
+	"sync"
 	"time"
 )
 
@@ -27,17 +32,33 @@ func main() {
 
 	*/
 
+	tutorial.Register("concurrency", "goroutines").
+		Section("simple", simpleGoroutineExample).
+		Section("closure", closureGoroutineExample).
+		Section("outlive", outliveExample).
+		Section("leaks", leakExample).
+		Section("limits", limitsExample).
+		Section("schedules", schedulesExample).
+		Run()
+}
+
+func simpleGoroutineExample() {
+
 	tutorial.Section("Simple goroutine")
 
 	// A new goroutine is created by putting the keyword go in front of any function call
 	go waitMs(5, "Done")
 	fmt.Println("After goroutine")
 
-	// Note that these time.Sleep calls are needed in this illustration to stop the examples being run out of order!
-	time.Sleep(time.Millisecond * 100)
+	// Note that these Sleep calls are needed in this illustration to stop the examples being run out of order!
+	// tutorial.Sleep behaves just like time.Sleep, but lets the lessons be run without waiting (see tutorial.ClockEnvVar)
+	tutorial.Sleep(time.Millisecond * 100)
 
 	// You cannot easily capture the return value of the function that is being run in a goroutine
 	// This won't compile go b := echoBool(true) and nor would b := go echoBool(true)
+}
+
+func closureGoroutineExample() {
 
 	// You can run any function in a goroutine, include a function, but be careful as the closure will have
 	// access to everything that is in scope of the parent function
@@ -49,7 +70,7 @@ func main() {
 		a = 2
 	}()
 
-	time.Sleep(time.Millisecond * 100)
+	tutorial.Sleep(time.Millisecond * 100)
 	fmt.Printf("I think a is set to 1, but it is %d\n\n", a)
 
 	// goroutines can leak - it is possible to create goroutines that don't exit. This is the most
@@ -59,6 +80,9 @@ func main() {
 	// goroutine does not exit when the calling goroutine does
 	//
 	// The only exception to this is the 'main' goroutine - when that ends, all other go routines die
+}
+
+func outliveExample() {
 
 	tutorial.Section("goroutines can outlive calling goroutines")
 
@@ -69,18 +93,199 @@ func main() {
 
 	}()
 
-	time.Sleep(time.Second * 3)
+	tutorial.Sleep(time.Second * 3)
 
 	// There is no way of explicitly controlling or gather data on an individual goroutine once it has
 	// been spawned. Communication and data flow between goroutines are accomplished with channels and contexts, both of
 	// which are covered in later lessons
 }
 
+func leakExample() {
+
+	tutorial.Section("Finding leaked goroutines")
+
+	// firstResult asks three goroutines for an answer and returns the first one it gets. The other two goroutines
+	// block forever trying to send their answers to a channel nobody is reading any more, so they (and anything they
+	// refer to) are never freed.
+	//
+	// The leak package finds goroutines like these by comparing the goroutines running before and after a function is
+	// called (giving any new ones a grace period to finish). Tests can call leak.VerifyNone to fail if they leak
+	// goroutines, as the tests for the concurrency exercises do.
+	leaked := leak.Check(func() {
+		fmt.Println("First result:", firstResult(make(chan string)))
+	}, 100*time.Millisecond)
+
+	fmt.Println("Leaked goroutines:", len(leaked))
+
+	for _, g := range leaked {
+		fmt.Printf("%s is stuck in state '%s'\n", g.Entry, g.State)
+	}
+
+	// Giving the channel room for every answer lets the goroutines that lose the race finish
+	leaked = leak.Check(func() {
+		fmt.Println("First result:", firstResult(make(chan string, 3)))
+	}, 100*time.Millisecond)
+
+	fmt.Println("Leaked goroutines:", len(leaked))
+
+	// tickForTwoSeconds (above) isn't a leak as it finishes eventually, but it would be reported if it was still
+	// ticking at the end of the grace period
+}
+
+func limitsExample() {
+
+	tutorial.Section("Limiting how fast and how many")
+
+	// Starting a goroutine is cheap, so it is easy to start far more work at once than a remote service (or your own
+	// memory) can cope with. The limit package has two ways of holding goroutines back.
+	ctx := context.Background()
+
+	// A Limiter controls how fast something happens. This one allows 5 requests a second, and up to 2 straight away
+	// (the 'burst'). Wait blocks until the next request is allowed, or ctx is cancelled. TryAcquire doesn't wait, it
+	// just reports whether a request is allowed now.
+	//
+	// The last argument is the clock the Limiter uses to tell the time and to wait. nil means the real clock, but this
+	// lesson gives it the tutorial's clock, so it can be replayed without waiting to check the times it prints.
+	limiter := limit.NewLimiter(5, 2, tutorial.CurrentClock())
+	start := tutorial.Now()
+
+	for i := 1; i <= 5; i++ {
+
+		if err := limiter.Wait(ctx); err != nil {
+			fmt.Printf("Gave up: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("Request %d after %v\n", i, tutorial.Since(start).Round(time.Millisecond))
+	}
+
+	fmt.Println("Allowed without waiting:", limiter.TryAcquire())
+
+	// The rate and burst can be changed while the Limiter is in use (with SetRate and SetBurst), e.g. if a service
+	// asks us to slow down
+
+	// A Semaphore controls how much happens at once. Each goroutine acquires a share (its 'weight') before it starts
+	// and releases it when it is done. This one allows 2 jobs at once. The size can be changed with SetSize.
+	sem := limit.NewSemaphore(2)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	running, most := 0, 0
+
+	for i := 1; i <= 6; i++ {
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := sem.Wait(ctx, 1); err != nil {
+				return
+			}
+
+			defer sem.Release(1)
+
+			mu.Lock()
+			running++
+
+			if running > most {
+				most = running
+			}
+
+			mu.Unlock()
+
+			tutorial.Sleep(100 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	fmt.Println("Most jobs running at once:", most)
+}
+
+func schedulesExample() {
+
+	tutorial.Section("Running goroutines on a schedule")
+
+	// Loops like tickForTwoSeconds (or tutorial.Tick, used in the channels lesson) are the simplest way of doing
+	// something periodically, but they block until they finish, can't be stopped and drift as the work in the loop
+	// takes time. The schedule package runs jobs in their own goroutines at fixed intervals (schedule.Every), with a
+	// fixed delay between the end of one run and the start of the next (schedule.AfterDelay) or at the times given by
+	// a cron expression (schedule.Cron).
+	//
+	// Like the Limiter above, the scheduler is given the tutorial's clock
+	s := schedule.New(tutorial.CurrentClock())
+	start := tutorial.Now()
+
+	s.Add(schedule.Job{
+		Name:     "heartbeat",
+		Schedule: schedule.Every(300 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			fmt.Printf("Heartbeat after %v\n", tutorial.Since(start).Round(time.Millisecond))
+			return nil
+		},
+	})
+
+	// This job is due every 100ms but takes 250ms. Its Overlap policy decides what happens when a run is due while the
+	// previous one is still going: Skip it (the default), Queue it until the previous run finishes or Allow it to run
+	// alongside the previous one. Jobs can also have Jitter (a random delay added to each run) and a Timeout.
+	slow, err := s.Add(schedule.Job{
+		Name:     "report",
+		Schedule: schedule.Every(100 * time.Millisecond),
+		Overlap:  schedule.Skip,
+		Run: func(ctx context.Context) error {
+			fmt.Printf("Report started after %v\n", tutorial.Since(start).Round(time.Millisecond))
+
+			// Each run is given a context that is cancelled if the job is cancelled (with Entry.Cancel) or the scheduler
+			// is stopped
+			tutorial.Sleep(250 * time.Millisecond)
+			return nil
+		},
+	})
+
+	if err != nil {
+		fmt.Printf("Couldn't add the report job: %s\n", err.Error())
+		return
+	}
+
+	tutorial.Sleep(950 * time.Millisecond)
+
+	// Shutdown stops new runs and waits for any that are in progress (or until its context is cancelled)
+	s.Shutdown(context.Background())
+
+	fmt.Printf("Report ran %d times and was skipped %d times\n", slow.Runs(), slow.Skipped())
+
+	// Cron expressions give times of day: minute, hour, day of the month, month and day of the week. This one is 9:30
+	// on weekdays.
+	weekdays := schedule.MustCron("30 9 * * 1-5")
+	next := time.Date(2020, time.January, 3, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		next = weekdays.Next(next)
+		fmt.Println("Next weekday run:", next.Format("Mon 2 Jan 15:04"))
+	}
+}
+
+func firstResult(answers chan string) string {
+
+	for i := 1; i <= 3; i++ {
+		go func(i int) {
+			answers <- fmt.Sprintf("answer %d", i)
+		}(i)
+	}
+
+	return <-answers
+}
+
 func tickForTwoSeconds() {
 
 	for i := 0; i < 10; i++ {
 		fmt.Println("Tick")
-		time.Sleep(time.Millisecond * 200)
+		tutorial.Sleep(time.Millisecond * 200)
 	}
 
 	fmt.Println("tick goroutine ends")
@@ -92,7 +297,7 @@ func echoBool(b bool) bool {
 }
 
 func waitMs(i int, s string) bool {
-	time.Sleep(time.Millisecond * time.Duration(i))
+	tutorial.Sleep(time.Millisecond * time.Duration(i))
 
 	fmt.Println(s)
 
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"github.com/benhalstead/gotraining/group"
+	"github.com/benhalstead/gotraining/interleave"
+	"github.com/benhalstead/gotraining/metrics"
 	"github.com/benhalstead/gotraining/tutorial"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -10,10 +15,12 @@ import (
 
 func main() {
 
-	exampleDataRace()
-	exampleMutex()
-	exampleAtomic()
-
+	tutorial.Register("concurrency", "mutex").
+		Section("race", exampleDataRace).
+		Section("mutex", exampleMutex).
+		Section("interleaving", exampleInterleaving).
+		Section("atomic", exampleAtomic).
+		Run()
 }
 
 func exampleDataRace() {
@@ -30,30 +37,33 @@ func exampleDataRace() {
 
 	v := []int{}
 
-	oddDone := make(chan bool)
-	evenDone := make(chan bool)
+	// A group waits for both goroutines to finish (see the groups section of the context lesson)
+	g, _ := group.New(context.Background())
 
-	loop := func(id string, c chan bool, start int) {
+	loop := func(id string, start int) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
 
-		for i := start; i < 10; i = i + 2 {
-			v = append(v, i)
-			fmt.Printf("%s %v\n", id, v)
-			sleepMs(45)
-		}
+			for i := start; i < 10; i = i + 2 {
+				v = append(v, i)
+				fmt.Printf("%s %v\n", id, v)
+				sleepMs(45)
+			}
 
-		c <- true
+			return nil
+		}
 	}
 
-	go loop("Odd", oddDone, 1)
-	go loop("Even", evenDone, 0)
+	g.Go(loop("Odd", 1))
+	g.Go(loop("Even", 0))
 
-	<-oddDone
-	<-evenDone
+	g.Wait()
 
 	fmt.Printf("Contents: %v Length: %d\n", v, len(v))
 
 	// Sometimes when you run this code, only half of the values will be present in the array, This an example of a 'race condition' or
 	// 'data race' and the effect is to make the behaviour of this code 'nondeterministic'
+	//
+	// It doesn't happen very often. The interleaving section (exampleInterleaving) shows how it happens step by step
 
 }
 
@@ -73,31 +83,89 @@ func exampleMutex() {
 
 	v := []int{}
 
-	oddDone := make(chan bool)
-	evenDone := make(chan bool)
+	g, _ := group.New(context.Background())
 
-	loop := func(id string, c chan bool, start int) {
+	loop := func(id string, start int) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
 
-		for i := start; i < 10; i = i + 2 {
-			mx.Lock()
-			v = append(v, i)
-			fmt.Printf("%s %v\n", id, v)
-			mx.Unlock()
-			sleepMs(45)
-		}
+			for i := start; i < 10; i = i + 2 {
+				mx.Lock()
+				v = append(v, i)
+				fmt.Printf("%s %v\n", id, v)
+				mx.Unlock()
+				sleepMs(45)
+			}
 
-		c <- true
+			return nil
+		}
 	}
 
-	go loop("Odd", oddDone, 1)
-	go loop("Even", evenDone, 0)
+	g.Go(loop("Odd", 1))
+	g.Go(loop("Even", 0))
 
-	<-oddDone
-	<-evenDone
+	g.Wait()
 
 	fmt.Printf("Contents: %v Length: %d\n", v, len(v))
 }
 
+func exampleInterleaving() {
+	tutorial.Section("Seeing a data race")
+
+	// The data race in exampleDataRace only loses values if both goroutines read v before either of them has written
+	// the result of append back to it. That is unlikely when each goroutine sleeps between appends, so most of the time
+	// the race doesn't happen.
+	//
+	// The interleave package lets us choose the order. Goroutines started by an interleave.Recorder take turns to
+	// read or write shared data in the order given by a schedule, and every step they take is recorded. This schedule
+	// makes the goroutines take alternate steps, so they both read the slice before either of them writes to it.
+	schedule := []string{"odd", "even"}
+
+	race := interleave.New(schedule...)
+	raceValues := new(interleave.Slice)
+	appendInTurn(race, raceValues, nil)
+
+	// The same schedule with a mutex. When even tries to take the lock while odd holds it, even is blocked and loses
+	// its turns until odd releases the lock.
+	locked := interleave.New(schedule...)
+	lockedValues := new(interleave.Slice)
+	appendInTurn(locked, lockedValues, new(interleave.Mutex))
+
+	interleave.WriteASCII(os.Stdout,
+		interleave.Run{Title: "Without a mutex (exampleDataRace)", Recorder: race, Result: fmt.Sprintf("Contents: %v", raceValues.Values())},
+		interleave.Run{Title: "With a mutex (exampleMutex)", Recorder: locked, Result: fmt.Sprintf("Contents: %v", lockedValues.Values())},
+	)
+
+	// interleave.WriteHTML draws the same timelines side by side as a web page
+}
+
+// appendInTurn appends 0 to 3 to a shared slice from two goroutines, one appending odd numbers and the other even
+// numbers. If mx isn't nil, each goroutine holds it while it appends.
+func appendInTurn(r *interleave.Recorder, values *interleave.Slice, mx *interleave.Mutex) {
+
+	loop := func(start int) func(g *interleave.Goroutine) {
+		return func(g *interleave.Goroutine) {
+
+			for i := start; i < 4; i = i + 2 {
+
+				if mx != nil {
+					mx.Lock(g)
+				}
+
+				values.Append(g, i)
+
+				if mx != nil {
+					mx.Unlock(g)
+				}
+			}
+		}
+	}
+
+	r.Go("odd", loop(1))
+	r.Go("even", loop(0))
+
+	r.Wait()
+}
+
 func exampleAtomic() {
 	tutorial.Section("Atomics")
 
@@ -109,42 +177,58 @@ func exampleAtomic() {
 	// As the documentation for package emphasises, these functions and data structures are only for certain low level actions
 	// and are not really suitable as mechanism for coordinating between goroutines
 
-	// This example simulates a pair of request handlers and a counter showing how many requests are currently active
+	// This example simulates a pair of request handlers and a counter showing how many requests are currently active.
+	// The counter is an int64 that is only ever read or changed with the functions in sync/atomic.
+	//
+	// A real service would want its monitoring system to see counts like this. The metrics package in this repository
+	// keeps them for you: a Gauge is an int64 that goes up and down, changed with sync/atomic in just the same way, and
+	// a Counter is one that only goes up. Both can be served to a monitoring system like Prometheus.
 
 	var activeRequests int64
 
-	getDone := make(chan bool)
-	postDone := make(chan bool)
+	activeGauge := metrics.Default.Gauge("lesson_active_requests", "Requests currently being handled", nil)
 
-	loop := func(c chan bool) {
+	g, _ := group.New(context.Background())
 
-		for i := 1; i < 10; i++ {
-			simulateRequest(&activeRequests)
-		}
+	loop := func(method string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
 
-		c <- true
+			handled := metrics.Default.Counter("lesson_requests_total", "Requests handled", metrics.Labels{"method": method})
 
+			for i := 1; i < 10; i++ {
+				simulateRequest(&activeRequests, activeGauge)
+				handled.Inc()
+			}
+
+			return nil
+		}
 	}
 
-	go loop(getDone)
-	go loop(postDone)
+	g.Go(loop("GET"))
+	g.Go(loop("POST"))
 
-	<-getDone
-	<-postDone
+	g.Wait()
 
-	fmt.Printf("End count should always be zero, is: %d", activeRequests)
+	fmt.Printf("End count should always be zero, is: %d\n", atomic.LoadInt64(&activeRequests))
 
+	// This is what a monitoring system would see if it asked for the metrics (see metrics.Registry.Handler)
+	fmt.Println()
+	metrics.Default.WriteText(os.Stdout)
 }
 
-func simulateRequest(activeRequests *int64) {
-	c := atomic.AddInt64(activeRequests, 1) //
+func simulateRequest(activeRequests *int64, activeGauge *metrics.Gauge) {
+	c := atomic.AddInt64(activeRequests, 1)
 	defer atomic.AddInt64(activeRequests, -1)
 
+	// The Gauge does the same with its own int64 (see Gauge.Inc)
+	activeGauge.Inc()
+	defer activeGauge.Dec()
+
 	fmt.Printf("Currently active: %d\n", c)
 
 	sleepMs(25)
 }
 
 func sleepMs(ms int) {
-	time.Sleep(time.Millisecond * time.Duration(ms))
+	tutorial.Sleep(time.Millisecond * time.Duration(ms))
 }
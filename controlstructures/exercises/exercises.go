@@ -0,0 +1,31 @@
+// Package exercises contains exercises for the control structures lessons (controlstructures/*.go). Replace the body
+// of each function so that it does what its comment describes, then check your answers with:
+//
+//	gotraining grade controlstructures
+package exercises
+
+// Sign returns "negative", "zero" or "positive" depending on the value of n
+func Sign(n int) string {
+	// TODO
+	return ""
+}
+
+// FizzBuzz returns the numbers from 1 to n as strings, except that multiples of 3 are replaced with "Fizz", multiples
+// of 5 with "Buzz" and multiples of both with "FizzBuzz"
+func FizzBuzz(n int) []string {
+	// TODO
+	return nil
+}
+
+// Kind returns "int", "string" or "bool" depending on the type of the value in i, or "unknown" for any other type
+func Kind(i interface{}) string {
+	// TODO
+	return ""
+}
+
+// Find returns the row and column of the first occurrence of target in grid (searching each row from left to right,
+// top row first), or -1, -1 if target is not in the grid
+func Find(grid [][]int, target int) (row, col int) {
+	// TODO
+	return 0, 0
+}
@@ -0,0 +1,75 @@
+package exercises
+
+import "testing"
+
+// Hint: an if/else if/else chain (see ifelse.go) or a switch with no value (switch { case n < 0: ... }) will work.
+func TestSign(t *testing.T) {
+
+	expected := map[int]string{
+		-5: "negative",
+		0:  "zero",
+		12: "positive",
+	}
+
+	for n, e := range expected {
+		if s := Sign(n); s != e {
+			t.Errorf("Expected Sign(%d) to be %q but found %q", n, e, s)
+		}
+	}
+}
+
+// Hint: check for multiples of both 3 and 5 first. strconv.Itoa converts an int to a string.
+func TestFizzBuzz(t *testing.T) {
+
+	expected := []string{"1", "2", "Fizz", "4", "Buzz", "Fizz", "7", "8", "Fizz", "Buzz", "11", "Fizz", "13", "14", "FizzBuzz"}
+
+	result := FizzBuzz(15)
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d values but found %d", len(expected), len(result))
+	}
+
+	for i, e := range expected {
+		if result[i] != e {
+			t.Errorf("Expected %q at index %d but found %q", e, i, result[i])
+		}
+	}
+}
+
+// Hint: a type switch (switch i.(type)) is the idiomatic way to do this. See switch.go.
+func TestKind(t *testing.T) {
+
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{1, "int"},
+		{"one", "string"},
+		{true, "bool"},
+		{1.5, "unknown"},
+	}
+
+	for _, c := range cases {
+		if k := Kind(c.value); k != c.expected {
+			t.Errorf("Expected Kind(%v) to be %q but found %q", c.value, c.expected, k)
+		}
+	}
+}
+
+// Hint: you can return from inside nested loops, or break out of both with a label (see forloop.go).
+func TestFind(t *testing.T) {
+
+	grid := [][]int{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 5, 9},
+	}
+
+	if r, c := Find(grid, 5); r != 1 || c != 1 {
+		t.Errorf("Expected to find 5 at 1, 1 but found %d, %d", r, c)
+	}
+
+	if r, c := Find(grid, 10); r != -1 || c != -1 {
+		t.Errorf("Expected -1, -1 for a missing value but found %d, %d", r, c)
+	}
+}
@@ -8,12 +8,26 @@ import (
 // There are no while or do while loops in Go. The for statement is flexible enough to simulate those
 func main() {
 
+	a.Register("controlstructures", "forloop").
+		Section("for", forExample).
+		Section("while", whileExample).
+		Section("continue", continueExample).
+		Section("break", breakExample).
+		Section("label", labelExample).
+		Run()
+}
+
+func forExample() {
+
 	// The standard 'for' statement follows the C convention with an initialisation, a test and an increment/decrement
 	a.Section("For statement")
 
 	for i := 0; i < 2; i++ {
 		fmt.Printf("Counter is %d\n", i)
 	}
+}
+
+func whileExample() {
 
 	// If you just have a test, you can simulate a while loop
 	a.Section("For statement as a 'while' loop")
@@ -23,6 +37,9 @@ func main() {
 		fmt.Printf("Counter is %d\n", n)
 		n--
 	}
+}
+
+func continueExample() {
 
 	// continue 'skips' an iteration in a loop
 	a.Section("Using continue in a for loop")
@@ -36,6 +53,9 @@ func main() {
 
 		fmt.Printf("Counter is %d\n", i)
 	}
+}
+
+func breakExample() {
 
 	// break exits the current structure early
 	a.Section("Breaking out of a for loop")
@@ -49,6 +69,9 @@ func main() {
 
 		fmt.Printf("Counter is %d\n", i)
 	}
+}
+
+func labelExample() {
 
 	// Labels can be used to break to specific a.Sections of code - useful for nested loops
 	a.Section("Breaking to a label")
@@ -8,6 +8,14 @@ import (
 // if/else if/else block is common to most C-syntax languages
 func main() {
 
+	a.Register("controlstructures", "ifelse").
+		Section("if", ifExample).
+		Section("if-else", ifElseExample).
+		Run()
+}
+
+func ifExample() {
+
 	a.Section("Basic if statement")
 
 	n := 2
@@ -15,6 +23,11 @@ func main() {
 	if n == 2 {
 		fmt.Println("Match")
 	}
+}
+
+func ifElseExample() {
+
+	n := 2
 
 	// But the layout of the clause keywords is enforced by the compiler - else and else if must be on the same
 	// line as the closing brace for the previous clause
@@ -7,6 +7,17 @@ import (
 
 func main() {
 
+	ann.Register("controlstructures", "switch").
+		Section("bool", boolSwitchExample).
+		Section("known-value", knownValueSwitchExample).
+		Section("variable-case", variableCaseSwitchExample).
+		Section("no-fall-through", noFallThroughExample).
+		Section("type-switch", typeSwitchExample).
+		Run()
+}
+
+func boolSwitchExample() {
+
 	//You can switch on any statement that evaluates to a value:
 	ann.Section("Switch on bool evaluation")
 
@@ -18,6 +29,9 @@ func main() {
 	default:
 		//Compiler won't catch impossible to reach default statements
 	}
+}
+
+func knownValueSwitchExample() {
 
 	//Most commonly you will test for a known value
 	ann.Section("Switch on known value")
@@ -31,11 +45,14 @@ func main() {
 		fmt.Println("Expected")
 
 	}
+}
+
+func variableCaseSwitchExample() {
 
 	// But it is possible to have a case that matches based on another variable
 	ann.Section("Variable in case check ")
 
-	a = 2
+	a := 2
 	b := 2
 
 	switch a {
@@ -44,11 +61,14 @@ func main() {
 	default:
 		fmt.Println("Unexpected")
 	}
+}
+
+func noFallThroughExample() {
 
 	// There is no concept of fallthrough in Go Switch statements - one or zero cases will match and be executed
 	ann.Section("No fall through")
-	a = 1
-	b = 1
+	a := 1
+	b := 1
 	c := 1
 
 	switch a {
@@ -56,6 +76,9 @@ func main() {
 	case c:
 		fmt.Println("Will never be reached")
 	}
+}
+
+func typeSwitchExample() {
 
 	//Switch statements are an idiomatic way of handling variables where you don't know the type
 	ann.Section("Type switch")
@@ -0,0 +1,81 @@
+// Package ctxkey stores typed values in a context.Context.
+//
+// Values in a context are looked up by key, and any code with the same key can read or replace a value. The usual
+// protection is an unexported key type plus hand-written helpers to store and read each value. A Key does the same job
+// for any type of value: every Key is distinct from every other Key (even one with the same name and type, in the same
+// or a different package), and its methods only accept and return values of its type.
+//
+//	var authToken = ctxkey.New[string]("authToken")
+//
+//	ctx = authToken.With(ctx, "DFASDAS;X1]a")
+//
+//	if t, okay := authToken.Lookup(ctx); okay {
+//		...
+//	}
+package ctxkey
+
+import (
+	"context"
+	"fmt"
+)
+
+// A Key identifies a value of type T in a context. Keys must be created with New or WithDefault.
+type Key[T any] struct {
+	name       string
+	def        T
+	hasDefault bool
+}
+
+// New creates a Key. The name is only used to describe the key in messages.
+func New[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// WithDefault creates a Key whose value is def in contexts that don't have a value for it
+func WithDefault[T any](name string, def T) *Key[T] {
+	return &Key[T]{name: name, def: def, hasDefault: true}
+}
+
+// With derives a context from ctx in which the key's value is v
+func (k *Key[T]) With(ctx context.Context, v T) context.Context {
+	// The pointer to the Key is the context key, so no other Key (and no value of any other type) can match it
+	return context.WithValue(ctx, k, v)
+}
+
+// Get returns the key's value in ctx. If the context has no value for the key, it returns the key's default value (or
+// the zero value of T if it doesn't have one).
+func (k *Key[T]) Get(ctx context.Context) T {
+
+	v, _ := k.Lookup(ctx)
+
+	return v
+}
+
+// Lookup returns the key's value in ctx and true, or the key's default value (or the zero value of T) and false if the
+// context has no value for the key
+func (k *Key[T]) Lookup(ctx context.Context) (T, bool) {
+
+	if v, okay := ctx.Value(k).(T); okay {
+		return v, true
+	}
+
+	return k.def, false
+}
+
+// MustGet returns the key's value in ctx, or its default value if the context doesn't have one. It panics if there is
+// neither.
+func (k *Key[T]) MustGet(ctx context.Context) T {
+
+	v, okay := k.Lookup(ctx)
+
+	if !okay && !k.hasDefault {
+		panic(fmt.Sprintf("the context has no value for %s", k))
+	}
+
+	return v
+}
+
+// String returns the key's name
+func (k *Key[T]) String() string {
+	return fmt.Sprintf("ctxkey.Key[%T](%s)", k.def, k.name)
+}
@@ -0,0 +1,71 @@
+package ctxkey
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+
+	token := New[string]("token")
+	retries := WithDefault("retries", 3)
+
+	ctx := context.Background()
+
+	if v, okay := token.Lookup(ctx); okay || v != "" {
+		t.Errorf("Expected no token, got %q %v", v, okay)
+	}
+
+	if v := retries.Get(ctx); v != 3 {
+		t.Errorf("Expected the default number of retries, got %d", v)
+	}
+
+	if v := retries.MustGet(ctx); v != 3 {
+		t.Errorf("Expected MustGet to return the default, got %d", v)
+	}
+
+	ctx = token.With(ctx, "ABCD")
+	ctx = retries.With(ctx, 0)
+
+	if v, okay := token.Lookup(ctx); !okay || v != "ABCD" {
+		t.Errorf("Expected the token, got %q %v", v, okay)
+	}
+
+	if v, okay := retries.Lookup(ctx); !okay || v != 0 {
+		t.Errorf("Expected a zero value to be stored rather than replaced by the default, got %d %v", v, okay)
+	}
+
+	if v := token.MustGet(ctx); v != "ABCD" {
+		t.Errorf("Expected MustGet to return the token, got %q", v)
+	}
+}
+
+func TestKeysDoNotCollide(t *testing.T) {
+
+	a := New[string]("authToken")
+	b := New[string]("authToken")
+
+	ctx := a.With(context.Background(), "1234")
+
+	// A plain string key with the same name, as used by code that doesn't know about ctxkey
+	ctx = context.WithValue(ctx, "authToken", "ABCD")
+
+	if v := a.Get(ctx); v != "1234" {
+		t.Errorf("Expected the value stored with the key, got %q", v)
+	}
+
+	if _, okay := b.Lookup(ctx); okay {
+		t.Errorf("Expected a different key with the same name not to find the value")
+	}
+}
+
+func TestMustGetPanicsWithoutAValue(t *testing.T) {
+
+	defer func() {
+		if r := recover(); r != "the context has no value for ctxkey.Key[string](token)" {
+			t.Errorf("Expected MustGet to panic, got %v", r)
+		}
+	}()
+
+	New[string]("token").MustGet(context.Background())
+}
@@ -0,0 +1,105 @@
+// Package deps copies the packages in this repository that a program imports into a temporary module, so that the
+// program can be built on its own with nothing downloaded. The playground uses it to build learners' programs and the
+// grader to build their answers.
+package deps
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ModulePath is the module path of this repository. Temporary modules must use it too, so that imports of the
+// repository's packages work.
+const ModulePath = "github.com/benhalstead/gotraining"
+
+// WriteModule creates a go.mod file for a temporary module in dir
+func WriteModule(dir string) error {
+	return os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+ModulePath+"\n\ngo 1.18\n"), 0644)
+}
+
+// Copy copies every package in the repository at root that the Go files in sources import, directly or through other
+// packages in the repository, into the module in dir. Their tests aren't copied. Problems parsing sources aren't
+// reported, as building them will report them better than Copy could.
+func Copy(root, dir string, sources ...string) error {
+
+	fs := token.NewFileSet()
+	copied := make(map[string]bool)
+
+	for len(sources) > 0 {
+
+		f, err := parser.ParseFile(fs, sources[0], nil, parser.ImportsOnly)
+		sources = sources[1:]
+
+		// A file with a syntax error still has the imports that come before it
+		if err != nil && f == nil {
+			continue
+		}
+
+		for _, i := range f.Imports {
+
+			ip, err := strconv.Unquote(i.Path.Value)
+
+			if err != nil || !strings.HasPrefix(ip, ModulePath+"/") || copied[ip] {
+				continue
+			}
+
+			copied[ip] = true
+
+			rel := filepath.FromSlash(strings.TrimPrefix(ip, ModulePath+"/"))
+
+			files, err := copyPackage(filepath.Join(root, rel), filepath.Join(dir, rel))
+
+			if err != nil {
+				return err
+			}
+
+			sources = append(sources, files...)
+		}
+	}
+
+	return nil
+}
+
+// copyPackage copies the Go source files (but not the tests) in one directory to another, returning the paths of the
+// copies
+func copyPackage(from, to string) ([]string, error) {
+
+	sources, err := filepath.Glob(filepath.Join(from, "*.go"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return nil, err
+	}
+
+	var copies []string
+
+	for _, s := range sources {
+
+		if strings.HasSuffix(s, "_test.go") {
+			continue
+		}
+
+		b, err := os.ReadFile(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c := filepath.Join(to, filepath.Base(s))
+
+		if err := os.WriteFile(c, b, 0644); err != nil {
+			return nil, err
+		}
+
+		copies = append(copies, c)
+	}
+
+	return copies, nil
+}
@@ -0,0 +1,78 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// The repository root, relative to this package
+const root = ".."
+
+func TestCopy(t *testing.T) {
+
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.go")
+
+	program := `package main
+
+import (
+	"fmt"
+	"github.com/benhalstead/gotraining/fetch"
+)
+
+func main() {
+	fmt.Println(fetch.Fetcher{})
+}
+`
+
+	if err := os.WriteFile(main, []byte(program), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Copy(root, dir, main); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	var copied []string
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			copied = append(copied, e.Name())
+		}
+	}
+
+	sort.Strings(copied)
+
+	// fetch imports limit and metrics, and limit imports clock, so they are copied too
+	if expected := []string{"clock", "fetch", "limit", "metrics"}; !reflect.DeepEqual(copied, expected) {
+		t.Errorf("Expected %v to be copied, got %v", expected, copied)
+	}
+
+	if tests, err := filepath.Glob(filepath.Join(dir, "*", "*_test.go")); err != nil || len(tests) != 0 {
+		t.Errorf("Expected tests not to be copied, got %v", tests)
+	}
+}
+
+func TestCopyMissingPackage(t *testing.T) {
+
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.go")
+
+	if err := os.WriteFile(main, []byte("package main\n\nimport \"github.com/benhalstead/gotraining/nothing\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The build will report that the package doesn't exist
+	if err := Copy(root, dir, main); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -8,6 +8,16 @@ import (
 
 func main() {
 
+	tutorial.Register("errorhandling", "errors").
+		Section("static", staticMessageExample).
+		Section("templated", templatedMessageExample).
+		Section("functions", errorsFromFunctionsExample).
+		Section("custom", customErrorTypesExample).
+		Run()
+}
+
+func staticMessageExample() {
+
 	// Go explicitly rejects the try/catch/throw pattern for exceptions in other languages
 	// https://golang.org/doc/faq#exceptions
 
@@ -26,15 +36,21 @@ func main() {
 	e = errors.New("Simple message")
 
 	fmt.Printf("Type: %T Error: %s\n", e, e.Error())
+}
+
+func templatedMessageExample() {
 
 	tutorial.Section("Generic error with templated message")
 
 	// The package fmt provides a function for creating a generic error using the same templating pattern as fmt.Printf
 
 	ec := 404
-	e = fmt.Errorf("HTTP error: %d", ec)
+	e := fmt.Errorf("HTTP error: %d", ec)
 
 	fmt.Printf("Type: %T Error: %s\n", e, e.Error())
+}
+
+func errorsFromFunctionsExample() {
 
 	tutorial.Section("Errors from functions")
 
@@ -60,6 +76,9 @@ func main() {
 	}
 
 	fmt.Printf("Value is %d\n", v)
+}
+
+func customErrorTypesExample() {
 
 	tutorial.Section("Custom error types")
 
@@ -67,7 +86,7 @@ func main() {
 	// custom error types which may contain more detailed information. The method signature will generally
 	// return the error interface, but the GoDoc will advertise which concrete types might be returned as errors
 
-	err = connectLocal()
+	err := connectLocal()
 
 	fmt.Println(err.Error())
 
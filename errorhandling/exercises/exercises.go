@@ -0,0 +1,38 @@
+// Package exercises contains exercises for the error handling lessons (errorhandling/*.go). Replace the body of each
+// function so that it does what its comment describes, then check your answers with:
+//
+//	gotraining grade errorhandling
+package exercises
+
+// RangeError records a value that was outside the range it was supposed to be in
+type RangeError struct {
+	Value int
+	Min   int
+	Max   int
+}
+
+// Error makes RangeError an error. It should return a message of the form "<value> is not between <min> and <max>"
+func (re RangeError) Error() string {
+	// TODO
+	return ""
+}
+
+// CheckAge returns nil if age is between 0 and 150 (inclusive), otherwise a RangeError
+func CheckAge(age int) error {
+	// TODO
+	return nil
+}
+
+// ParseAge converts s to an int and checks it with CheckAge. If s isn't a number, the error from strconv.Atoi is
+// returned.
+func ParseAge(s string) (int, error) {
+	// TODO
+	return 0, nil
+}
+
+// SafeDivide returns a divided by b. If the division panics (because b is zero), it recovers and returns an error
+// instead.
+func SafeDivide(a, b int) (q int, err error) {
+	// TODO
+	return 0, nil
+}
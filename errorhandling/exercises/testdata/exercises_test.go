@@ -0,0 +1,62 @@
+package exercises
+
+import (
+	"strconv"
+	"testing"
+)
+
+// Hint: fmt.Sprintf builds the message. Make sure you pass an argument for every verb (errors.go has a bug like this).
+func TestRangeError(t *testing.T) {
+
+	var err error = RangeError{Value: 200, Min: 0, Max: 150}
+
+	if err.Error() != "200 is not between 0 and 150" {
+		t.Errorf("Expected \"200 is not between 0 and 150\" but found %q", err.Error())
+	}
+}
+
+// Hint: return RangeError{...} as the error and nil when everything is fine.
+func TestCheckAge(t *testing.T) {
+
+	if err := CheckAge(30); err != nil {
+		t.Errorf("Expected nil for 30 but found %v", err)
+	}
+
+	err := CheckAge(-1)
+
+	if re, okay := err.(RangeError); !okay || re.Value != -1 {
+		t.Errorf("Expected a RangeError for -1 but found %#v", err)
+	}
+}
+
+// Hint: check the error from strconv.Atoi before checking the range, and return it unchanged.
+func TestParseAge(t *testing.T) {
+
+	if a, err := ParseAge("42"); a != 42 || err != nil {
+		t.Errorf("Expected 42, nil but found %d, %v", a, err)
+	}
+
+	if _, err := ParseAge("old"); err == nil {
+		t.Errorf("Expected an error for \"old\"")
+	} else if _, okay := err.(*strconv.NumError); !okay {
+		t.Errorf("Expected a *strconv.NumError for \"old\" but found %T", err)
+	}
+
+	if _, err := ParseAge("151"); err == nil {
+		t.Errorf("Expected an error for 151")
+	} else if _, okay := err.(RangeError); !okay {
+		t.Errorf("Expected a RangeError for 151 but found %T", err)
+	}
+}
+
+// Hint: defer a function that calls recover and sets the named return value err. See panic.go.
+func TestSafeDivide(t *testing.T) {
+
+	if q, err := SafeDivide(9, 3); q != 3 || err != nil {
+		t.Errorf("Expected 3, nil but found %d, %v", q, err)
+	}
+
+	if _, err := SafeDivide(1, 0); err == nil {
+		t.Errorf("Expected an error when dividing by zero")
+	}
+}
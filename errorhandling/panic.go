@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/benhalstead/gotraining/tutorial"
 )
 
 func main() {
@@ -29,8 +30,9 @@ func main() {
 
 	// The function below illustrates how to raise a panic and recover from it using a defer
 
-	recoverable()
-
+	tutorial.Register("errorhandling", "panic").
+		Section("recover", recoverable).
+		Run()
 }
 
 func recoverable() {
@@ -31,8 +31,10 @@ func main() {
 		https://golang.org/pkg/bufio
 	*/
 
-	exampleWritingAFile()
-	exampleReadingFiles()
+	tutorial.Register("essential", "bufio").
+		Section("writing", exampleWritingAFile).
+		Section("reading", exampleReadingFiles).
+		Run()
 }
 
 // We have hard-coded a UNIX style file path
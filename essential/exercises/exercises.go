@@ -0,0 +1,47 @@
+// Package exercises contains exercises for the essential packages lessons (essential/*.go). Replace the body of each
+// function so that it does what its comment describes, then check your answers with:
+//
+//	gotraining grade essential
+package exercises
+
+import (
+	"io"
+	"time"
+)
+
+// ParsePrice splits a price like "12.99" into its pounds and pence. It returns an error if s is not a number with
+// exactly two decimal places.
+func ParsePrice(s string) (pounds, pence int, err error) {
+	// TODO
+	return 0, 0, nil
+}
+
+// Person is encoded as JSON by ToJSON. Add tags so that the fields are called "name" and "age" in the JSON.
+type Person struct {
+	Name string
+	Age  int
+}
+
+// ToJSON returns the JSON encoding of p
+func ToJSON(p Person) (string, error) {
+	// TODO
+	return "", nil
+}
+
+// CountLines returns the number of lines that can be read from r
+func CountLines(r io.Reader) (int, error) {
+	// TODO
+	return 0, nil
+}
+
+// ParseDate parses a date written like "30/04/2019" (day, month, year)
+func ParseDate(s string) (time.Time, error) {
+	// TODO
+	return time.Time{}, nil
+}
+
+// Initials returns the first letter of each word in s, in upper case. Initials("ben james halstead") returns "BJH".
+func Initials(s string) string {
+	// TODO
+	return ""
+}
@@ -0,0 +1,71 @@
+package exercises
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Hint: a regular expression with two capture groups and strconv.Atoi will do this. See regexp.go.
+func TestParsePrice(t *testing.T) {
+
+	if p, c, err := ParsePrice("12.99"); p != 12 || c != 99 || err != nil {
+		t.Errorf("Expected 12, 99, nil but found %d, %d, %v", p, c, err)
+	}
+
+	for _, bad := range []string{"12.9", "twelve", "12.999", "1299"} {
+		if _, _, err := ParsePrice(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+// Hint: tags look like `json:"name"` - note the double quotes inside the back ticks. See json.go (and spot its bug).
+func TestToJSON(t *testing.T) {
+
+	j, err := ToJSON(Person{Name: "Ada", Age: 36})
+
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err.Error())
+	}
+
+	if j != `{"name":"Ada","age":36}` {
+		t.Errorf("Expected {\"name\":\"Ada\",\"age\":36} but found %s", j)
+	}
+}
+
+// Hint: wrap r in a bufio.Scanner and count the calls to Scan that return true. See bufio.go.
+func TestCountLines(t *testing.T) {
+
+	n, err := CountLines(strings.NewReader("one\ntwo\nthree\n"))
+
+	if n != 3 || err != nil {
+		t.Errorf("Expected 3, nil but found %d, %v", n, err)
+	}
+}
+
+// Hint: the layout is the reference date Jan 2 15:04:05 2006 written the way you want to parse. See time.go.
+func TestParseDate(t *testing.T) {
+
+	d, err := ParseDate("30/04/2019")
+
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err.Error())
+	}
+
+	if d.Year() != 2019 || d.Month() != time.April || d.Day() != 30 {
+		t.Errorf("Expected 30 April 2019 but found %v", d)
+	}
+
+	if _, err := ParseDate("2019-04-30"); err == nil {
+		t.Errorf("Expected an error for a date in the wrong format")
+	}
+}
+
+// Hint: strings.Fields splits s into words and strings.ToUpper changes case. See strings.go.
+func TestInitials(t *testing.T) {
+
+	if i := Initials("ben james halstead"); i != "BJH" {
+		t.Errorf("Expected \"BJH\" but found %q", i)
+	}
+}
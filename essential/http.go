@@ -2,8 +2,10 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/benhalstead/gotraining/limit"
 	"github.com/benhalstead/gotraining/tutorial"
 	"net/http"
 )
@@ -13,12 +15,18 @@ func main() {
 	// Go's HTTP client library is similar to most modern languages. This file gives an example of a simple GET and POST
 
 	// https://golang.org/pkg/http
-	basicGet()
-	basicPost()
-	requestWithMoreControl()
-
+	tutorial.Register("essential", "http").
+		Section("get", basicGet).
+		Section("post", basicPost).
+		Section("request", requestWithMoreControl).
+		Run()
 }
 
+// jsontest.com is a free service, so these examples wait their turn to make at most one request a second to it (see
+// the limits section of the goroutines lesson). The limit only holds within one run: each run starts with a full
+// bucket, so running the lesson over and over can still go faster than that.
+var jsonTest = limit.NewLimiter(1, 1, nil)
+
 func basicGet() {
 
 	tutorial.Section("Basic GET")
@@ -26,6 +34,11 @@ func basicGet() {
 	// If you need to make a simple GET request and don't need any control over headers or cookies, there is a simple helper
 	// method
 
+	if err := jsonTest.Wait(context.Background()); err != nil {
+		fmt.Printf("Gave up waiting to make the request: %s\n", err.Error())
+		return
+	}
+
 	if res, err := http.Get("http://ip.jsontest.com/"); err != nil {
 
 		fmt.Printf("Error type: %T Message: %s\n", err, err.Error())
@@ -70,6 +83,11 @@ func basicPost() {
 	//Wrap in a Reader
 	r := bytes.NewReader(j)
 
+	if err := jsonTest.Wait(context.Background()); err != nil {
+		fmt.Printf("Gave up waiting to make the request: %s\n", err.Error())
+		return
+	}
+
 	if res, err := http.Post("http://validate.jsontest.com/", "application/json", r); err != nil {
 		fmt.Printf("Error type: %T Message: %s\n", err, err.Error())
 	} else {
@@ -95,6 +113,11 @@ func requestWithMoreControl() {
 	// Once you have a request object, you can set headers
 	req.Header.Add("A", "B")
 
+	if err := jsonTest.Wait(context.Background()); err != nil {
+		fmt.Printf("Gave up waiting to make the request: %s\n", err.Error())
+		return
+	}
+
 	if res, err := client.Do(req); err != nil {
 		fmt.Printf("Error type: %T Message: %s\n", err, err.Error())
 	} else {
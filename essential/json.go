@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/benhalstead/gotraining/jsonschema"
 	"github.com/benhalstead/gotraining/tutorial"
 	"strings"
 )
@@ -26,12 +27,15 @@ func main() {
 
 	*/
 
-	unmarshallIntoStructFromReader()
-	unmarshallIntoMapFromReader()
-	unmarshallFromString()
-	marshallFromStructToBytes()
-	marhsallFromStructToWriter()
-	marhsallToPrettyPrintedString()
+	tutorial.Register("essential", "json").
+		Section("unmarshall-struct", unmarshallIntoStructFromReader).
+		Section("unmarshall-map", unmarshallIntoMapFromReader).
+		Section("unmarshall-string", func() { unmarshallFromString() }).
+		Section("marshall-bytes", marshallFromStructToBytes).
+		Section("marshall-writer", marhsallFromStructToWriter).
+		Section("pretty-print", marhsallToPrettyPrintedString).
+		Section("schema", schemaExample).
+		Run()
 }
 
 var simpleJSON = `
@@ -193,3 +197,82 @@ func marhsallToPrettyPrintedString() {
 	}
 
 }
+
+func schemaExample() {
+	tutorial.Section("Schemas and validation")
+
+	// Decoding into a struct is forgiving: fields in the JSON that the struct doesn't have are ignored, and Decode stops
+	// at the first value with the wrong type, leaving the fields after it at their zero values. json.Decoder's
+	// DisallowUnknownFields method helps with the first problem, but still only reports one of them.
+	//
+	// A JSON Schema (https://json-schema.org) describes what a JSON document may contain. The jsonschema package
+	// generates one from a struct's fields and json tags. Target refers to itself in ObjectVal and ObjectArray, so those
+	// properties point back at the whole schema with "$ref": "#"
+	schema, err := jsonschema.For(Target{})
+
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if j, err := json.MarshalIndent(schema, "", "\t"); err != nil {
+		fmt.Println(err.Error())
+	} else {
+		fmt.Printf("%s\n", string(j))
+	}
+
+	// Look closely at the property names. They are the field names, not the names in Target's tags, because the tags
+	// aren't quoted (that's the bug mentioned in the exercises) so encoding/json, and the schema, ignore them. Decode
+	// hides the bug, as it ignores case when matching names to fields, but the schema only allows the exact names.
+	printProblems(schema, simpleJSON)
+
+	// With the tags fixed (and fewer fields, to keep this short) the schema uses the names in the tags
+	type Fixed struct {
+		NumberVal float64 `json:"numberVal"`
+		BoolVal   bool    `json:"boolVal"`
+		StringVal string  `json:"stringVal"`
+		ObjectVal *Fixed  `json:"objectVal"`
+	}
+
+	fixed, err := jsonschema.For(Fixed{})
+
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	// Validating reports every problem, each with a JSON Pointer to the value that is wrong. boolval isn't allowed,
+	// although Decode would put it in BoolVal.
+	printProblems(fixed, `{"numberVal": 54.1, "objectVal": {"numberVal": 5}}`)
+	printProblems(fixed, `{"numberVal": "54.1", "boolval": true, "objectVal": {"objectVal": {"stringVal": 2}}}`)
+
+	// jsonschema.Unmarshal validates before unmarshalling, so nothing is unmarshalled from an invalid document
+	var f Fixed
+
+	if err := jsonschema.Unmarshal([]byte(`{"numberVal": "54.1"}`), &f); err != nil {
+		fmt.Printf("Unmarshal failed: %s\n", err.Error())
+	}
+}
+
+func printProblems(schema *jsonschema.Schema, doc string) {
+
+	err := schema.Validate([]byte(doc))
+
+	if err == nil {
+		fmt.Println("Valid")
+		return
+	}
+
+	errs, okay := err.(jsonschema.Errors)
+
+	if !okay {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Printf("%d problems:\n", len(errs))
+
+	for _, e := range errs {
+		fmt.Printf("  %s: %s\n", e.Path, e.Message)
+	}
+}
@@ -14,6 +14,14 @@ func main() {
 
 	// This is a good site for developing and testing your regex https://regex-golang.appspot.com/assets/html/index.html
 
+	tutorial.Register("essential", "regexp").
+		Section("matching", basicMatchingExample).
+		Section("capture-groups", captureGroupsExample).
+		Run()
+}
+
+func basicMatchingExample() {
+
 	tutorial.Section("Basic matching")
 	// Patterns must be 'compiled' before they can be used
 
@@ -38,12 +46,15 @@ func main() {
 	// Which will panic if the regex is illegal
 
 	fmt.Printf("Match: %t\n", crx.MatchString("10!00"))
+}
+
+func captureGroupsExample() {
 
 	tutorial.Section("Capture groups")
 
 	// Like most regex libraries, Go allows you to capture portions of a match by surrounding the group your're interested in brackets
-	currencyPattern = "^(\\d*)\\.(\\d{2})$"
-	crx = regexp.MustCompile(currencyPattern)
+	currencyPattern := "^(\\d*)\\.(\\d{2})$"
+	crx := regexp.MustCompile(currencyPattern)
 
 	groups := crx.FindStringSubmatch("100.54")
 
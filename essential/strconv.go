@@ -7,6 +7,13 @@ import (
 
 func main() {
 
+	tutorial.Register("essential", "strconv").
+		Section("parsing", parsingExample).
+		Run()
+}
+
+func parsingExample() {
+
 	//The strconv package contains functions for converting from text representations of a value to typed representations
 	// https://golang.org/pkg/strconv
 
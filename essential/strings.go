@@ -8,6 +8,13 @@ import (
 
 func main() {
 
+	tutorial.Register("essential", "strings").
+		Section("manipulation", manipulationExample).
+		Run()
+}
+
+func manipulationExample() {
+
 	// The strings package provides functions for manipulating strings
 
 	// https://golang.org/pkg/strings/
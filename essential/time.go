@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/benhalstead/gotraining/tutorial"
 	"time"
 )
 
@@ -9,6 +10,14 @@ func main() {
 
 	// https://golang.org/pkg/time
 
+	tutorial.Register("essential", "time").
+		Section("parsing", parsingDatesExample).
+		Section("durations", durationsExample).
+		Run()
+}
+
+func parsingDatesExample() {
+
 	// Date parsing is peculiar in Go. Instead of using symbols to represent a date format, you need to remember a particular date and time:
 	//
 	// Jan 2 15:04:05 2006 MST
@@ -41,14 +50,20 @@ func main() {
 		fmt.Println(err.Error())
 	}
 
+}
+
+func durationsExample() {
+
 	// Intervals between two date-times are represented as a time.Duration which is an int64. The raw value is in nano seconds
 	// To convert between the nano second value and a higher order unit (ms, second, minute, hour) use the constants in the time package
 
-	start := time.Now()
+	// tutorial.Now, tutorial.Sleep and tutorial.Since behave like time.Now, time.Sleep and time.Since, but let the
+	// lessons be run without waiting (see tutorial.ClockEnvVar)
+	start := tutorial.Now()
 
-	time.Sleep(2 * time.Second)
+	tutorial.Sleep(2 * time.Second)
 
-	end := time.Since(start)
+	end := tutorial.Since(start)
 
 	fmt.Printf("Elaspsed in nano seconds %d\n", end)
 
@@ -0,0 +1,332 @@
+// Package fetch downloads a list of URLs concurrently.
+//
+// A Fetcher limits how many requests are made at once and how long each request (and the whole list) may take. Every
+// URL produces exactly one Result, which records the response's status, how many bytes were read and how long it took,
+// or the error that stopped it. Results are delivered as they complete or in the order the URLs were given.
+package fetch
+
+import (
+	"context"
+	"github.com/benhalstead/gotraining/limit"
+	"github.com/benhalstead/gotraining/metrics"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Order is the order in which a Fetcher delivers results
+type Order int
+
+const (
+	// Results are delivered as soon as each request completes
+	CompletionOrder Order = iota
+
+	// Results are delivered in the same order as the URLs they are for. A slow request holds back the results of
+	// every URL after it.
+	InputOrder
+)
+
+// A Result is the outcome of fetching one URL
+type Result struct {
+	URL string
+
+	// The position of the URL in the list passed to Fetch
+	Index int
+
+	// The response's HTTP status code, or zero if there was no response
+	Status int
+
+	// The number of bytes read from the response body
+	Bytes int64
+
+	// How long the request took, including reading the body
+	Duration time.Duration
+
+	// Why the request failed, if it did. Requests that were never started because the Fetcher ran out of time have
+	// the context's error.
+	Err error
+}
+
+// A Fetcher downloads URLs concurrently. The zero value makes one request at a time with no timeouts.
+type Fetcher struct {
+	// The client used to make requests (http.DefaultClient if nil)
+	Client *http.Client
+
+	// The maximum number of requests in progress at once (1 if less than 1)
+	Parallel int
+
+	// If set, each request waits for the Limiter to allow it before it is made. Parallel limits how many requests are
+	// in progress, the Limiter limits how often they start.
+	Limiter *limit.Limiter
+
+	// How long each request may take, including reading the body (no limit if zero)
+	RequestTimeout time.Duration
+
+	// How long fetching every URL may take (no limit if zero)
+	Timeout time.Duration
+
+	Order Order
+
+	// Called with each Result as it completes, before it is delivered. Must be safe to call concurrently.
+	Observe func(Result)
+
+	// If set, the time taken by each request is recorded in the fetch_request_duration_seconds timer and failed
+	// requests are counted by fetch_errors_total, both labelled with the host the request was for
+	Metrics *metrics.Registry
+}
+
+// Fetch starts fetching urls and returns a channel that receives a Result for each of them and is then closed. The
+// channel must be read until it is closed, unless ctx is cancelled, in which case any undelivered results are
+// discarded.
+func (f *Fetcher) Fetch(ctx context.Context, urls []string) <-chan Result {
+
+	out := make(chan Result)
+	completed := make(chan Result)
+
+	fctx, cancel := ctx, context.CancelFunc(func() {})
+
+	if f.Timeout > 0 {
+		fctx, cancel = context.WithTimeout(ctx, f.Timeout)
+	}
+
+	go f.start(fctx, urls, completed)
+
+	go func() {
+		defer cancel()
+		defer close(out)
+
+		deliver := func(r Result) bool {
+			select {
+			case out <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if f.Order == InputOrder {
+			reorder(completed, deliver)
+		} else {
+			for r := range completed {
+				if !deliver(r) {
+					break
+				}
+			}
+		}
+
+		// Let any requests still in progress finish so nothing is left blocked
+		for range completed {
+		}
+	}()
+
+	return out
+}
+
+// FetchAll fetches urls and returns their results, in the Fetcher's Order
+func (f *Fetcher) FetchAll(ctx context.Context, urls []string) []Result {
+
+	results := make([]Result, 0, len(urls))
+
+	for r := range f.Fetch(ctx, urls) {
+		results = append(results, r)
+	}
+
+	return results
+}
+
+// start makes a request for each URL, at most f.Parallel at a time, and sends each Result to completed, which is
+// closed once every URL has a Result
+func (f *Fetcher) start(ctx context.Context, urls []string, completed chan<- Result) {
+
+	parallel := f.Parallel
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	slots := make(chan bool, parallel)
+
+	var rec *recorder
+
+	if f.Metrics != nil {
+		rec = &recorder{reg: f.Metrics, hosts: make(map[string]*hostMetrics)}
+	}
+
+	var wg sync.WaitGroup
+
+	for i, u := range urls {
+
+		select {
+		case slots <- true:
+		case <-ctx.Done():
+			// Out of time, so the remaining URLs fail without a request being made
+			f.complete(Result{URL: u, Index: i, Err: ctx.Err()}, rec, completed)
+			continue
+		}
+
+		if f.Limiter != nil {
+
+			if err := f.Limiter.Wait(ctx); err != nil {
+				<-slots
+				f.complete(Result{URL: u, Index: i, Err: err}, rec, completed)
+				continue
+			}
+		}
+
+		wg.Add(1)
+
+		go func(i int, u string) {
+			defer wg.Done()
+
+			r := f.get(ctx, i, u)
+			<-slots
+
+			f.complete(r, rec, completed)
+		}(i, u)
+	}
+
+	wg.Wait()
+	close(completed)
+}
+
+func (f *Fetcher) complete(r Result, rec *recorder, completed chan<- Result) {
+
+	if rec != nil {
+		rec.record(r)
+	}
+
+	if f.Observe != nil {
+		f.Observe(r)
+	}
+
+	completed <- r
+}
+
+// A recorder adds the results of one call to Fetch to the Fetcher's metrics. Each host's metrics are looked up in the
+// registry the first time a result for that host is recorded and reused after that.
+type recorder struct {
+	reg *metrics.Registry
+
+	mu    sync.Mutex
+	hosts map[string]*hostMetrics
+}
+
+// The metrics for requests to one host
+type hostMetrics struct {
+	errors   *metrics.Counter
+	duration *metrics.Timer
+}
+
+func (rec *recorder) forHost(host string) *hostMetrics {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if m, okay := rec.hosts[host]; okay {
+		return m
+	}
+
+	labels := metrics.Labels{"host": host}
+
+	m := &hostMetrics{
+		errors:   rec.reg.Counter("fetch_errors_total", "Requests that failed", labels),
+		duration: rec.reg.Timer("fetch_request_duration_seconds", "Time taken to fetch a URL, including reading the body", labels),
+	}
+
+	rec.hosts[host] = m
+
+	return m
+}
+
+func (rec *recorder) record(r Result) {
+
+	host := "unknown"
+
+	if u, err := url.Parse(r.URL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	m := rec.forHost(host)
+
+	if r.Err != nil {
+		m.errors.Inc()
+	}
+
+	// Requests that were never made have no duration worth recording
+	if r.Duration > 0 {
+		m.duration.ObserveDuration(r.Duration)
+	}
+}
+
+// get fetches a single URL, reading (and discarding) the whole response body
+func (f *Fetcher) get(ctx context.Context, i int, u string) (r Result) {
+
+	r = Result{URL: u, Index: i}
+
+	if f.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.RequestTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	defer func() {
+		r.Duration = time.Since(start)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	client := f.Client
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	defer res.Body.Close()
+
+	r.Status = res.StatusCode
+	r.Bytes, r.Err = io.Copy(io.Discard, res.Body)
+
+	return r
+}
+
+// reorder passes results to deliver in the order of their Index, holding back any that arrive early
+func reorder(completed <-chan Result, deliver func(Result) bool) {
+
+	pending := make(map[int]Result)
+	next := 0
+
+	for r := range completed {
+
+		pending[r.Index] = r
+
+		for {
+			p, okay := pending[next]
+
+			if !okay {
+				break
+			}
+
+			delete(pending, next)
+			next++
+
+			if !deliver(p) {
+				return
+			}
+		}
+	}
+}
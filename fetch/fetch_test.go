@@ -0,0 +1,249 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"github.com/benhalstead/gotraining/limit"
+	"github.com/benhalstead/gotraining/metrics"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// server responds to /wait?d=<duration> after the duration, /missing with a 404 and everything else with a short body.
+// It records the most requests it has had in progress at once.
+type server struct {
+	*httptest.Server
+	inFlight    int32
+	maxInFlight int32
+}
+
+func newServer(t *testing.T) *server {
+
+	s := new(server)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		n := atomic.AddInt32(&s.inFlight, 1)
+		defer atomic.AddInt32(&s.inFlight, -1)
+
+		for m := atomic.LoadInt32(&s.maxInFlight); n > m && !atomic.CompareAndSwapInt32(&s.maxInFlight, m, n); m = atomic.LoadInt32(&s.maxInFlight) {
+		}
+
+		if d, err := time.ParseDuration(r.URL.Query().Get("d")); err == nil {
+			select {
+			case <-time.After(d):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte("Hello, World"))
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *server) url(path string) string {
+	return s.URL + path
+}
+
+func TestResults(t *testing.T) {
+
+	s := newServer(t)
+	f := &Fetcher{Parallel: 2, Order: InputOrder}
+
+	results := f.FetchAll(context.Background(), []string{s.url("/"), s.url("/missing"), "http://[::1]:namedport"})
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	if r := results[0]; r.Status != http.StatusOK || r.Bytes != 12 || r.Err != nil || r.Duration <= 0 {
+		t.Errorf("Unexpected result %+v", r)
+	}
+
+	if r := results[1]; r.Status != http.StatusNotFound || r.Err != nil {
+		t.Errorf("Expected a 404, got %+v", r)
+	}
+
+	if r := results[2]; r.Err == nil || r.Status != 0 {
+		t.Errorf("Expected an invalid URL to fail, got %+v", r)
+	}
+}
+
+func TestOrder(t *testing.T) {
+
+	s := newServer(t)
+	urls := []string{s.url("/?d=100ms"), s.url("/?d=50ms"), s.url("/")}
+
+	f := &Fetcher{Parallel: 3}
+
+	if got := indexes(f.FetchAll(context.Background(), urls)); got != "210" {
+		t.Errorf("Expected results in completion order, got %s", got)
+	}
+
+	f.Order = InputOrder
+
+	if got := indexes(f.FetchAll(context.Background(), urls)); got != "012" {
+		t.Errorf("Expected results in input order, got %s", got)
+	}
+}
+
+func TestParallelismIsBounded(t *testing.T) {
+
+	s := newServer(t)
+
+	var urls []string
+
+	for i := 0; i < 12; i++ {
+		urls = append(urls, s.url("/?d=20ms"))
+	}
+
+	f := &Fetcher{Parallel: 3}
+	f.FetchAll(context.Background(), urls)
+
+	if m := atomic.LoadInt32(&s.maxInFlight); m != 3 {
+		t.Errorf("Expected at most (and at some point exactly) 3 requests at once, got %d", m)
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+
+	s := newServer(t)
+
+	f := &Fetcher{Parallel: 2, RequestTimeout: 50 * time.Millisecond, Order: InputOrder}
+
+	results := f.FetchAll(context.Background(), []string{s.url("/?d=1s"), s.url("/")})
+
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("Expected the slow request to time out, got %v", results[0].Err)
+	}
+
+	if results[1].Err != nil {
+		t.Errorf("Expected the fast request to succeed, got %v", results[1].Err)
+	}
+
+	// One request at a time, so the second and third never start
+	f = &Fetcher{Parallel: 1, Timeout: 50 * time.Millisecond, Order: InputOrder}
+
+	start := time.Now()
+	results = f.FetchAll(context.Background(), []string{s.url("/?d=1s"), s.url("/"), s.url("/")})
+
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("Expected fetching to stop after the overall timeout, took %s", d)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Expected a result for every URL, got %d", len(results))
+	}
+
+	for _, r := range results {
+		if !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("Expected %s to time out, got %v", r.URL, r.Err)
+		}
+	}
+}
+
+func TestCancellingLeavesNothingRunning(t *testing.T) {
+
+	s := newServer(t)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	f := &Fetcher{Parallel: 2}
+	results := f.Fetch(ctx, []string{s.url("/"), s.url("/?d=1s"), s.url("/?d=1s"), s.url("/")})
+
+	<-results
+	cancel()
+
+	// The channel is closed without delivering the rest of the results
+	for range results {
+	}
+
+	s.CloseClientConnections()
+	http.DefaultClient.CloseIdleConnections()
+
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := runtime.NumGoroutine(); n > before {
+		buf := make([]byte, 1<<16)
+		t.Errorf("Expected %d goroutines but found %d:\n%s", before, n, buf[:runtime.Stack(buf, true)])
+	}
+}
+
+func indexes(results []Result) string {
+
+	var b strings.Builder
+
+	for _, r := range results {
+		b.WriteByte(byte('0' + r.Index))
+	}
+
+	return b.String()
+}
+
+func TestMetrics(t *testing.T) {
+
+	s := newServer(t)
+	reg := metrics.NewRegistry()
+
+	f := &Fetcher{Parallel: 2, Metrics: reg}
+	f.FetchAll(context.Background(), []string{s.url("/"), s.url("/missing"), "http://[::1]:namedport"})
+
+	host := strings.TrimPrefix(s.URL, "http://")
+	timings := uint64(0)
+
+	for _, fam := range reg.Snapshot() {
+
+		if fam.Name != "fetch_request_duration_seconds" {
+			continue
+		}
+
+		for _, m := range fam.Metrics {
+			if m.Labels["host"] == host {
+				timings = m.Histogram.Count
+			}
+		}
+	}
+
+	if timings != 2 {
+		t.Errorf("Expected 2 timings for %s, got %d", host, timings)
+	}
+
+	if c := reg.Counter("fetch_errors_total", "", metrics.Labels{"host": "unknown"}).Value(); c != 1 {
+		t.Errorf("Expected 1 error, got %d", c)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+
+	s := newServer(t)
+
+	// Two requests are allowed straight away, then no more
+	f := &Fetcher{Parallel: 3, Timeout: 50 * time.Millisecond, Order: InputOrder, Limiter: limit.NewLimiter(0, 2, nil)}
+
+	results := f.FetchAll(context.Background(), []string{s.url("/"), s.url("/"), s.url("/")})
+
+	if results[0].Err != nil || results[1].Err != nil {
+		t.Errorf("Expected the first two requests to succeed, got %+v", results[:2])
+	}
+
+	if !errors.Is(results[2].Err, context.DeadlineExceeded) || results[2].Status != 0 {
+		t.Errorf("Expected the third request to run out of time waiting, got %+v", results[2])
+	}
+}
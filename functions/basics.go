@@ -2,11 +2,15 @@ package main
 
 import (
 	"fmt"
+	"github.com/benhalstead/gotraining/tutorial"
 	"github.com/pkg/errors"
 )
 
 func main() {
 
+	tutorial.Register("functions", "basics").
+		Section("multi-return", CallingMultiReturnValueFunctions).
+		Run()
 }
 
 func privateFunction() {
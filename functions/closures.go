@@ -7,6 +7,13 @@ import (
 
 func main() {
 
+	tutorial.Register("functions", "closures").
+		Section("closures", closuresExample).
+		Run()
+}
+
+func closuresExample() {
+
 	// In Go the syntax for closures is shared with lambdas/anonymous functions
 
 	tutorial.Section("Anonymous function/lambda expression")
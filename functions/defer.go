@@ -10,34 +10,21 @@ func main() {
 	// Defer is Go's 'finally' mechanism to allow ensure resources are closed or other cleanup activity to be performed
 	// reliably when a function ends.
 
-	// Defer allows you to specify a function to execute when a function ends (but before it returns)
-	tutorial.Section("Single defer")
-	singleDefer()
-
-	// The defered function can be a closure
-	tutorial.Section("Closure defer")
-	closureDefer()
-
-	// You can have multiple defers in a function and they are executing in reverse order (i.e. there is a 'stack' of deferred functions)
-	tutorial.Section("Multi defer")
-	multiDefer()
-
-	// Be very careful using defer in loops
-	tutorial.Section("Loop defer")
-	loopDefer()
-
-	// Defer runs even if code in the function panics (and is the idiomatic way to recover from panics)
-	tutorial.Section("Loop defer")
-	panicDefer()
-
-	// Defer executes _after_ the return statement - this means that the return value can be altered by defer statments, as long as the return values have names
-	tutorial.Section("Manipulate return values")
-	fmt.Println(echo(1))
-
+	tutorial.Register("functions", "defer").
+		Section("single", singleDefer).
+		Section("closure", closureDefer).
+		Section("multi", multiDefer).
+		Section("loop", loopDefer).
+		Section("panic", panicDefer).
+		Section("return-values", returnValuesExample).
+		Run()
 }
 
 func singleDefer() {
 
+	// Defer allows you to specify a function to execute when a function ends (but before it returns)
+	tutorial.Section("Single defer")
+
 	openResource()
 	defer closeResource()
 
@@ -46,6 +33,10 @@ func singleDefer() {
 }
 
 func closureDefer() {
+
+	// The defered function can be a closure
+	tutorial.Section("Closure defer")
+
 	a := 2
 
 	defer func() {
@@ -57,6 +48,9 @@ func closureDefer() {
 
 func multiDefer() {
 
+	// You can have multiple defers in a function and they are executing in reverse order (i.e. there is a 'stack' of deferred functions)
+	tutorial.Section("Multi defer")
+
 	defer fmt.Println("First defer")
 	defer fmt.Println("Second defer")
 
@@ -64,6 +58,9 @@ func multiDefer() {
 
 func loopDefer() {
 
+	// Be very careful using defer in loops
+	tutorial.Section("Loop defer")
+
 	for i := 0; i < 3; i++ {
 		openResource()
 		defer closeResource()
@@ -73,6 +70,9 @@ func loopDefer() {
 
 func panicDefer() {
 
+	// Defer runs even if code in the function panics (and is the idiomatic way to recover from panics)
+	tutorial.Section("Panic defer")
+
 	openResource()
 
 	defer func() {
@@ -87,6 +87,14 @@ func panicDefer() {
 
 }
 
+func returnValuesExample() {
+
+	// Defer executes _after_ the return statement - this means that the return value can be altered by defer statments, as long as the return values have names
+	tutorial.Section("Manipulate return values")
+
+	fmt.Println(echo(1))
+}
+
 func openResource() {
 	fmt.Println("Open resource")
 }
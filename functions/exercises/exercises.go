@@ -0,0 +1,41 @@
+// Package exercises contains exercises for the functions lessons (functions/*.go). Replace the body of each function
+// so that it does what its comment describes, then check your answers with:
+//
+//	gotraining grade functions
+package exercises
+
+import "errors"
+
+// ErrDivideByZero is returned by Divide when asked to divide by zero
+var ErrDivideByZero = errors.New("divide by zero")
+
+// Divide returns a divided by b, or ErrDivideByZero if b is zero
+func Divide(a, b int) (int, error) {
+	// TODO
+	return 0, nil
+}
+
+// Sum returns the total of any number of ints
+func Sum(nums ...int) int {
+	// TODO
+	return 0
+}
+
+// Counter returns a function that returns 1 the first time it is called, 2 the second time and so on. Each function
+// returned by Counter keeps its own count.
+func Counter() func() int {
+	// TODO
+	return func() int { return 0 }
+}
+
+// Apply calls f on each value in nums and returns the results in a new slice
+func Apply(nums []int, f func(int) int) []int {
+	// TODO
+	return nil
+}
+
+// Protect calls f and returns nil, unless f panics, in which case it recovers and returns an error instead
+func Protect(f func()) (err error) {
+	// TODO
+	return nil
+}
@@ -0,0 +1,79 @@
+package exercises
+
+import "testing"
+
+// Hint: check b before dividing and return the error as the second return value.
+func TestDivide(t *testing.T) {
+
+	if q, err := Divide(7, 2); err != nil || q != 3 {
+		t.Errorf("Expected 3, nil but found %d, %v", q, err)
+	}
+
+	if _, err := Divide(1, 0); err != ErrDivideByZero {
+		t.Errorf("Expected ErrDivideByZero but found %v", err)
+	}
+}
+
+// Hint: inside the function nums is a []int, so you can range over it. See variadic.go.
+func TestSum(t *testing.T) {
+
+	if s := Sum(); s != 0 {
+		t.Errorf("Expected Sum() to be 0 but found %d", s)
+	}
+
+	if s := Sum(1, 2, 3); s != 6 {
+		t.Errorf("Expected Sum(1, 2, 3) to be 6 but found %d", s)
+	}
+
+	nums := []int{4, 5}
+
+	if s := Sum(nums...); s != 9 {
+		t.Errorf("Expected Sum(nums...) to be 9 but found %d", s)
+	}
+}
+
+// Hint: declare the count inside Counter and return a closure that increments it. See closures.go.
+func TestCounter(t *testing.T) {
+
+	a := Counter()
+	b := Counter()
+
+	a()
+	a()
+
+	if n := a(); n != 3 {
+		t.Errorf("Expected the third call to return 3 but found %d", n)
+	}
+
+	if n := b(); n != 1 {
+		t.Errorf("Expected a new counter to start at 1 but found %d", n)
+	}
+}
+
+// Hint: make a slice the same length as nums, or append to an empty one.
+func TestApply(t *testing.T) {
+
+	nums := []int{1, 2, 3}
+
+	result := Apply(nums, func(i int) int { return i * 10 })
+
+	if len(result) != 3 || result[0] != 10 || result[1] != 20 || result[2] != 30 {
+		t.Errorf("Expected [10 20 30] but found %v", result)
+	}
+
+	if nums[0] != 1 {
+		t.Errorf("Apply should not modify its argument")
+	}
+}
+
+// Hint: recover only works in a deferred function, and a deferred function can set a named return value. See defer.go.
+func TestProtect(t *testing.T) {
+
+	if err := Protect(func() {}); err != nil {
+		t.Errorf("Expected nil for a function that doesn't panic but found %v", err)
+	}
+
+	if err := Protect(func() { panic("oops") }); err == nil {
+		t.Errorf("Expected an error for a function that panics")
+	}
+}
@@ -54,6 +54,13 @@ func OpenResource() (interface{}, close) {
 
 func main() {
 
+	tutorial.Register("functions", "types").
+		Section("function-types", functionTypesExample).
+		Run()
+}
+
+func functionTypesExample() {
+
 	tutorial.Section("Function type variables")
 
 	// Variables can be declared with a function as their type
@@ -30,11 +30,23 @@ func main() {
 
 	// Variadic functions (varargs or vargs in other languages) allow a single named parameter on a function to accept 0, 1 or n values
 
+	tutorial.Register("functions", "variadic").
+		Section("single-type", singleTypeExample).
+		Section("pass-arrays", passArraysExample).
+		Section("interface-variadic", interfaceVariadicExample).
+		Run()
+}
+
+func singleTypeExample() {
+
 	tutorial.Section("Single type")
 
 	TypedVariadic("ZERO")
 	TypedVariadic("ONE", 1)
 	TypedVariadic("TWO", 1, 2)
+}
+
+func passArraysExample() {
 
 	// As the args are packed into a typed array, you can pass an slice or array of that type into a variadic function
 	// using the ellipsis operator AFTER the parameter
@@ -44,6 +56,9 @@ func main() {
 	TypedVariadic("ARRAY", ia...)
 
 	//This technique is also useful for recursive variadic functions
+}
+
+func interfaceVariadicExample() {
 
 	tutorial.Section("interface{} variadic")
 
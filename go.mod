@@ -0,0 +1,13 @@
+module github.com/benhalstead/gotraining
+
+go 1.24.0
+
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/tools v0.42.0
+)
+
+require (
+	golang.org/x/mod v0.33.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
+)
@@ -0,0 +1,346 @@
+// Package grader checks a learner's answers to the exercises that accompany each topic.
+//
+// Each topic directory that has exercises contains an exercises package with a stub for every exercise (for example
+// functions/exercises/exercises.go). The reference tests for those stubs are kept out of the package, in
+// <topic>/exercises/testdata, so that running 'go test' on the exercises package doesn't give the answers away.
+//
+// To grade a topic, the learner's copy of the exercises package is copied to a temporary module alongside the reference
+// tests and run with 'go test'. Everything happens offline with the local Go toolchain.
+//
+// Every reference test is one exercise. The exercise's name is the test's name without the Test prefix and any lines
+// in the test's doc comment that start with "Hint:" are shown to the learner when the exercise fails.
+package grader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/benhalstead/gotraining/deps"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The directory inside a topic that holds its exercises
+const exercisesDir = "exercises"
+
+// How long a single exercise's test may run before it is stopped (e.g. because of a deadlock)
+const testTimeout = time.Minute
+
+// An Exercise is a single reference test
+type Exercise struct {
+	Topic string
+	Name  string
+	Test  string
+	Hint  string
+}
+
+// A Result records whether a learner's answer to an exercise passed its reference test
+type Result struct {
+	Exercise
+	Passed bool
+
+	// Anything the test logged, or the compiler's output if the learner's code didn't build
+	Output string
+}
+
+// Topics returns the name of every topic in the repository that has exercises, in alphabetical order
+func Topics(root string) ([]string, error) {
+
+	matches, err := filepath.Glob(filepath.Join(root, "*", exercisesDir, "testdata", "*_test.go"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var topics []string
+	seen := make(map[string]bool)
+
+	for _, m := range matches {
+
+		t := filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(m))))
+
+		if !seen[t] {
+			seen[t] = true
+			topics = append(topics, t)
+		}
+	}
+
+	sort.Strings(topics)
+
+	return topics, nil
+}
+
+// Exercises reads the reference tests for a topic and returns an Exercise for each of them, in the order they are
+// declared
+func Exercises(root, topic string) ([]Exercise, error) {
+
+	files, err := referenceTests(root, topic)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var exercises []Exercise
+
+	fs := token.NewFileSet()
+
+	for _, f := range files {
+
+		parsed, err := parser.ParseFile(fs, f, nil, parser.ParseComments)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range parsed.Decls {
+
+			fd, okay := d.(*ast.FuncDecl)
+
+			if !okay || fd.Recv != nil || !strings.HasPrefix(fd.Name.Name, "Test") {
+				continue
+			}
+
+			exercises = append(exercises, Exercise{
+				Topic: topic,
+				Name:  strings.TrimPrefix(fd.Name.Name, "Test"),
+				Test:  fd.Name.Name,
+				Hint:  hint(fd.Doc),
+			})
+		}
+	}
+
+	return exercises, nil
+}
+
+func referenceTests(root, topic string) ([]string, error) {
+
+	files, err := filepath.Glob(filepath.Join(root, topic, exercisesDir, "testdata", "*_test.go"))
+
+	if err == nil && len(files) == 0 {
+		err = fmt.Errorf("%s has no exercises", topic)
+	}
+
+	return files, err
+}
+
+// hint joins together the lines in a doc comment that start with "Hint:"
+func hint(doc *ast.CommentGroup) string {
+
+	if doc == nil {
+		return ""
+	}
+
+	var hints []string
+
+	for _, l := range strings.Split(doc.Text(), "\n") {
+		if strings.HasPrefix(l, "Hint:") {
+			hints = append(hints, strings.TrimSpace(strings.TrimPrefix(l, "Hint:")))
+		}
+	}
+
+	return strings.Join(hints, " ")
+}
+
+// Grade runs the reference tests for a topic against the learner's copy of its exercises. root is the directory
+// containing this repository and workspace is the directory containing the learner's work, laid out in the same way
+// (so the answers for the functions topic are in <workspace>/functions/exercises). Learners who edit the stubs in
+// place can use root as their workspace.
+func Grade(root, workspace, topic string) ([]Result, error) {
+
+	exercises, err := Exercises(root, topic)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tests, _ := referenceTests(root, topic)
+
+	dir, err := os.MkdirTemp("", "gotraining-grade-")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(dir)
+
+	if err := prepare(root, dir, filepath.Join(workspace, topic, exercisesDir), tests); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(exercises))
+
+	for i, e := range exercises {
+
+		results[i].Exercise = e
+
+		// Each test is run on its own so that a learner's code panicking in one exercise doesn't stop the rest running
+		outcomes, buildOutput := runTest(dir, e.Test)
+
+		if o, okay := outcomes[e.Test]; okay {
+			results[i].Passed = o.passed
+			results[i].Output = o.output
+		} else {
+			// The test never ran, most likely because the learner's code doesn't compile
+			results[i].Output = buildOutput
+		}
+	}
+
+	return results, nil
+}
+
+// prepare creates a module in dir containing the learner's code, the reference tests and a copy of each package in
+// this repository that they import
+func prepare(root, dir, answers string, tests []string) error {
+
+	if err := deps.WriteModule(dir); err != nil {
+		return err
+	}
+
+	sources, err := filepath.Glob(filepath.Join(answers, "*.go"))
+
+	if err != nil {
+		return err
+	}
+
+	if len(sources) == 0 {
+		return fmt.Errorf("there are no answers in %s", answers)
+	}
+
+	var copies []string
+
+	for _, s := range sources {
+
+		// Any tests the learner has written themselves are ignored
+		if strings.HasSuffix(s, "_test.go") {
+			continue
+		}
+
+		if err := copyFile(s, dir); err != nil {
+			return err
+		}
+
+		copies = append(copies, filepath.Join(dir, filepath.Base(s)))
+	}
+
+	for _, t := range tests {
+
+		if err := copyFile(t, dir); err != nil {
+			return err
+		}
+
+		copies = append(copies, filepath.Join(dir, filepath.Base(t)))
+	}
+
+	return deps.Copy(root, dir, copies...)
+}
+
+func copyFile(src, dir string) error {
+
+	b, err := os.ReadFile(src)
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, filepath.Base(src)), b, 0644)
+}
+
+type outcome struct {
+	passed bool
+	output string
+}
+
+// A line of output from 'go test -json' (see 'go doc test2json')
+type testEvent struct {
+	Action string
+	Test   string
+	Output string
+}
+
+// runTest runs a single test with 'go test' in dir and returns its outcome along with any output that didn't belong to
+// a test (which will include compiler errors)
+func runTest(dir, test string) (map[string]*outcome, string) {
+
+	cmd := exec.Command("go", "test", "-json", "-count=1", "-timeout", testTimeout.String(), "-run", "^"+test+"$", ".")
+	cmd.Dir = dir
+
+	// Make sure nothing is downloaded and nothing outside the temporary module affects the build
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off", "GO111MODULE=on", "GOTOOLCHAIN=local")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// A non-zero exit code just means some tests failed, which is reported below
+	cmd.Run()
+
+	outcomes := make(map[string]*outcome)
+	var other strings.Builder
+
+	s := bufio.NewScanner(&stdout)
+
+	for s.Scan() {
+
+		var e testEvent
+
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			other.WriteString(s.Text() + "\n")
+			continue
+		}
+
+		name := e.Test
+
+		if i := strings.Index(name, "/"); i >= 0 {
+			// Output from subtests is reported against the test that contains them
+			name = name[:i]
+		}
+
+		if name == "" {
+			if e.Action == "output" || e.Action == "build-output" {
+				other.WriteString(e.Output)
+			}
+
+			continue
+		}
+
+		o := outcomes[name]
+
+		if o == nil {
+			o = new(outcome)
+			outcomes[name] = o
+		}
+
+		switch {
+		case e.Action == "output" && !isBoilerplate(e.Output):
+			o.output += e.Output
+		case e.Action == "pass" && e.Test == name:
+			o.passed = true
+		}
+	}
+
+	other.WriteString(stderr.String())
+
+	return outcomes, strings.TrimSpace(other.String())
+}
+
+// isBoilerplate reports whether a line of test output is one that 'go test' prints for every test
+func isBoilerplate(line string) bool {
+
+	l := strings.TrimSpace(line)
+
+	for _, p := range []string{"=== RUN", "=== PAUSE", "=== CONT", "--- PASS", "--- FAIL", "--- SKIP"} {
+		if strings.HasPrefix(l, p) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package grader
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The fixture repository has a maths topic with two exercises and an empty topic with no reference tests. The answers
+// workspace passes one maths exercise and fails the other and the broken workspace doesn't compile.
+var (
+	root   = filepath.Join("testdata", "root")
+	maths  = "maths"
+	double = Exercise{Topic: maths, Name: "Double", Test: "TestDouble", Hint: "multiply by two. or add n to itself."}
+	negate = Exercise{Topic: maths, Name: "Negate", Test: "TestNegate"}
+)
+
+func TestTopics(t *testing.T) {
+
+	topics, err := Topics(root)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !reflect.DeepEqual(topics, []string{maths}) {
+		t.Errorf("Expected only the topic with reference tests, got %v", topics)
+	}
+}
+
+func TestExercises(t *testing.T) {
+
+	exercises, err := Exercises(root, maths)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if expected := []Exercise{double, negate}; !reflect.DeepEqual(exercises, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, exercises)
+	}
+}
+
+func TestMissingReferenceTests(t *testing.T) {
+
+	if _, err := Exercises(root, "empty"); err == nil || err.Error() != "empty has no exercises" {
+		t.Errorf("Expected an error for a topic with no reference tests, got %v", err)
+	}
+
+	if _, err := Grade(root, root, "empty"); err == nil {
+		t.Errorf("Expected an error grading a topic with no reference tests")
+	}
+}
+
+func TestMissingAnswers(t *testing.T) {
+
+	if _, err := Grade(root, t.TempDir(), maths); err == nil || !strings.Contains(err.Error(), "there are no answers") {
+		t.Errorf("Expected an error for a workspace with no answers, got %v", err)
+	}
+}
+
+func TestGrade(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("grading runs 'go test'")
+	}
+
+	tests := []struct {
+		workspace string
+		passed    []bool
+		output    []string
+	}{
+		// The stubs haven't been changed, so nothing passes
+		{root, []bool{false, false}, []string{"Expected 6 but found 0", "Expected -3 but found 0"}},
+		{filepath.Join("testdata", "answers"), []bool{true, false}, []string{"", "Expected -3 but found 3"}},
+		{filepath.Join("testdata", "broken"), []bool{false, false}, []string{"undefined: two", "undefined: two"}},
+	}
+
+	for _, test := range tests {
+
+		results, err := Grade(root, test.workspace, maths)
+
+		if err != nil {
+			t.Fatalf("Unexpected error grading %s: %v", test.workspace, err)
+		}
+
+		if len(results) != 2 || results[0].Exercise != double || results[1].Exercise != negate {
+			t.Fatalf("Expected a result for each exercise in order, got %+v", results)
+		}
+
+		for i, r := range results {
+
+			if r.Passed != test.passed[i] {
+				t.Errorf("Expected %s in %s to have passed=%t", r.Name, test.workspace, test.passed[i])
+			}
+
+			if !strings.Contains(r.Output, test.output[i]) || (test.output[i] == "" && r.Output != "") {
+				t.Errorf("Expected the output of %s in %s to contain %q, got %q", r.Name, test.workspace, test.output[i], r.Output)
+			}
+		}
+	}
+}
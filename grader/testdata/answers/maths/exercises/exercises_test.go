@@ -0,0 +1,8 @@
+package exercises
+
+import "testing"
+
+// A test the learner wrote themselves, which the grader ignores
+func TestMine(t *testing.T) {
+	t.Fatal("the learner's own tests shouldn't be run")
+}
@@ -0,0 +1,12 @@
+// Package exercises is a small topic for testing the grader
+package exercises
+
+// Double returns twice n
+func Double(n int) int {
+	return n * two
+}
+
+// Negate returns -n
+func Negate(n int) int {
+	return -n
+}
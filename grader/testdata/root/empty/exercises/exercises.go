@@ -0,0 +1,8 @@
+// Package exercises is a topic whose reference tests haven't been written
+package exercises
+
+// Triple returns three times n
+func Triple(n int) int {
+	// TODO
+	return 0
+}
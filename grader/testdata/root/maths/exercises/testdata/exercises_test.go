@@ -0,0 +1,19 @@
+package exercises
+
+import "testing"
+
+// Hint: multiply by two.
+// Hint: or add n to itself.
+func TestDouble(t *testing.T) {
+
+	if d := Double(3); d != 6 {
+		t.Errorf("Expected 6 but found %d", d)
+	}
+}
+
+func TestNegate(t *testing.T) {
+
+	if n := Negate(3); n != -3 {
+		t.Errorf("Expected -3 but found %d", n)
+	}
+}
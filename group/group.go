@@ -0,0 +1,223 @@
+// Package group runs a set of functions in their own goroutines and waits for them all to finish, collecting the
+// errors they return.
+//
+// Every function is given the group's context, which is cancelled as soon as one of them fails, so the others can
+// stop early rather than finishing work whose result won't be used. A function that panics doesn't crash the program:
+// the panic comes back from Wait as a *PanicError, with the stack trace of the goroutine that panicked.
+//
+//	g, ctx := group.New(context.Background())
+//	g.SetLimit(4)
+//
+//	for _, u := range urls {
+//		u := u
+//
+//		g.Go(func(ctx context.Context) error {
+//			return download(ctx, u)
+//		})
+//	}
+//
+//	if err := g.Wait(); err != nil {
+//		// The first download that failed
+//	}
+package group
+
+import (
+	"context"
+	"fmt"
+	"github.com/benhalstead/gotraining/limit"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// A Group is a set of goroutines working on parts of the same task
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+
+	// Limits how many functions run at once, if SetLimit has been called
+	sem *limit.Semaphore
+}
+
+// New creates a Group and the context its functions are given, which is cancelled when a function fails, when Wait
+// returns or when parent is cancelled
+func New(parent context.Context) (*Group, context.Context) {
+
+	ctx, cancel := context.WithCancel(parent)
+
+	return &Group{ctx: ctx, cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of functions running at once to n, or removes the limit if n is zero or less. The limit
+// can be changed while functions are running: lowering it doesn't stop any of them, but no more start until fewer
+// than n are running.
+func (g *Group) SetLimit(n int) {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case n <= 0:
+		g.sem = nil
+	case g.sem == nil:
+		g.sem = limit.NewSemaphore(int64(n))
+	default:
+		g.sem.SetSize(int64(n))
+	}
+}
+
+func (g *Group) semaphore() *limit.Semaphore {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.sem
+}
+
+// Go runs fn in a new goroutine. If the group has a limit and it has been reached, Go waits until another function
+// finishes.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+
+	sem := g.semaphore()
+
+	if sem != nil {
+		// Can't fail, as the context is never cancelled
+		sem.Wait(context.Background(), 1)
+	}
+
+	g.start(sem, fn)
+}
+
+// TryGo runs fn in a new goroutine if the group's limit hasn't been reached, and reports whether it did
+func (g *Group) TryGo(fn func(ctx context.Context) error) bool {
+
+	sem := g.semaphore()
+
+	if sem != nil && !sem.TryAcquire(1) {
+		return false
+	}
+
+	g.start(sem, fn)
+
+	return true
+}
+
+func (g *Group) start(sem *limit.Semaphore, fn func(ctx context.Context) error) {
+
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		if sem != nil {
+			defer sem.Release(1)
+		}
+
+		if err := call(g.ctx, fn); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// call calls fn, turning a panic into a *PanicError
+func call(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// fail records an error and cancels the group's context
+func (g *Group) fail(err error) {
+
+	g.mu.Lock()
+	g.errs = append(g.errs, err)
+	g.mu.Unlock()
+
+	g.cancel()
+}
+
+// Wait waits for every function to finish and returns the first error any of them returned (or nil). The group's
+// context is cancelled once Wait returns.
+func (g *Group) Wait() error {
+
+	g.wg.Wait()
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 {
+		return nil
+	}
+
+	return g.errs[0]
+}
+
+// WaitAll waits for every function to finish and returns every error they returned as Errors (or nil if there were
+// none). Functions that stopped because another function failed usually return the context's error, which is
+// included.
+func (g *Group) WaitAll() error {
+
+	g.wg.Wait()
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 {
+		return nil
+	}
+
+	return append(Errors(nil), g.errs...)
+}
+
+// Errors is a list of errors, in the order they were returned
+type Errors []error
+
+func (e Errors) Error() string {
+
+	messages := make([]string, len(e))
+
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d errors: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Unwrap lets errors.Is and errors.As look at every error in the list
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// A PanicError is a panic in one of a group's functions
+type PanicError struct {
+	// The value passed to panic
+	Value interface{}
+
+	// The stack trace of the goroutine that panicked
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", p.Value, p.Stack)
+}
+
+// Unwrap returns the value passed to panic, if it was an error
+func (p *PanicError) Unwrap() error {
+
+	if err, okay := p.Value.(error); okay {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"github.com/benhalstead/gotraining/leak"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWaitWithoutErrors(t *testing.T) {
+	leak.VerifyNone(t)
+
+	g, ctx := New(context.Background())
+
+	var mu sync.Mutex
+	sum := 0
+
+	for i := 1; i <= 10; i++ {
+
+		i := i
+
+		g.Go(func(ctx context.Context) error {
+			mu.Lock()
+			sum += i
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if sum != 55 {
+		t.Errorf("Expected every function to run, sum is %d", sum)
+	}
+
+	if ctx.Err() == nil {
+		t.Errorf("Expected the context to be cancelled once Wait returns")
+	}
+}
+
+func TestFirstErrorCancels(t *testing.T) {
+	leak.VerifyNone(t)
+
+	g, _ := New(context.Background())
+	failure := errors.New("failed")
+
+	for i := 0; i < 3; i++ {
+		g.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+
+	g.Go(func(ctx context.Context) error {
+		return failure
+	})
+
+	if err := g.Wait(); err != failure {
+		t.Fatalf("Expected the first error, got %v", err)
+	}
+}
+
+func TestWaitAll(t *testing.T) {
+	leak.VerifyNone(t)
+
+	g, _ := New(context.Background())
+	failure := errors.New("failed")
+
+	g.Go(func(ctx context.Context) error {
+		return failure
+	})
+
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := g.WaitAll()
+
+	var all Errors
+
+	if !errors.As(err, &all) || len(all) != 2 || all[0] != failure || all[1] != context.Canceled {
+		t.Fatalf("Expected both errors, the failure first, got %v", err)
+	}
+
+	if !errors.Is(err, failure) || !strings.HasPrefix(err.Error(), "2 errors: failed; ") {
+		t.Errorf("Unexpected error %q", err.Error())
+	}
+}
+
+func TestPanics(t *testing.T) {
+	leak.VerifyNone(t)
+
+	g, _ := New(context.Background())
+
+	g.Go(func(ctx context.Context) error {
+		explode()
+		return nil
+	})
+
+	err := g.Wait()
+
+	var p *PanicError
+
+	if !errors.As(err, &p) {
+		t.Fatalf("Expected a PanicError, got %v", err)
+	}
+
+	if p.Value != "boom" || !strings.Contains(string(p.Stack), "group.explode") {
+		t.Errorf("Expected the panic's value and the stack of the goroutine that panicked, got %v", err)
+	}
+
+	// Panicking with an error lets it be found with errors.Is
+	g, _ = New(context.Background())
+
+	g.Go(func(ctx context.Context) error {
+		panic(context.DeadlineExceeded)
+	})
+
+	if err := g.Wait(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected to find the error passed to panic, got %v", err)
+	}
+}
+
+func explode() {
+	panic("boom")
+}
+
+func TestLimit(t *testing.T) {
+	leak.VerifyNone(t)
+
+	g, _ := New(context.Background())
+	g.SetLimit(2)
+
+	release := make(chan bool)
+
+	block := func(ctx context.Context) error {
+		<-release
+		return nil
+	}
+
+	if !g.TryGo(block) || !g.TryGo(block) {
+		t.Fatalf("Expected room for two functions")
+	}
+
+	if g.TryGo(block) {
+		t.Fatalf("Expected the limit to be reached")
+	}
+
+	// Go waits for room
+	started := make(chan bool)
+
+	go func() {
+		g.Go(block)
+		started <- true
+	}()
+
+	select {
+	case <-started:
+		t.Fatalf("Expected Go to wait for room")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release <- true
+	<-started
+
+	// Raising the limit makes room straight away
+	g.SetLimit(3)
+
+	if !g.TryGo(block) {
+		t.Errorf("Expected room after raising the limit")
+	}
+
+	close(release)
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+// Package interleave records the order in which goroutines access shared data, and can force them to take turns in a
+// particular order, so races that only happen occasionally can be reproduced (and looked at) on demand.
+//
+// Goroutines are started with a Recorder's Go method and access shared data through instrumented types (Slice and
+// Mutex), each of which splits an operation into the steps that matter for a race: appending to a Slice reads the
+// slice and then writes a new one. Every step gets a logical timestamp (1, 2, 3...) and is recorded as an Event.
+//
+// A Recorder created with a schedule lets goroutines take steps in the order the schedule names them. For example,
+//
+//	r := interleave.New("odd", "even", "odd", "even")
+//
+// lets odd read the slice, then even read it, then odd write its new slice, then even write its new slice (losing
+// odd's update). The schedule repeats until every goroutine has finished, skipping goroutines that can't take a step.
+// Without a schedule, goroutines take steps in whatever order the Go scheduler runs them (but still one at a time).
+package interleave
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Op is the kind of step a goroutine took
+type Op string
+
+const (
+	Read   Op = "read"
+	Write  Op = "write"
+	Lock   Op = "lock"
+	Unlock Op = "unlock"
+
+	// A goroutine tried to lock a Mutex that another goroutine holds
+	Blocked Op = "blocked"
+)
+
+// An Event is a single step taken by a goroutine
+type Event struct {
+	// The logical time of the step, starting at 1
+	Time int
+
+	Goroutine string
+	Op        Op
+
+	// What was read or written, or who holds the lock a goroutine is blocked on
+	Detail string
+
+	// Set when a write replaced data that had changed since the goroutine read it (a lost update)
+	Lost string
+}
+
+// A Recorder records the steps taken by a set of goroutines and decides which of them may take the next step
+type Recorder struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	schedule []string
+	next     int
+
+	events []Event
+
+	// Goroutines that have been started but haven't finished
+	running map[string]bool
+
+	// Goroutines waiting for a Mutex
+	blocked map[string]bool
+
+	wg sync.WaitGroup
+}
+
+// New creates a Recorder that lets goroutines take steps in the order given by schedule (a list of goroutine names,
+// one for each step, that is repeated as many times as necessary)
+func New(schedule ...string) *Recorder {
+
+	r := &Recorder{
+		schedule: schedule,
+		running:  make(map[string]bool),
+		blocked:  make(map[string]bool),
+	}
+
+	r.cond = sync.NewCond(&r.mu)
+
+	return r
+}
+
+// A Goroutine is a goroutine started by a Recorder
+type Goroutine struct {
+	Name string
+	r    *Recorder
+}
+
+// Go starts fn in a new goroutine, which is identified by name in the schedule and in Events
+func (r *Recorder) Go(name string, fn func(g *Goroutine)) {
+
+	r.mu.Lock()
+	r.running[name] = true
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		defer func() {
+			r.mu.Lock()
+			delete(r.running, name)
+			r.cond.Broadcast()
+			r.mu.Unlock()
+		}()
+
+		fn(&Goroutine{Name: name, r: r})
+	}()
+}
+
+// Wait waits for every goroutine started with Go to finish
+func (r *Recorder) Wait() {
+	r.wg.Wait()
+}
+
+// Events returns every step taken so far, in order
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Event(nil), r.events...)
+}
+
+// Step waits for the goroutine's turn, calls fn and records the step. fn returns a description of what it did. No
+// other goroutine can take a step while fn runs.
+func (g *Goroutine) Step(op Op, fn func() string) {
+
+	r := g.r
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.awaitTurn(g.Name)
+	r.record(Event{Goroutine: g.Name, Op: op, Detail: fn()})
+}
+
+// awaitTurn waits until it is a goroutine's turn to take a step. Must be called with the lock held.
+func (r *Recorder) awaitTurn(name string) {
+
+	for !r.isTurn(name) {
+		r.cond.Wait()
+	}
+}
+
+// isTurn reports whether a goroutine may take the next step, skipping any entries in the schedule for goroutines that
+// can't take a step (because they have finished, never started or are waiting for a Mutex). Must be called with the
+// lock held.
+func (r *Recorder) isTurn(name string) bool {
+
+	for i := 0; i < len(r.schedule); i++ {
+
+		n := r.schedule[r.next]
+
+		if r.running[n] && !r.blocked[n] {
+			return n == name
+		}
+
+		r.next = (r.next + 1) % len(r.schedule)
+	}
+
+	// Nobody in the schedule can take a step
+	return true
+}
+
+// record adds an Event and passes the turn on. Must be called with the lock held.
+func (r *Recorder) record(e Event) {
+
+	e.Time = len(r.events) + 1
+	r.events = append(r.events, e)
+
+	if len(r.schedule) > 0 {
+		r.next = (r.next + 1) % len(r.schedule)
+	}
+
+	r.cond.Broadcast()
+}
+
+// A Slice is a slice of ints shared between goroutines. Appending to it is not safe unless every goroutine holds the
+// same Mutex while it appends.
+type Slice struct {
+	values []int
+
+	// How many writes there have been, used to spot lost updates
+	version int
+}
+
+// Append adds v to the end of the slice in two steps, in the same way as
+//
+//	s = append(s, v)
+//
+// It reads the slice, then writes a new slice made from what it read. If another goroutine writes in between, its
+// update is lost.
+func (s *Slice) Append(g *Goroutine, v int) {
+
+	var read []int
+	var version int
+
+	g.Step(Read, func() string {
+		read, version = s.values, s.version
+		return fmt.Sprint(read)
+	})
+
+	r := g.r
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.awaitTurn(g.Name)
+
+	e := Event{Goroutine: g.Name, Op: Write}
+
+	if s.version != version {
+		e.Lost = fmt.Sprint(s.values)
+	}
+
+	s.values = append(read[:len(read):len(read)], v)
+	s.version++
+
+	e.Detail = fmt.Sprint(s.values)
+	r.record(e)
+}
+
+// Values returns the contents of the slice
+func (s *Slice) Values() []int {
+	return append([]int(nil), s.values...)
+}
+
+// A Mutex is a lock whose steps are recorded
+type Mutex struct {
+	holder  string
+	waiting []string
+}
+
+// Lock waits until no other goroutine holds the Mutex, then takes it
+func (m *Mutex) Lock(g *Goroutine) {
+
+	r := g.r
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for {
+
+		r.awaitTurn(g.Name)
+
+		if m.holder == "" {
+			m.holder = g.Name
+			r.record(Event{Goroutine: g.Name, Op: Lock})
+			return
+		}
+
+		r.record(Event{Goroutine: g.Name, Op: Blocked, Detail: "held by " + m.holder})
+
+		// Goroutines waiting for the lock don't get a turn until it is released
+		r.blocked[g.Name] = true
+		m.waiting = append(m.waiting, g.Name)
+
+		for r.blocked[g.Name] {
+			r.cond.Wait()
+		}
+	}
+}
+
+// Unlock releases the Mutex
+func (m *Mutex) Unlock(g *Goroutine) {
+
+	r := g.r
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.awaitTurn(g.Name)
+
+	if m.holder != g.Name {
+		panic(fmt.Sprintf("%s unlocked a mutex held by %q", g.Name, m.holder))
+	}
+
+	m.holder = ""
+
+	// Goroutines waiting for the lock can take a turn again. This happens here, rather than when each of them wakes up,
+	// so the next turn doesn't depend on which goroutine the Go scheduler runs first.
+	for _, w := range m.waiting {
+		delete(r.blocked, w)
+	}
+
+	m.waiting = nil
+
+	r.record(Event{Goroutine: g.Name, Op: Unlock})
+}
@@ -0,0 +1,169 @@
+package interleave
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// appendAll appends values to s from a goroutine per name, holding mx (if it isn't nil) for each append
+func appendAll(r *Recorder, s *Slice, mx *Mutex, values map[string][]int) {
+
+	for name, vs := range values {
+
+		vs := vs
+
+		r.Go(name, func(g *Goroutine) {
+			for _, v := range vs {
+
+				if mx != nil {
+					mx.Lock(g)
+				}
+
+				s.Append(g, v)
+
+				if mx != nil {
+					mx.Unlock(g)
+				}
+			}
+		})
+	}
+
+	r.Wait()
+}
+
+func TestScheduleReproducesLostUpdate(t *testing.T) {
+
+	r := New("a", "b")
+	s := new(Slice)
+
+	appendAll(r, s, nil, map[string][]int{"a": {1, 3}, "b": {2, 4}})
+
+	if v := s.Values(); !reflect.DeepEqual(v, []int{2, 4}) {
+		t.Errorf("Expected a's values to be lost, got %v", v)
+	}
+
+	var got []string
+
+	for _, e := range r.Events() {
+		got = append(got, e.Goroutine+" "+e.describe())
+	}
+
+	want := []string{
+		"a read []",
+		"b read []",
+		"a write [1]",
+		"b write [2] (overwrites [1])",
+		"a read [2]",
+		"b read [2]",
+		"a write [2 3]",
+		"b write [2 4] (overwrites [2 3])",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestMutexPreventsLostUpdates(t *testing.T) {
+
+	for _, schedule := range [][]string{{"a", "b"}, {"b", "a", "a"}, nil} {
+
+		r := New(schedule...)
+		s := new(Slice)
+
+		appendAll(r, s, new(Mutex), map[string][]int{"a": {1, 3, 5}, "b": {2, 4, 6}})
+
+		if v := s.Values(); len(v) != 6 {
+			t.Errorf("Expected every value with schedule %v, got %v", schedule, v)
+		}
+
+		for i, e := range r.Events() {
+
+			if e.Time != i+1 {
+				t.Errorf("Expected event %d to have time %d, got %d", i, i+1, e.Time)
+			}
+
+			if e.Lost != "" {
+				t.Errorf("Expected no lost updates with schedule %v, got %+v", schedule, e)
+			}
+		}
+	}
+}
+
+func TestBlockedGoroutinesLoseTheirTurn(t *testing.T) {
+
+	r := New("a", "b")
+	mx := new(Mutex)
+
+	r.Go("a", func(g *Goroutine) {
+		mx.Lock(g)
+		g.Step(Write, func() string { return "x" })
+		mx.Unlock(g)
+	})
+
+	r.Go("b", func(g *Goroutine) {
+		mx.Lock(g)
+		mx.Unlock(g)
+	})
+
+	r.Wait()
+
+	var got []string
+
+	for _, e := range r.Events() {
+		got = append(got, e.Goroutine+" "+e.describe())
+	}
+
+	want := []string{"a lock", "b blocked held by a", "a write x", "a unlock", "b lock", "b unlock"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestTimelines(t *testing.T) {
+
+	race := New("a", "b")
+	appendAll(race, new(Slice), nil, map[string][]int{"a": {1}, "b": {2}})
+
+	locked := New("a", "b")
+	appendAll(locked, new(Slice), new(Mutex), map[string][]int{"a": {1}, "b": {2}})
+
+	runs := []Run{{Title: "Race", Recorder: race, Result: "Done"}, {Title: "Locked", Recorder: locked}}
+
+	var b strings.Builder
+
+	if err := WriteASCII(&b, runs...); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Race
+
+time  a                           b
+   1  read []
+   2                              read []
+   3  write [1]
+   4                              write [2] (overwrites [1])
+
+Lost updates: 1
+
+Done
+`
+
+	if !strings.HasPrefix(b.String(), want) {
+		t.Errorf("Expected the timeline to start\n%s\ngot\n%s", want, b.String())
+	}
+
+	b.Reset()
+
+	if err := WriteHTML(&b, runs...); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"<h2>Race</h2>", "<h2>Locked</h2>", `<td class="write lost">write [2] (overwrites [1])</td>`, "Lost updates: 1"} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("Expected the page to contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package interleave
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// A Run is a recording to be shown in a timeline
+type Run struct {
+	Title    string
+	Recorder *Recorder
+
+	// The outcome of the run, shown under its timeline (optional)
+	Result string
+}
+
+// The columns of a timeline: one for each goroutine, in the order they took their first step
+func columns(events []Event) []string {
+
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, e := range events {
+		if !seen[e.Goroutine] {
+			seen[e.Goroutine] = true
+			names = append(names, e.Goroutine)
+		}
+	}
+
+	return names
+}
+
+// describe returns the text of an Event's cell in a timeline
+func (e Event) describe() string {
+
+	s := string(e.Op)
+
+	if e.Detail != "" {
+		s += " " + e.Detail
+	}
+
+	if e.Lost != "" {
+		s += " (overwrites " + e.Lost + ")"
+	}
+
+	return s
+}
+
+// WriteASCII writes a timeline of each run as plain text, with a column for each goroutine and a row for each step
+func WriteASCII(w io.Writer, runs ...Run) error {
+
+	var b strings.Builder
+
+	for i, run := range runs {
+
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		events := run.Recorder.Events()
+		names := columns(events)
+
+		width := 12
+
+		for _, e := range events {
+			if l := len(e.describe()) + 2; l > width {
+				width = l
+			}
+		}
+
+		fmt.Fprintf(&b, "%s\n\n", run.Title)
+		fmt.Fprintf(&b, "%4s  ", "time")
+
+		for _, n := range names {
+			fmt.Fprintf(&b, "%-*s", width, n)
+		}
+
+		b.WriteString("\n")
+
+		for _, e := range events {
+
+			fmt.Fprintf(&b, "%4d  ", e.Time)
+
+			for _, n := range names {
+
+				if n == e.Goroutine {
+					fmt.Fprintf(&b, "%-*s", width, e.describe())
+					break
+				}
+
+				fmt.Fprintf(&b, "%-*s", width, "")
+			}
+
+			b.WriteString("\n")
+		}
+
+		if lost := lostUpdates(events); lost > 0 {
+			fmt.Fprintf(&b, "\nLost updates: %d\n", lost)
+		}
+
+		if run.Result != "" {
+			fmt.Fprintf(&b, "\n%s\n", run.Result)
+		}
+	}
+
+	// Trailing spaces are an artefact of the padding
+	lines := strings.Split(b.String(), "\n")
+
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n"))
+
+	return err
+}
+
+func lostUpdates(events []Event) int {
+
+	n := 0
+
+	for _, e := range events {
+		if e.Lost != "" {
+			n++
+		}
+	}
+
+	return n
+}
+
+type htmlRun struct {
+	Title   string
+	Result  string
+	Lost    int
+	Columns []string
+	Rows    []htmlRow
+}
+
+type htmlRow struct {
+	Time  int
+	Cells []htmlCell
+}
+
+type htmlCell struct {
+	Text  string
+	Class string
+}
+
+// WriteHTML writes a page showing the timeline of each run side by side
+func WriteHTML(w io.Writer, runs ...Run) error {
+
+	var page []htmlRun
+
+	for _, run := range runs {
+
+		events := run.Recorder.Events()
+
+		h := htmlRun{Title: run.Title, Result: run.Result, Lost: lostUpdates(events), Columns: columns(events)}
+
+		for _, e := range events {
+
+			row := htmlRow{Time: e.Time}
+
+			for _, n := range h.Columns {
+
+				c := htmlCell{}
+
+				if n == e.Goroutine {
+
+					c.Text = e.describe()
+					c.Class = string(e.Op)
+
+					if e.Lost != "" {
+						c.Class += " lost"
+					}
+				}
+
+				row.Cells = append(row.Cells, c)
+			}
+
+			h.Rows = append(h.Rows, row)
+		}
+
+		page = append(page, h)
+	}
+
+	return timelinePage.Execute(w, page)
+}
+
+var timelinePage = template.Must(template.New("timeline").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Interleaving</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.runs { display: flex; gap: 3em; align-items: flex-start; }
+table { border-collapse: collapse; font-family: monospace; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+td.read { background: #e8f0fe; }
+td.write { background: #e6f4ea; }
+td.lock, td.unlock { background: #f1f3f4; }
+td.blocked { background: #fef7e0; }
+td.lost { background: #fce8e6; font-weight: bold; }
+</style>
+</head>
+<body>
+<div class="runs">
+{{range .}}<section>
+<h2>{{.Title}}</h2>
+<table>
+<tr><th>time</th>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr><td>{{.Time}}</td>{{range .Cells}}<td class="{{.Class}}">{{.Text}}</td>{{end}}</tr>
+{{end}}</table>
+{{if .Lost}}<p>Lost updates: {{.Lost}}</p>{{end}}
+{{if .Result}}<p>{{.Result}}</p>{{end}}
+</section>
+{{end}}</div>
+</body>
+</html>
+`))
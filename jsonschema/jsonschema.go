@@ -0,0 +1,532 @@
+// Package jsonschema generates a JSON Schema (https://json-schema.org, draft 2020-12) from a Go type, using the same
+// rules as encoding/json to decide what each field is called and what JSON it accepts, and validates JSON documents
+// against schemas.
+//
+// json.Unmarshal ignores fields it doesn't know about and leaves fields it can't decode at their zero values (after
+// returning an error for the first one). Validating a document against its type's schema first finds every problem
+// in it, and says where each one is with a JSON Pointer (RFC 6901) like /objectArray/1/stringVal.
+//
+//	var t Target
+//
+//	if err := jsonschema.Unmarshal(data, &t); err != nil {
+//		// err is a jsonschema.Errors listing every problem
+//	}
+//
+// Structs become objects that don't allow properties other than their fields. Property names must match exactly,
+// although json.Unmarshal would also accept them in a different case. No properties are required, as encoding/json
+// doesn't require any, unless a field is tagged with schema:"required".
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The version of JSON Schema this package generates
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// A Schema describes the JSON values that are allowed somewhere in a document. Only the keywords this package
+// generates are supported.
+type Schema struct {
+	Schema string             `json:"$schema,omitempty"`
+	Ref    string             `json:"$ref,omitempty"`
+	Defs   map[string]*Schema `json:"$defs,omitempty"`
+
+	Title string    `json:"title,omitempty"`
+	Type  Types     `json:"type,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+
+	// For strings
+	Format          string `json:"format,omitempty"`
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	// For numbers
+	Minimum *float64 `json:"minimum,omitempty"`
+
+	// For objects
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// For arrays
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	// A schema that no value matches, written as false
+	never bool
+}
+
+// False is the schema that no value matches. It is used as the AdditionalProperties of objects made from structs.
+var False = &Schema{never: true}
+
+// MarshalJSON writes False as false and any other schema as an object
+func (s *Schema) MarshalJSON() ([]byte, error) {
+
+	if s.never {
+		return []byte("false"), nil
+	}
+
+	type plain Schema
+
+	return json.Marshal((*plain)(s))
+}
+
+// UnmarshalJSON reads a schema written as an object, or as true (any value) or false (no value)
+func (s *Schema) UnmarshalJSON(data []byte) error {
+
+	var b bool
+
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Schema{never: !b}
+		return nil
+	}
+
+	type plain Schema
+
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// Types are the JSON types a value may have: "null", "boolean", "integer", "number", "string", "array" or "object".
+// A single type is written as a string, more than one as an array.
+type Types []string
+
+// MarshalJSON writes a single type as a string
+func (t Types) MarshalJSON() ([]byte, error) {
+
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON reads a string or an array of strings
+func (t *Types) UnmarshalJSON(data []byte) error {
+
+	var one string
+
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+func (t Types) has(name string) bool {
+
+	for _, n := range t {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t Types) nullable() Types {
+
+	if t.has("null") {
+		return t
+	}
+
+	return append(append(Types(nil), t...), "null")
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// For returns the schema of the JSON that v's type is marshalled to and unmarshalled from. Named struct types used in
+// more than one place, or that contain themselves, are defined once in $defs and referred to with $ref.
+func For(v interface{}) (*Schema, error) {
+
+	t := reflect.TypeOf(v)
+
+	if t == nil {
+		return nil, fmt.Errorf("can't generate a schema for nil")
+	}
+
+	return ForType(t)
+}
+
+// ForType is like For, but takes a type
+func ForType(t reflect.Type) (*Schema, error) {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g := &generator{root: t, names: make(map[reflect.Type]string), defs: make(map[string]*Schema)}
+
+	s, err := g.schema(t, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.Schema = Draft
+
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+
+	return s, nil
+}
+
+type generator struct {
+	root reflect.Type
+
+	// The names in $defs of struct types seen so far, and their schemas
+	names map[reflect.Type]string
+	defs  map[string]*Schema
+}
+
+// schema returns t's schema. top is true for the root type, whose schema is the document's top level.
+func (g *generator) schema(t reflect.Type, top bool) (*Schema, error) {
+
+	// Types that marshal themselves could produce anything, except for time.Time and types that marshal to text
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}, nil
+	case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType):
+		return &Schema{}, nil
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &Schema{Type: Types{"string"}}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{"integer"}}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: Types{"integer"}, Minimum: &zero}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}, nil
+
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}, nil
+
+	case reflect.Interface:
+		return &Schema{}, nil
+
+	case reflect.Ptr:
+		return g.nullable(t.Elem())
+
+	case reflect.Slice:
+
+		// []byte is marshalled as a base64 string
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string", "null"}, ContentEncoding: "base64"}, nil
+		}
+
+		s, err := g.array(t)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Type = s.Type.nullable()
+
+		return s, nil
+
+	case reflect.Array:
+
+		s, err := g.array(t)
+
+		if err != nil {
+			return nil, err
+		}
+
+		n := t.Len()
+		s.MinItems, s.MaxItems = &n, &n
+
+		return s, nil
+
+	case reflect.Map:
+		return g.object(t)
+
+	case reflect.Struct:
+
+		if top {
+			g.names[t] = "#"
+			return g.fields(t)
+		}
+
+		return g.ref(t)
+	}
+
+	return nil, fmt.Errorf("%s can't be represented in JSON", t)
+}
+
+// nullable returns the schema of a value of type t or null, as when t is pointed to
+func (g *generator) nullable(t reflect.Type) (*Schema, error) {
+
+	s, err := g.schema(t, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}, nil
+	case len(s.Type) > 0:
+		s.Type = s.Type.nullable()
+	}
+
+	return s, nil
+}
+
+func (g *generator) array(t reflect.Type) (*Schema, error) {
+
+	items, err := g.schema(t.Elem(), false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{Type: Types{"array"}, Items: items}, nil
+}
+
+func (g *generator) object(t reflect.Type) (*Schema, error) {
+
+	// Like encoding/json, allow string keys, integer keys and keys that marshal themselves to text
+	switch k := t.Key(); {
+	case k.Kind() == reflect.String:
+	case k.Implements(textMarshalerType):
+	case k.Kind() >= reflect.Int && k.Kind() <= reflect.Uintptr:
+	default:
+		return nil, fmt.Errorf("%s can't be represented in JSON: its keys aren't strings or integers", t)
+	}
+
+	values, err := g.schema(t.Elem(), false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{Type: Types{"object", "null"}, AdditionalProperties: values}, nil
+}
+
+// ref returns a reference to the definition of a struct type, adding the definition if there isn't one yet
+func (g *generator) ref(t reflect.Type) (*Schema, error) {
+
+	if name, okay := g.names[t]; okay {
+		return &Schema{Ref: name}, nil
+	}
+
+	// Anonymous structs aren't worth defining, as they can't be used anywhere else
+	if t.Name() == "" {
+		return g.fields(t)
+	}
+
+	name := t.Name()
+
+	for i := 2; g.defs[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", t.Name(), i)
+	}
+
+	ref := "#/$defs/" + name
+
+	// Registered before the fields are generated, so fields of the same type refer to it
+	g.names[t] = ref
+	g.defs[name] = &Schema{}
+
+	s, err := g.fields(t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	*g.defs[name] = *s
+
+	return &Schema{Ref: ref}, nil
+}
+
+// fields returns the schema of a struct
+func (g *generator) fields(t reflect.Type) (*Schema, error) {
+
+	s := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema), AdditionalProperties: False}
+
+	if t.Name() != "" {
+		s.Title = t.Name()
+	}
+
+	for _, f := range jsonFields(t) {
+
+		p, err := g.schema(f.typ, false)
+
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %s", f.name, err.Error())
+		}
+
+		if f.quoted {
+			p = quoted(p)
+		}
+
+		s.Properties[f.name] = p
+
+		if f.required {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+
+	return s, nil
+}
+
+// quoted returns the schema of a value marshalled with the ,string option, which writes numbers and bools as strings
+func quoted(s *Schema) *Schema {
+
+	if len(s.Type) == 0 || s.Type.has("string") || s.Type.has("object") || s.Type.has("array") {
+		return s
+	}
+
+	return &Schema{Type: Types{"string"}}
+}
+
+// A field as encoding/json sees it
+type field struct {
+	name     string
+	typ      reflect.Type
+	quoted   bool
+	required bool
+
+	// How deeply embedded the field is, and whether its name came from a tag, which decide which of two fields with
+	// the same name is used
+	depth  int
+	tagged bool
+}
+
+var fieldCache sync.Map
+
+// jsonFields returns the fields encoding/json reads and writes for a struct type, in the order it writes them. It
+// follows encoding/json's rules: unexported fields and fields tagged "-" are ignored, fields of embedded structs
+// without a name in their tag are promoted, and of several fields with the same name the shallowest (then the tagged
+// one) wins, unless that leaves a tie, in which case none of them are used.
+func jsonFields(t reflect.Type) []field {
+
+	if f, okay := fieldCache.Load(t); okay {
+		return f.([]field)
+	}
+
+	var all []field
+	collectFields(t, 0, make(map[reflect.Type]bool), &all)
+
+	byName := make(map[string][]field)
+	var order []string
+
+	for _, f := range all {
+
+		if _, okay := byName[f.name]; !okay {
+			order = append(order, f.name)
+		}
+
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	var fields []field
+
+	for _, name := range order {
+		if f, okay := dominant(byName[name]); okay {
+			fields = append(fields, f)
+		}
+	}
+
+	fieldCache.Store(t, fields)
+
+	return fields
+}
+
+func collectFields(t reflect.Type, depth int, visited map[reflect.Type]bool, all *[]field) {
+
+	if visited[t] {
+		return
+	}
+
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i:]
+		}
+
+		ft := sf.Type
+
+		if sf.Anonymous {
+
+			et := ft
+
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+
+			// Embedded structs without a name are flattened into the struct that embeds them
+			if name == "" && et.Kind() == reflect.Struct {
+				collectFields(et, depth+1, visited, all)
+				continue
+			}
+
+			if !sf.IsExported() && et.Kind() != reflect.Struct {
+				continue
+			}
+
+		} else if !sf.IsExported() {
+			continue
+		}
+
+		f := field{name: name, typ: ft, depth: depth, tagged: name != ""}
+
+		if name == "" {
+			f.name = sf.Name
+		}
+
+		f.quoted = strings.Contains(opts+",", ",string,")
+		f.required = sf.Tag.Get("schema") == "required"
+
+		*all = append(*all, f)
+	}
+}
+
+func dominant(fields []field) (field, bool) {
+
+	sort.SliceStable(fields, func(i, j int) bool {
+
+		if fields[i].depth != fields[j].depth {
+			return fields[i].depth < fields[j].depth
+		}
+
+		return fields[i].tagged && !fields[j].tagged
+	})
+
+	if len(fields) > 1 && fields[0].depth == fields[1].depth && fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+
+	return fields[0], true
+}
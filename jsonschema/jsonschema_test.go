@@ -0,0 +1,268 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Target from essential/json.go, with its tags quoted
+type target struct {
+	NumberVal   float64   `json:"numberVal"`
+	BoolVal     bool      `json:"boolVal"`
+	StringVal   string    `json:"stringVal"`
+	NumArray    []float64 `json:"numArray"`
+	BoolArray   []bool    `json:"boolArray"`
+	StringArray []string  `json:"stringArray"`
+	ObjectVal   *target   `json:"objectVal"`
+	ObjectArray []target  `json:"objectArray"`
+}
+
+func mustFor(t *testing.T, v interface{}) *Schema {
+
+	t.Helper()
+
+	s, err := For(v)
+
+	if err != nil {
+		t.Fatalf("Unexpected error generating a schema for %T: %v", v, err)
+	}
+
+	return s
+}
+
+func marshal(t *testing.T, v interface{}) string {
+
+	t.Helper()
+
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		t.Fatalf("Unexpected error marshalling: %v", err)
+	}
+
+	return string(data)
+}
+
+func TestRecursiveStruct(t *testing.T) {
+
+	s := mustFor(t, target{})
+
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"target","type":"object",` +
+		`"properties":{` +
+		`"boolArray":{"type":["array","null"],"items":{"type":"boolean"}},` +
+		`"boolVal":{"type":"boolean"},` +
+		`"numArray":{"type":["array","null"],"items":{"type":"number"}},` +
+		`"numberVal":{"type":"number"},` +
+		`"objectArray":{"type":["array","null"],"items":{"$ref":"#"}},` +
+		`"objectVal":{"anyOf":[{"$ref":"#"},{"type":"null"}]},` +
+		`"stringArray":{"type":["array","null"],"items":{"type":"string"}},` +
+		`"stringVal":{"type":"string"}},` +
+		`"additionalProperties":false}`
+
+	if got := marshal(t, s); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// The schema can be read back
+	var read Schema
+
+	if err := json.Unmarshal([]byte(expected), &read); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if got := marshal(t, &read); got != expected {
+		t.Errorf("Expected the schema to be unchanged by reading it, got\n%s", got)
+	}
+}
+
+type base struct {
+	ID      int `json:"id"`
+	Created time.Time
+}
+
+type address struct {
+	Street string `json:"street" schema:"required"`
+}
+
+type customer struct {
+	base
+	Name     string            `json:"name,omitempty" schema:"required"`
+	Age      uint8             `json:"age,string"`
+	Home     address           `json:"home"`
+	Work     *address          `json:"work"`
+	Tags     map[string]string `json:"tags"`
+	Raw      json.RawMessage   `json:"raw"`
+	Photo    []byte            `json:"photo"`
+	Point    [2]float64        `json:"point"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestStructRules(t *testing.T) {
+
+	s := mustFor(t, &customer{})
+
+	var names []string
+
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+
+	// Embedded fields are promoted, and ignored and unexported fields are left out
+	expected := []string{"Created", "age", "home", "id", "name", "photo", "point", "raw", "tags", "work"}
+
+	if !reflect.DeepEqual(setOf(names), setOf(expected)) {
+		t.Errorf("Expected properties %v, got %v", expected, names)
+	}
+
+	checks := map[string]string{
+		"Created": `{"type":"string","format":"date-time"}`,
+		"age":     `{"type":"string"}`,
+		"home":    `{"$ref":"#/$defs/address"}`,
+		"work":    `{"anyOf":[{"$ref":"#/$defs/address"},{"type":"null"}]}`,
+		"tags":    `{"type":["object","null"],"additionalProperties":{"type":"string"}}`,
+		"raw":     `{}`,
+		"photo":   `{"type":["string","null"],"contentEncoding":"base64"}`,
+		"point":   `{"type":"array","items":{"type":"number"},"minItems":2,"maxItems":2}`,
+	}
+
+	for name, expected := range checks {
+		if got := marshal(t, s.Properties[name]); got != expected {
+			t.Errorf("Expected %s to be %s, got %s", name, expected, got)
+		}
+	}
+
+	if !reflect.DeepEqual(s.Required, []string{"name"}) {
+		t.Errorf("Expected name to be required, got %v", s.Required)
+	}
+
+	if def := marshal(t, s.Defs["address"]); !strings.Contains(def, `"required":["street"]`) {
+		t.Errorf("Unexpected definition of address %s", def)
+	}
+
+	if _, err := For(struct{ C chan int }{}); err == nil {
+		t.Errorf("Expected an error for a type that can't be marshalled")
+	}
+}
+
+// setOf returns the strings as a map, so they can be compared in any order
+func setOf(strs []string) map[string]bool {
+
+	m := make(map[string]bool)
+
+	for _, s := range strs {
+		m[s] = true
+	}
+
+	return m
+}
+
+func TestValidate(t *testing.T) {
+
+	s := mustFor(t, target{})
+
+	tests := []struct {
+		doc    string
+		errors []string
+	}{
+		{`{"numberVal": 5, "objectVal": {"objectVal": null}, "objectArray": [{"stringVal": "A"}]}`, nil},
+		{`{"numberVal": "5"}`, []string{"/numberVal: expected number, got string"}},
+		{`{"numbervalue": 5, "boolVal": 1}`, []string{"/boolVal: expected boolean, got integer", "/numbervalue: isn't a known property"}},
+		{`{"numArray": [1, "2", 3]}`, []string{"/numArray/1: expected number, got string"}},
+		{`{"objectArray": [{}, {"stringVal": true}]}`, []string{"/objectArray/1/stringVal: expected string, got boolean"}},
+		{`{"objectVal": {"objectVal": {"x": 1}}}`, []string{"/objectVal/objectVal/x: isn't a known property"}},
+		{`{"objectVal": 7}`, []string{"/objectVal: expected object or null, got integer"}},
+		{`[]`, []string{"(root): expected object, got array"}},
+	}
+
+	for _, test := range tests {
+
+		err := s.Validate([]byte(test.doc))
+
+		var got []string
+
+		if errs, okay := err.(Errors); okay {
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+		} else if err != nil {
+			t.Errorf("Unexpected error validating %s: %v", test.doc, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, test.errors) {
+			t.Errorf("Expected %s to have errors %q, got %q", test.doc, test.errors, got)
+		}
+	}
+
+	if err := s.Validate([]byte(`{"numberVal": `)); err == nil {
+		t.Errorf("Expected an error for a document that isn't JSON")
+	}
+}
+
+func TestValidateStructRules(t *testing.T) {
+
+	s := mustFor(t, customer{})
+
+	doc := `{"id": 1.5, "Created": "yesterday", "age": 30, "home": {}, "work": {"street": 2}, "tags": {"a": 1},` +
+		` "point": [1], "name": "Ann", "raw": [1, {"anything": true}]}`
+
+	expected := Errors{
+		{"/Created", `"yesterday" isn't a date-time`},
+		{"/age", "expected string, got integer"},
+		{"/home/street", "is required"},
+		{"/id", "expected integer, got number"},
+		{"/point", "has 1 items, expected at least 2"},
+		{"/tags/a", "expected string, got integer"},
+		{"/work/street", "expected string, got integer"},
+	}
+
+	if err := s.Validate([]byte(doc)); !reflect.DeepEqual(err, expected) {
+		t.Errorf("Expected %v, got %v", expected, err)
+	}
+
+	// Names containing / and ~ are escaped in paths
+	tags := s.Properties["tags"]
+
+	if err := tags.Validate([]byte(`{"a/b~c": 1}`)); err == nil || err.Error() != "/a~1b~0c: expected string, got integer" {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// Without a name, and with a negative age
+	if err := mustFor(t, struct {
+		Name string `schema:"required"`
+		Age  uint
+	}{}).Validate([]byte(`{"Age": -1}`)); err == nil || err.Error() != "2 errors: /Name: is required; /Age: -1 is less than the minimum of 0" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	var v target
+
+	err := Unmarshal([]byte(`{"stringVal": 3, "extra": true}`), &v)
+
+	if errs, okay := err.(Errors); !okay || len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", err)
+	}
+
+	if v.StringVal != "" {
+		t.Errorf("Expected nothing to be unmarshalled from an invalid document")
+	}
+
+	if err := Unmarshal([]byte(`{"stringVal": "a", "objectVal": {"numberVal": 2}}`), &v); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if v.StringVal != "a" || v.ObjectVal == nil || v.ObjectVal.NumberVal != 2 {
+		t.Errorf("Unexpected value %+v", v)
+	}
+
+	if err := Unmarshal([]byte(`{}`), v); err == nil {
+		t.Errorf("Expected an error unmarshalling into something that isn't a pointer")
+	}
+}
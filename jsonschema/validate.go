@@ -0,0 +1,344 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An Error is a problem with one value in a document
+type Error struct {
+	// A JSON Pointer to the value ("" is the whole document)
+	Path    string
+	Message string
+}
+
+func (e *Error) Error() string {
+
+	if e.Path == "" {
+		return "(root): " + e.Message
+	}
+
+	return e.Path + ": " + e.Message
+}
+
+// Errors are all the problems found in a document. Problems with the properties of an object are in the order of the
+// properties' names.
+type Errors []*Error
+
+func (e Errors) Error() string {
+
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	msgs := make([]string, len(e))
+
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d errors: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Validate checks a JSON document against the schema. It returns an Errors listing every problem, a *json.SyntaxError
+// if data isn't JSON, or nil if the document is valid.
+func (s *Schema) Validate(data []byte) error {
+
+	d := json.NewDecoder(bytes.NewReader(data))
+
+	// Numbers are kept as text, so large integers aren't rounded and 1.0 can be told apart from 1
+	d.UseNumber()
+
+	var doc interface{}
+
+	if err := d.Decode(&doc); err != nil {
+		return err
+	}
+
+	if d.More() {
+		return fmt.Errorf("there is more than one JSON value")
+	}
+
+	return s.ValidateValue(doc)
+}
+
+// ValidateValue checks a decoded JSON document (as json.Unmarshal would decode it into an interface{}, with numbers as
+// float64 or json.Number) against the schema
+func (s *Schema) ValidateValue(doc interface{}) error {
+
+	v := &validator{root: s}
+	v.validate(s, doc, "")
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	return nil
+}
+
+var schemaCache sync.Map
+
+// Unmarshal validates data against the schema of v's type, then unmarshals it into v (which must be a pointer) if it
+// is valid
+func Unmarshal(data []byte, v interface{}) error {
+
+	t := reflect.TypeOf(v)
+
+	if t == nil || t.Kind() != reflect.Ptr {
+		return fmt.Errorf("can't unmarshal into %T, it isn't a pointer", v)
+	}
+
+	var s *Schema
+
+	if cached, okay := schemaCache.Load(t); okay {
+		s = cached.(*Schema)
+	} else {
+
+		var err error
+
+		if s, err = ForType(t); err != nil {
+			return err
+		}
+
+		schemaCache.Store(t, s)
+	}
+
+	if err := s.Validate(data); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+type validator struct {
+	root *Schema
+	errs Errors
+}
+
+func (v *validator) fail(path string, format string, args ...interface{}) {
+	v.errs = append(v.errs, &Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// resolve follows a schema's $ref, if it has one. Only references to the root ("#") and its $defs are supported.
+func (v *validator) resolve(s *Schema) (*Schema, error) {
+
+	for s.Ref != "" {
+
+		switch {
+		case s.Ref == "#":
+			s = v.root
+		case strings.HasPrefix(s.Ref, "#/$defs/"):
+
+			def, okay := v.root.Defs[unescape(strings.TrimPrefix(s.Ref, "#/$defs/"))]
+
+			if !okay {
+				return nil, fmt.Errorf("the schema has no definition for %s", s.Ref)
+			}
+
+			s = def
+		default:
+			return nil, fmt.Errorf("the schema's reference %s isn't supported", s.Ref)
+		}
+	}
+
+	return s, nil
+}
+
+func (v *validator) validate(s *Schema, value interface{}, path string) {
+
+	s, err := v.resolve(s)
+
+	if err != nil {
+		v.fail(path, "%s", err.Error())
+		return
+	}
+
+	if s.never {
+		v.fail(path, "isn't allowed")
+		return
+	}
+
+	if len(s.AnyOf) > 0 {
+		v.anyOf(s.AnyOf, value, path)
+	}
+
+	actual := typeOf(value)
+
+	if len(s.Type) > 0 && !s.Type.has(actual) && !(actual == "integer" && s.Type.has("number")) {
+		v.fail(path, "expected %s, got %s", describe(s.Type), actual)
+		return
+	}
+
+	switch value := value.(type) {
+	case json.Number, float64:
+
+		if n, err := number(value); err == nil && s.Minimum != nil && n < *s.Minimum {
+			v.fail(path, "%v is less than the minimum of %v", value, *s.Minimum)
+		}
+
+	case string:
+
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				v.fail(path, "%q isn't a date-time", value)
+			}
+		}
+
+	case []interface{}:
+		v.array(s, value, path)
+
+	case map[string]interface{}:
+		v.object(s, value, path)
+	}
+}
+
+// anyOf reports a problem if value doesn't match any of the schemas. When value has a type that one of them allows,
+// the problems it has with that schema are reported, as they are usually what is wrong.
+func (v *validator) anyOf(schemas []*Schema, value interface{}, path string) {
+
+	var closest Errors
+	var types Types
+
+	for _, s := range schemas {
+
+		sub := &validator{root: v.root}
+		sub.validate(s, value, path)
+
+		if len(sub.errs) == 0 {
+			return
+		}
+
+		resolved, err := v.resolve(s)
+
+		if err == nil {
+
+			types = append(types, resolved.Type...)
+
+			if closest == nil && (len(resolved.Type) == 0 || resolved.Type.has(typeOf(value))) {
+				closest = sub.errs
+			}
+		}
+	}
+
+	if closest != nil {
+		v.errs = append(v.errs, closest...)
+	} else {
+		v.fail(path, "expected %s, got %s", describe(types), typeOf(value))
+	}
+}
+
+func (v *validator) array(s *Schema, value []interface{}, path string) {
+
+	if s.MinItems != nil && len(value) < *s.MinItems {
+		v.fail(path, "has %d items, expected at least %d", len(value), *s.MinItems)
+	}
+
+	if s.MaxItems != nil && len(value) > *s.MaxItems {
+		v.fail(path, "has %d items, expected at most %d", len(value), *s.MaxItems)
+	}
+
+	if s.Items == nil {
+		return
+	}
+
+	for i, item := range value {
+		v.validate(s.Items, item, fmt.Sprintf("%s/%d", path, i))
+	}
+}
+
+func (v *validator) object(s *Schema, value map[string]interface{}, path string) {
+
+	for _, name := range s.Required {
+		if _, okay := value[name]; !okay {
+			v.fail(path+"/"+escape(name), "is required")
+		}
+	}
+
+	names := make([]string, 0, len(value))
+
+	for name := range value {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		p, okay := s.Properties[name]
+
+		switch {
+		case okay:
+			v.validate(p, value[name], path+"/"+escape(name))
+		case s.AdditionalProperties != nil && s.AdditionalProperties.never:
+			v.fail(path+"/"+escape(name), "isn't a known property")
+		case s.AdditionalProperties != nil:
+			v.validate(s.AdditionalProperties, value[name], path+"/"+escape(name))
+		}
+	}
+}
+
+// typeOf returns the JSON type of a decoded value. Numbers written with a fraction or an exponent aren't integers, even
+// if their value is a whole number, as encoding/json won't unmarshal them into an integer field.
+func typeOf(value interface{}) string {
+
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+
+		n, err := number(value)
+
+		if err == nil && n == float64(int64(n)) && !strings.ContainsAny(fmt.Sprint(value), ".eE") {
+			return "integer"
+		}
+
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+func number(value interface{}) (float64, error) {
+
+	if n, okay := value.(json.Number); okay {
+		return n.Float64()
+	}
+
+	return value.(float64), nil
+}
+
+// describe lists types for a message, like "string, array or null"
+func describe(types Types) string {
+
+	switch len(types) {
+	case 0:
+		return "nothing"
+	case 1:
+		return types[0]
+	}
+
+	return strings.Join(types[:len(types)-1], ", ") + " or " + types[len(types)-1]
+}
+
+// escape escapes a property name for use in a JSON Pointer
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func unescape(token string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+}
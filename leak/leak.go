@@ -0,0 +1,242 @@
+// Package leak finds goroutines that are still running when they should have finished.
+//
+// A goroutine that never returns (usually because it is blocked sending to or receiving from a channel nobody will
+// ever use again) keeps everything it refers to in memory for as long as the program runs. The functions in this
+// package compare the goroutines running before and after some code, giving any new goroutines a grace period to
+// finish, and report the ones that are left.
+//
+//	func TestSearch(t *testing.T) {
+//
+//		leak.VerifyNone(t)
+//
+//		...
+//	}
+package leak
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A Goroutine is a goroutine's entry in a stack dump
+type Goroutine struct {
+	ID int
+
+	// Why the goroutine isn't running, e.g. "chan send" or "select"
+	State string
+
+	// The function the goroutine is in
+	Function string
+
+	// The function the goroutine was started with
+	Entry string
+
+	// The function that started the goroutine (empty for the main goroutine)
+	CreatedBy string
+
+	// The goroutine's stack trace, as printed by runtime.Stack
+	Stack string
+}
+
+func (g Goroutine) String() string {
+	return fmt.Sprintf("goroutine %d [%s] started with %s, in %s", g.ID, g.State, g.Entry, g.Function)
+}
+
+// DefaultGrace is how long VerifyNone gives goroutines to finish
+var DefaultGrace = time.Second
+
+// Goroutines that belong to the runtime or the testing package rather than the code being checked. Matched against
+// the function each goroutine was started with.
+var system = []string{
+	"runtime.",
+	"os/signal.",
+	"testing.tRunner",
+	"testing.(*T).Run",
+	"testing.runFuzzing",
+}
+
+// Snapshot returns every goroutine that is running, ordered by ID
+func Snapshot() []Goroutine {
+
+	buf := make([]byte, 64*1024)
+
+	for {
+
+		n := runtime.Stack(buf, true)
+
+		if n < len(buf) {
+			return parse(string(buf[:n]))
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+var header = regexp.MustCompile(`^goroutine (\d+) \[([^\]]*)\]:$`)
+
+var gowrap = regexp.MustCompile(`\.gowrap\d+$`)
+
+// parse reads the output of runtime.Stack
+func parse(dump string) []Goroutine {
+
+	var gs []Goroutine
+
+	for _, block := range strings.Split(strings.TrimSpace(dump), "\n\n") {
+
+		s := bufio.NewScanner(strings.NewReader(block))
+
+		if !s.Scan() {
+			continue
+		}
+
+		m := header.FindStringSubmatch(s.Text())
+
+		if m == nil {
+			continue
+		}
+
+		g := Goroutine{Stack: block}
+		g.ID, _ = strconv.Atoi(m[1])
+
+		// Durations ("chan receive, 2 minutes") and other details follow the state
+		g.State, _, _ = strings.Cut(m[2], ",")
+
+		// Each frame is a function call followed by an indented file and line
+		for s.Scan() {
+
+			line := s.Text()
+
+			// Very deep stacks have frames missing from the middle
+			if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "...") {
+				continue
+			}
+
+			if strings.HasPrefix(line, "created by ") {
+				g.CreatedBy, _, _ = strings.Cut(strings.TrimPrefix(line, "created by "), " in goroutine")
+				break
+			}
+
+			f := function(line)
+
+			if g.Function == "" {
+				g.Function = f
+			}
+
+			// The runtime's own frame at the bottom of a stack isn't interesting, and neither is the wrapper the
+			// compiler generates for go statements that call a function with arguments (unless it hasn't called the
+			// function yet)
+			if f == "runtime.goexit" || (gowrap.MatchString(f) && g.Entry != "") {
+				continue
+			}
+
+			g.Entry = f
+		}
+
+		gs = append(gs, g)
+	}
+
+	sort.Slice(gs, func(i, j int) bool {
+		return gs[i].ID < gs[j].ID
+	})
+
+	return gs
+}
+
+// function removes the arguments from a function call in a stack trace
+func function(call string) string {
+
+	if i := strings.LastIndex(call, "("); i > 0 {
+		return call[:i]
+	}
+
+	return call
+}
+
+// Since returns the goroutines that have been started since before was taken and are still running after grace has
+// passed. Goroutines started with a function whose name starts with one of ignore are not reported, nor are the
+// runtime's own goroutines.
+func Since(before []Goroutine, grace time.Duration, ignore ...string) []Goroutine {
+
+	existing := make(map[int]bool)
+
+	for _, g := range before {
+		existing[g.ID] = true
+	}
+
+	deadline := time.Now().Add(grace)
+
+	for {
+
+		var leaked []Goroutine
+
+		for _, g := range Snapshot() {
+			if !existing[g.ID] && !ignored(g, ignore) {
+				leaked = append(leaked, g)
+			}
+		}
+
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func ignored(g Goroutine, ignore []string) bool {
+
+	for _, prefix := range append(system, ignore...) {
+		if strings.HasPrefix(g.Entry, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check runs fn and returns the goroutines it started that are still running once it has returned and grace has
+// passed
+func Check(fn func(), grace time.Duration, ignore ...string) []Goroutine {
+
+	before := Snapshot()
+
+	fn()
+
+	return Since(before, grace, ignore...)
+}
+
+// VerifyNone fails a test if goroutines started after VerifyNone was called are still running DefaultGrace after the
+// test (and any cleanup functions registered after VerifyNone) has finished. Call it at the start of the test.
+func VerifyNone(t testing.TB, ignore ...string) {
+
+	t.Helper()
+
+	before := Snapshot()
+
+	t.Cleanup(func() {
+		if leaked := Since(before, DefaultGrace, ignore...); len(leaked) > 0 {
+			t.Errorf("%s", Report(leaked))
+		}
+	})
+}
+
+// Report describes leaked goroutines, including their stack traces
+func Report(leaked []Goroutine) string {
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d goroutine(s) still running:\n", len(leaked))
+
+	for _, g := range leaked {
+		fmt.Fprintf(&b, "\n%s\n", g.Stack)
+	}
+
+	return b.String()
+}
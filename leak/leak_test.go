@@ -0,0 +1,113 @@
+package leak
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+
+	stop := make(chan bool)
+	defer close(stop)
+
+	leaked := Check(func() {
+
+		// Finishes within the grace period
+		go time.Sleep(20 * time.Millisecond)
+
+		// Still waiting when the grace period ends
+		go blockUntil(stop)
+
+	}, 200*time.Millisecond)
+
+	if len(leaked) != 1 {
+		t.Fatalf("Expected one leaked goroutine, got %d:\n%s", len(leaked), Report(leaked))
+	}
+
+	g := leaked[0]
+
+	if g.State != "chan receive" || g.Entry != "github.com/benhalstead/gotraining/leak.blockUntil" || !strings.Contains(g.CreatedBy, "TestCheck") {
+		t.Errorf("Unexpected goroutine %+v", g)
+	}
+
+	if leaked := Check(func() {}, 0, "github.com/benhalstead/gotraining/leak.blockUntil"); len(leaked) != 0 {
+		t.Errorf("Expected nothing to be reported, got %s", Report(leaked))
+	}
+
+	if leaked := Check(func() { go blockUntil(stop) }, 100*time.Millisecond, "github.com/benhalstead/gotraining/leak.blockUntil"); len(leaked) != 0 {
+		t.Errorf("Expected ignored goroutines not to be reported, got %s", Report(leaked))
+	}
+}
+
+// fakeT records the errors from a test
+type fakeT struct {
+	testing.TB
+	cleanup []func()
+	errors  []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanup = append(f.cleanup, fn)
+}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestVerifyNone(t *testing.T) {
+
+	defer func(g time.Duration) { DefaultGrace = g }(DefaultGrace)
+	DefaultGrace = 50 * time.Millisecond
+
+	stop := make(chan bool)
+	defer close(stop)
+
+	f := new(fakeT)
+
+	VerifyNone(f)
+	go blockUntil(stop)
+
+	f.cleanup[0]()
+
+	if len(f.errors) != 1 || !strings.Contains(f.errors[0], "1 goroutine(s) still running") || !strings.Contains(f.errors[0], "leak.blockUntil") {
+		t.Errorf("Expected the leak to be reported, got %q", f.errors)
+	}
+}
+
+func TestParse(t *testing.T) {
+
+	dump := `goroutine 1 [running]:
+main.main()
+	/src/main.go:10 +0x1d
+
+goroutine 7 [chan send, 2 minutes]:
+main.search.func1(0xc000010000)
+	/src/main.go:20 +0x25
+main.worker(...)
+	/src/main.go:25
+created by main.search in goroutine 1
+	/src/main.go:18 +0x4f
+`
+
+	gs := parse(dump)
+
+	if len(gs) != 2 {
+		t.Fatalf("Expected 2 goroutines, got %d", len(gs))
+	}
+
+	if g := gs[1]; g.ID != 7 || g.State != "chan send" || g.Function != "main.search.func1" || g.Entry != "main.worker" || g.CreatedBy != "main.search" {
+		t.Errorf("Unexpected goroutine %+v", g)
+	}
+
+	if g := gs[0]; g.Entry != "main.main" || g.CreatedBy != "" {
+		t.Errorf("Unexpected goroutine %+v", g)
+	}
+}
+
+func blockUntil(c chan bool) {
+	<-c
+}
@@ -0,0 +1,12 @@
+// Package limit controls how fast and how much work is done at once.
+//
+// A Limiter limits the rate at which something happens, like requests to a remote host, using a token bucket: the
+// bucket holds up to burst tokens, is refilled at a fixed rate, and each event takes a token. A short burst of events
+// is allowed straight away, but over time events can't happen faster than the rate.
+//
+// A Semaphore limits how much work is in progress at once. Each piece of work acquires a weight (its share of
+// something limited, like memory or connections) before it starts and releases it when it finishes.
+//
+// Both can wait for their turn with Wait, which gives up when its context is cancelled, or try without waiting with
+// TryAcquire. Both can be reconfigured while in use.
+package limit
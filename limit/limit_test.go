@@ -0,0 +1,290 @@
+package limit
+
+import (
+	"context"
+	"github.com/benhalstead/gotraining/clock"
+	"github.com/benhalstead/gotraining/leak"
+	"math"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// wait calls fn in a goroutine and returns a channel that receives its error
+func wait(fn func() error) <-chan error {
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- fn()
+	}()
+
+	return done
+}
+
+func expectPending(t *testing.T, done <-chan error) {
+
+	t.Helper()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected to still be waiting, but returned %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func expectDone(t *testing.T, done <-chan error, expected error) {
+
+	t.Helper()
+
+	select {
+	case err := <-done:
+		if err != expected {
+			t.Fatalf("Expected %v, got %v", expected, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected to have stopped waiting")
+	}
+}
+
+func TestBurstAndRefill(t *testing.T) {
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	l := NewLimiter(10, 3, c)
+
+	for i := 0; i < 3; i++ {
+		if !l.TryAcquire() {
+			t.Fatalf("Expected token %d of the burst to be available", i+1)
+		}
+	}
+
+	if l.TryAcquire() {
+		t.Fatalf("Expected the bucket to be empty")
+	}
+
+	// 10 per second is one every 100ms
+	c.Advance(99 * time.Millisecond)
+
+	if l.TryAcquire() {
+		t.Fatalf("Expected no token before 100ms")
+	}
+
+	c.Advance(time.Millisecond)
+
+	if !l.TryAcquire() {
+		t.Fatalf("Expected a token after 100ms")
+	}
+
+	// The bucket never holds more than the burst
+	c.Advance(time.Hour)
+
+	if !l.TryAcquireN(3) || l.TryAcquire() {
+		t.Fatalf("Expected exactly 3 tokens after a long wait")
+	}
+}
+
+func TestWait(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	l := NewLimiter(10, 1, c)
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	done := wait(func() error { return l.Wait(context.Background()) })
+
+	c.WaitUntilSleeping(1)
+	c.Advance(50 * time.Millisecond)
+	expectPending(t, done)
+
+	c.Advance(50 * time.Millisecond)
+	expectDone(t, done, nil)
+
+	if err := l.WaitN(context.Background(), 2); err == nil {
+		t.Errorf("Expected waiting for more than the burst to fail")
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	l := NewLimiter(1, 1, c)
+	l.TryAcquire()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := wait(func() error { return l.Wait(ctx) })
+
+	c.WaitUntilSleeping(1)
+	cancel()
+
+	expectDone(t, done, context.Canceled)
+
+	// The cancelled wait stopped its timer and didn't take a token
+	if c.Sleeping() != 0 {
+		t.Errorf("Expected the timer to be stopped")
+	}
+
+	c.Advance(time.Second)
+
+	if !l.TryAcquire() {
+		t.Errorf("Expected a token")
+	}
+}
+
+func TestReconfiguringLimiter(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+
+	// Nothing is allowed until the rate is raised
+	l := NewLimiter(0, 1, c)
+	l.TryAcquire()
+
+	done := wait(func() error { return l.Wait(context.Background()) })
+	expectPending(t, done)
+
+	// The waiting goroutine works out its wait again
+	l.SetRate(2)
+
+	c.WaitUntilSleeping(1)
+	c.Advance(500 * time.Millisecond)
+	expectDone(t, done, nil)
+
+	// Shrinking the burst throws away tokens
+	l.SetBurst(5)
+	c.Advance(10 * time.Second)
+	l.SetBurst(2)
+
+	if !l.TryAcquireN(2) || l.TryAcquire() {
+		t.Errorf("Expected exactly 2 tokens")
+	}
+
+	if l.Rate() != 2 || l.Burst() != 2 {
+		t.Errorf("Expected a rate and burst of 2, got %v and %d", l.Rate(), l.Burst())
+	}
+
+	// An infinite rate allows everything
+	l.SetRate(math.Inf(1))
+
+	if !l.TryAcquireN(100) || l.WaitN(context.Background(), 100) != nil {
+		t.Errorf("Expected an infinite rate to allow everything")
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	leak.VerifyNone(t)
+
+	s := NewSemaphore(3)
+
+	if !s.TryAcquire(2) || s.TryAcquire(2) {
+		t.Fatalf("Expected to acquire 2 then not another 2")
+	}
+
+	if err := s.Wait(context.Background(), 1); err != nil || s.Used() != 3 {
+		t.Fatalf("Expected to acquire the last 1, got %v with %d used", err, s.Used())
+	}
+
+	s.Release(3)
+
+	if s.Used() != 0 {
+		t.Errorf("Expected nothing to be used, got %d", s.Used())
+	}
+}
+
+// waitUntilQueued lets other goroutines run until n requests are waiting for s
+func waitUntilQueued(s *Semaphore, n int) {
+
+	for {
+
+		s.mu.Lock()
+		queued := s.waiters.Len()
+		s.mu.Unlock()
+
+		if queued == n {
+			return
+		}
+
+		runtime.Gosched()
+	}
+}
+
+func TestSemaphoreIsFirstComeFirstServed(t *testing.T) {
+	leak.VerifyNone(t)
+
+	s := NewSemaphore(3)
+	s.TryAcquire(2)
+
+	big := wait(func() error { return s.Wait(context.Background(), 3) })
+	waitUntilQueued(s, 1)
+
+	// There is room for 1, but the big request got there first
+	if s.TryAcquire(1) {
+		t.Fatalf("Expected TryAcquire not to jump the queue")
+	}
+
+	small := wait(func() error { return s.Wait(context.Background(), 1) })
+	waitUntilQueued(s, 2)
+
+	s.Release(2)
+	expectDone(t, big, nil)
+	expectPending(t, small)
+
+	s.Release(3)
+	expectDone(t, small, nil)
+}
+
+func TestSemaphoreCancelled(t *testing.T) {
+	leak.VerifyNone(t)
+
+	s := NewSemaphore(3)
+	s.TryAcquire(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	big := wait(func() error { return s.Wait(ctx, 3) })
+	waitUntilQueued(s, 1)
+
+	small := wait(func() error { return s.Wait(context.Background(), 1) })
+	waitUntilQueued(s, 2)
+
+	// Giving up on the big request lets the small one through
+	cancel()
+
+	expectDone(t, big, context.Canceled)
+	expectDone(t, small, nil)
+
+	if s.Used() != 3 {
+		t.Errorf("Expected 3 to be used, got %d", s.Used())
+	}
+}
+
+func TestResizingSemaphore(t *testing.T) {
+	leak.VerifyNone(t)
+
+	s := NewSemaphore(2)
+	s.TryAcquire(2)
+
+	// More than the size, so it waits until the semaphore grows
+	done := wait(func() error { return s.Wait(context.Background(), 3) })
+	waitUntilQueued(s, 1)
+
+	s.SetSize(5)
+	expectDone(t, done, nil)
+
+	// Shrinking below what is in use stops anything else being acquired
+	s.SetSize(1)
+	s.Release(4)
+
+	if s.TryAcquire(1) {
+		t.Errorf("Expected nothing to be acquired while 1 of 1 is used")
+	}
+
+	s.Release(1)
+
+	if !s.TryAcquire(1) || s.Size() != 1 {
+		t.Errorf("Expected to acquire 1 of 1")
+	}
+}
@@ -0,0 +1,194 @@
+package limit
+
+import (
+	"context"
+	"fmt"
+	"github.com/benhalstead/gotraining/clock"
+	"math"
+	"sync"
+	"time"
+)
+
+// A Limiter allows events to happen at up to rate per second, with bursts of up to burst events. It is safe to use
+// from multiple goroutines.
+type Limiter struct {
+	mu    sync.Mutex
+	clock clock.Clock
+
+	rate  float64
+	burst int
+
+	// Tokens in the bucket when it was last refilled
+	tokens float64
+	last   time.Time
+
+	// Closed (and replaced) whenever the rate or burst changes, so waiting goroutines work out their wait again
+	changed chan struct{}
+}
+
+// NewLimiter creates a Limiter that tells the time with c and starts with a full bucket. A rate of zero allows nothing
+// (until the rate is changed) and a rate of math.Inf(1) allows everything.
+func NewLimiter(rate float64, burst int, c clock.Clock) *Limiter {
+
+	if c == nil {
+		c = clock.RealClock{}
+	}
+
+	return &Limiter{
+		clock:   c,
+		rate:    rate,
+		burst:   burst,
+		tokens:  float64(burst),
+		last:    c.Now(),
+		changed: make(chan struct{}),
+	}
+}
+
+// Rate returns the number of events allowed per second
+func (l *Limiter) Rate() float64 {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate
+}
+
+// Burst returns the most events allowed at once
+func (l *Limiter) Burst() int {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.burst
+}
+
+// SetRate changes the number of events allowed per second. Tokens added before the change were added at the old rate.
+func (l *Limiter) SetRate(rate float64) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	l.rate = rate
+	l.notify()
+}
+
+// SetBurst changes the most events allowed at once. If the bucket holds more tokens than the new burst, the extra
+// tokens are thrown away.
+func (l *Limiter) SetBurst(burst int) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	l.burst = burst
+	l.tokens = math.Min(l.tokens, float64(burst))
+	l.notify()
+}
+
+// TryAcquire takes a token if one is available, and reports whether it did
+func (l *Limiter) TryAcquire() bool {
+	return l.TryAcquireN(1)
+}
+
+// TryAcquireN takes n tokens if they are all available, and reports whether it did
+func (l *Limiter) TryAcquireN(n int) bool {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.take(n)
+}
+
+// Wait waits until a token is available and takes it
+func (l *Limiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN waits until n tokens are available and takes them. It returns an error without waiting if n is more than the
+// burst, and returns ctx's error if ctx is cancelled first.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+
+	for {
+
+		l.mu.Lock()
+
+		if n > l.burst && !math.IsInf(l.rate, 1) {
+			burst := l.burst
+			l.mu.Unlock()
+
+			return fmt.Errorf("%d tokens is more than the limiter's burst of %d", n, burst)
+		}
+
+		if l.take(n) {
+			l.mu.Unlock()
+			return nil
+		}
+
+		// A nil channel never receives, so with a rate of zero only a change (or ctx) ends the wait
+		var timer clock.Timer
+		var ready <-chan time.Time
+
+		if l.rate > 0 {
+			missing := float64(n) - l.tokens
+			timer = l.clock.NewTimer(time.Duration(math.Ceil(missing / l.rate * float64(time.Second))))
+			ready = timer.C()
+		}
+
+		changed := l.changed
+
+		l.mu.Unlock()
+
+		// Another goroutine may take the tokens first, so check again after waking
+		select {
+		case <-ready:
+		case <-changed:
+		case <-ctx.Done():
+		}
+
+		// In case the wait ended some other way, as a VirtualClock would go on counting the timer as sleeping
+		if timer != nil {
+			timer.Stop()
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// take refills the bucket then takes n tokens if there are enough. Must be called with the lock held.
+func (l *Limiter) take(n int) bool {
+
+	if math.IsInf(l.rate, 1) {
+		return true
+	}
+
+	l.refill()
+
+	if l.tokens < float64(n) {
+		return false
+	}
+
+	l.tokens -= float64(n)
+
+	return true
+}
+
+// refill adds the tokens earned since the bucket was last refilled. Must be called with the lock held.
+func (l *Limiter) refill() {
+
+	now := l.clock.Now()
+
+	if elapsed := now.Sub(l.last); elapsed > 0 && l.rate > 0 {
+		l.tokens = math.Min(float64(l.burst), l.tokens+elapsed.Seconds()*l.rate)
+	}
+
+	l.last = now
+}
+
+// notify wakes every waiting goroutine. Must be called with the lock held.
+func (l *Limiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
@@ -0,0 +1,149 @@
+package limit
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// A Semaphore allows work with a total weight of up to its size to be in progress at once. Goroutines waiting for
+// room are served in the order they started waiting, so a large request isn't starved by a stream of small ones. It is
+// safe to use from multiple goroutines.
+type Semaphore struct {
+	mu   sync.Mutex
+	size int64
+	used int64
+
+	// Goroutines waiting for room, oldest first
+	waiters list.List
+}
+
+type waiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewSemaphore creates a Semaphore with a total weight of size
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Size returns the total weight that can be acquired at once
+func (s *Semaphore) Size() int64 {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size
+}
+
+// Used returns the weight currently acquired
+func (s *Semaphore) Used() int64 {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.used
+}
+
+// SetSize changes the total weight that can be acquired at once. If the semaphore shrinks below the weight already
+// acquired, nothing more is acquired until enough has been released.
+func (s *Semaphore) SetSize(size int64) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.size = size
+	s.notify()
+}
+
+// TryAcquire acquires a weight of n if there is room and nobody is waiting, and reports whether it did
+func (s *Semaphore) TryAcquire(n int64) bool {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.used+n <= s.size && s.waiters.Len() == 0 {
+		s.used += n
+		return true
+	}
+
+	return false
+}
+
+// Wait waits until there is room, then acquires a weight of n. It returns ctx's error (having acquired nothing) if ctx
+// is cancelled first. A weight larger than the semaphore's size waits until the size is increased.
+func (s *Semaphore) Wait(ctx context.Context, n int64) error {
+
+	s.mu.Lock()
+
+	if s.used+n <= s.size && s.waiters.Len() == 0 {
+		s.used += n
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	w := &waiter{n: n, ready: make(chan struct{})}
+	e := s.waiters.PushBack(w)
+
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+
+	case <-ctx.Done():
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		select {
+		case <-w.ready:
+			// Acquired just as ctx was cancelled. Return it, so the caller doesn't have to.
+			s.used -= n
+			s.notify()
+
+		default:
+			s.waiters.Remove(e)
+
+			// This waiter may have been holding up smaller ones behind it
+			s.notify()
+		}
+
+		return ctx.Err()
+	}
+}
+
+// Release releases a weight of n, which must have been acquired
+func (s *Semaphore) Release(n int64) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n > s.used {
+		panic(fmt.Sprintf("limit: released %d but only %d was acquired", n, s.used))
+	}
+
+	s.used -= n
+	s.notify()
+}
+
+// notify lets waiting goroutines acquire their weight, in order, until one doesn't fit. Must be called with the lock
+// held.
+func (s *Semaphore) notify() {
+
+	for e := s.waiters.Front(); e != nil; e = s.waiters.Front() {
+
+		w := e.Value.(*waiter)
+
+		if s.used+w.n > s.size {
+			return
+		}
+
+		s.used += w.n
+		s.waiters.Remove(e)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,62 @@
+package lint
+
+import (
+	"go/ast"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// DeferLoop reports defer statements inside loops. Deferred calls run when the surrounding function returns, not at
+// the end of each iteration, so resources 'closed' this way stay open until the loop (and the rest of the function)
+// has finished. A defer inside a function literal in a loop is fine, as it runs when the literal returns.
+var DeferLoop = &analysis.Analyzer{
+	Name:     "deferloop",
+	Doc:      "check for defer statements inside loops",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runDeferLoop,
+}
+
+func runDeferLoop(pass *analysis.Pass) (interface{}, error) {
+
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	in.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+
+		switch f := n.(type) {
+		case *ast.FuncDecl:
+			if f.Body != nil {
+				checkDefers(pass, f.Body, false)
+			}
+		case *ast.FuncLit:
+			checkDefers(pass, f.Body, false)
+		}
+	})
+
+	return nil, nil
+}
+
+// checkDefers reports defer statements in a function body that are inside a loop. Function literals are skipped as
+// they are checked separately.
+func checkDefers(pass *analysis.Pass, body ast.Node, inLoop bool) {
+
+	ast.Inspect(body, func(n ast.Node) bool {
+
+		switch s := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ForStmt:
+			checkDefers(pass, s.Body, true)
+			return false
+		case *ast.RangeStmt:
+			checkDefers(pass, s.Body, true)
+			return false
+		case *ast.DeferStmt:
+			if inLoop {
+				pass.Reportf(s.Pos(), "defer inside a loop: the call runs when the function returns, not at the end of each iteration")
+			}
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,71 @@
+package lint
+
+import (
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// IgnoredError reports errors that are assigned to the blank identifier, such as
+//
+//	result, _ = MultiReturn()
+//
+// Values of other types assigned to _ are not reported.
+var IgnoredError = &analysis.Analyzer{
+	Name:     "ignorederr",
+	Doc:      "check for errors that are assigned to _ and never checked",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runIgnoredError,
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func runIgnoredError(pass *analysis.Pass) (interface{}, error) {
+
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	in.Preorder([]ast.Node{(*ast.AssignStmt)(nil)}, func(n ast.Node) {
+
+		a := n.(*ast.AssignStmt)
+
+		for i, lhs := range a.Lhs {
+
+			if id, okay := lhs.(*ast.Ident); !okay || id.Name != "_" {
+				continue
+			}
+
+			var t types.Type
+
+			if len(a.Lhs) == len(a.Rhs) {
+				t = pass.TypesInfo.TypeOf(a.Rhs[i])
+			} else if call, okay := a.Rhs[0].(*ast.CallExpr); okay {
+
+				// Comma-ok expressions (v, ok := m[k]) also have two results, but only calls can return an error
+				if tuple, okay := pass.TypesInfo.TypeOf(call).(*types.Tuple); okay && i < tuple.Len() {
+					t = tuple.At(i).Type()
+				}
+			}
+
+			if isError(t) {
+				pass.Reportf(lhs.Pos(), "an error is assigned to _ and never checked")
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+func isError(t types.Type) bool {
+
+	if t == nil {
+		return false
+	}
+
+	if b, okay := t.(*types.Basic); okay && b.Kind() == types.UntypedNil {
+		return false
+	}
+
+	return types.Implements(t, errorType)
+}
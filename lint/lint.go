@@ -0,0 +1,24 @@
+// Package lint finds the mistakes the lessons warn about. Several lessons contain these mistakes on purpose, so running
+// the analyzers over the repository is also a good way to find them.
+//
+// Each analyzer is a go/analysis Analyzer, so they can be run by go vet through cmd/gotraining-vet:
+//
+//	go install ./cmd/gotraining-vet
+//	go vet -vettool=$(which gotraining-vet) ./...
+//
+// The analyzers are written against the golang.org/x/tools version in the repository's go.mod (v0.42.0). Check they
+// still pass their tests before upgrading it, as the go/analysis API has changed between releases before.
+package lint
+
+import (
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzers is every analyzer in this package
+var Analyzers = []*analysis.Analyzer{
+	PrintfArity,
+	StructTags,
+	DeferLoop,
+	IgnoredError,
+	LoopClosure,
+}
@@ -0,0 +1,28 @@
+package lint
+
+import (
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+// The fixtures in testdata are taken from the lessons that make these mistakes
+
+func TestPrintfArity(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), PrintfArity, "printf")
+}
+
+func TestStructTags(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), StructTags, "structtags")
+}
+
+func TestDeferLoop(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), DeferLoop, "deferloop")
+}
+
+func TestIgnoredError(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), IgnoredError, "ignorederr")
+}
+
+func TestLoopClosure(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), LoopClosure, "loopclosure")
+}
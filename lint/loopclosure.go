@@ -0,0 +1,117 @@
+package lint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// LoopClosure reports goroutines started in a loop with a function literal that uses the loop's variables, such as
+//
+//	for i := 0; i < 10; i++ {
+//		go func() {
+//			fmt.Println(i)
+//		}()
+//	}
+//
+// Before Go 1.22 every iteration shared the same variables, so each goroutine sees whatever value the variable has
+// when it gets round to reading it (usually the last). Files built with Go 1.22 or later get a new variable for each
+// iteration and are not reported.
+var LoopClosure = &analysis.Analyzer{
+	Name:     "loopclosure",
+	Doc:      "check for goroutines that capture loop variables",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runLoopClosure,
+}
+
+func runLoopClosure(pass *analysis.Pass) (interface{}, error) {
+
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	in.WithStack([]ast.Node{(*ast.ForStmt)(nil), (*ast.RangeStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+
+		if !push {
+			return true
+		}
+
+		if file, okay := stack[0].(*ast.File); okay && perIterationLoopVars(pass, file) {
+			return false
+		}
+
+		var vars []ast.Expr
+		var body *ast.BlockStmt
+
+		switch l := n.(type) {
+		case *ast.ForStmt:
+
+			if init, okay := l.Init.(*ast.AssignStmt); okay && init.Tok == token.DEFINE {
+				vars = init.Lhs
+			}
+
+			body = l.Body
+
+		case *ast.RangeStmt:
+
+			if l.Tok == token.DEFINE {
+				vars = []ast.Expr{l.Key, l.Value}
+			}
+
+			body = l.Body
+		}
+
+		loopVars := make(map[types.Object]bool)
+
+		for _, v := range vars {
+			if id, okay := v.(*ast.Ident); okay && id.Name != "_" {
+				loopVars[pass.TypesInfo.Defs[id]] = true
+			}
+		}
+
+		if len(loopVars) == 0 {
+			return true
+		}
+
+		ast.Inspect(body, func(n ast.Node) bool {
+
+			g, okay := n.(*ast.GoStmt)
+
+			if !okay {
+				return true
+			}
+
+			lit, okay := g.Call.Fun.(*ast.FuncLit)
+
+			if !okay {
+				return true
+			}
+
+			ast.Inspect(lit.Body, func(n ast.Node) bool {
+
+				if id, okay := n.(*ast.Ident); okay && loopVars[pass.TypesInfo.Uses[id]] {
+					pass.Reportf(id.Pos(), "loop variable %s captured by func literal in go statement", id.Name)
+				}
+
+				return true
+			})
+
+			return false
+		})
+
+		return true
+	})
+
+	return nil, nil
+}
+
+// perIterationLoopVars reports whether a file is built with a version of Go that creates new loop variables for each
+// iteration
+func perIterationLoopVars(pass *analysis.Pass, file *ast.File) bool {
+
+	v := pass.TypesInfo.FileVersions[file]
+
+	return v != "" && version.Compare(v, "go1.22") >= 0
+}
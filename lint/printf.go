@@ -0,0 +1,160 @@
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"unicode/utf8"
+)
+
+// PrintfArity reports calls to printf-style functions whose format string needs a different number of arguments to
+// the number the call passes, such as
+//
+//	fmt.Sprintf("Could not connect to server %s on port %d\n")
+var PrintfArity = &analysis.Analyzer{
+	Name:     "printfarity",
+	Doc:      "check that calls to printf-style functions pass as many arguments as their format string uses",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runPrintfArity,
+}
+
+// The printf-style functions that are checked and the position of the format string in their arguments
+var printfFuncs = map[string]int{
+	"fmt.Printf":                         0,
+	"fmt.Sprintf":                        0,
+	"fmt.Errorf":                         0,
+	"fmt.Fprintf":                        1,
+	"fmt.Appendf":                        1,
+	"log.Printf":                         0,
+	"log.Fatalf":                         0,
+	"log.Panicf":                         0,
+	"(*log.Logger).Printf":               0,
+	"(*log.Logger).Fatalf":               0,
+	"(*log.Logger).Panicf":               0,
+	"(*testing.common).Errorf":           0,
+	"(*testing.common).Fatalf":           0,
+	"(*testing.common).Logf":             0,
+	"(*testing.common).Skipf":            0,
+	"github.com/pkg/errors.Errorf":       0,
+	"github.com/pkg/errors.Wrapf":        1,
+	"github.com/pkg/errors.WithMessagef": 1,
+}
+
+func runPrintfArity(pass *analysis.Pass) (interface{}, error) {
+
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	in.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+
+		call := n.(*ast.CallExpr)
+
+		fn, okay := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+
+		if !okay {
+			return
+		}
+
+		index, okay := printfFuncs[fn.FullName()]
+
+		// A call like Printf(format, args...) passes an unknown number of arguments
+		if !okay || index >= len(call.Args) || call.Ellipsis.IsValid() {
+			return
+		}
+
+		format := pass.TypesInfo.Types[call.Args[index]].Value
+
+		if format == nil || format.Kind() != constant.String {
+			return
+		}
+
+		want := formatArgs(constant.StringVal(format))
+		got := len(call.Args) - index - 1
+
+		if want >= 0 && want != got {
+			pass.Reportf(call.Pos(), "%s format reads %s but the call has %s", fn.Name(), plural(want, "arg"), plural(got, "arg"))
+		}
+	})
+
+	return nil, nil
+}
+
+// formatArgs returns the number of arguments a format string uses, or -1 if it refers to arguments by index (%[1]d)
+func formatArgs(format string) int {
+
+	n := 0
+
+	for i := 0; i < len(format); i++ {
+
+		if format[i] != '%' {
+			continue
+		}
+
+		i++
+
+		// Flags
+		for i < len(format) && (format[i] == '+' || format[i] == '-' || format[i] == '#' || format[i] == ' ' || format[i] == '0') {
+			i++
+		}
+
+		if i < len(format) && format[i] == '[' {
+			return -1
+		}
+
+		// Width, which may be read from an argument
+		if i < len(format) && format[i] == '*' {
+			n++
+			i++
+		}
+
+		for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+			i++
+		}
+
+		// Precision, which may also be read from an argument
+		if i < len(format) && format[i] == '.' {
+
+			i++
+
+			if i < len(format) && format[i] == '*' {
+				n++
+				i++
+			}
+
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+
+		if i < len(format) && format[i] == '[' {
+			return -1
+		}
+
+		if i >= len(format) {
+			break
+		}
+
+		if format[i] != '%' {
+			n++
+		}
+
+		// Verbs can be any rune
+		_, size := utf8.DecodeRuneInString(format[i:])
+		i += size - 1
+	}
+
+	return n
+}
+
+func plural(n int, noun string) string {
+
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
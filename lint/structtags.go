@@ -0,0 +1,165 @@
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StructTags reports struct field tags that aren't in the conventional key:"value" form (which reflect.StructTag.Get
+// can't read, so encoding/json and friends silently ignore them) and fields in the same struct that are given the
+// same name by a json or xml tag
+var StructTags = &analysis.Analyzer{
+	Name:     "structtags",
+	Doc:      "check that struct field tags are well formed and that json and xml tags don't give two fields the same name",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runStructTags,
+}
+
+// The tag keys that name a field when it is encoded
+var namingKeys = []string{"json", "xml"}
+
+func runStructTags(pass *analysis.Pass) (interface{}, error) {
+
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	in.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+
+		// The field that first used each name, by tag key
+		seen := make(map[string]map[string]string)
+
+		for _, f := range n.(*ast.StructType).Fields.List {
+
+			if f.Tag == nil {
+				continue
+			}
+
+			tag, err := strconv.Unquote(f.Tag.Value)
+
+			if err != nil {
+				continue
+			}
+
+			if err := validateTag(tag); err != nil {
+				pass.Reportf(f.Tag.Pos(), "struct field tag %s is not of the form key:\"value\": %s", f.Tag.Value, err.Error())
+				continue
+			}
+
+			field := fieldName(f)
+
+			for _, key := range namingKeys {
+
+				name, _, _ := strings.Cut(reflect.StructTag(tag).Get(key), ",")
+
+				if name == "" || name == "-" {
+					continue
+				}
+
+				if seen[key] == nil {
+					seen[key] = make(map[string]string)
+				}
+
+				if first, okay := seen[key][name]; okay {
+					pass.Reportf(f.Tag.Pos(), "struct field %s repeats %s tag %q also used by %s", field, key, name, first)
+				} else {
+					seen[key][name] = field
+				}
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+// validateTag checks that a tag is a space separated list of key:"value" pairs, the form described in the
+// documentation for reflect.StructTag
+func validateTag(tag string) error {
+
+	for tag != "" {
+
+		tag = strings.TrimLeft(tag, " ")
+
+		if tag == "" {
+			break
+		}
+
+		i := 0
+
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+
+		if i == 0 {
+			return fmt.Errorf("missing key")
+		}
+
+		key := tag[:i]
+
+		if i+1 >= len(tag) || tag[i] != ':' {
+			return fmt.Errorf("the key %s has no value", key)
+		}
+
+		if tag[i+1] != '"' {
+			return fmt.Errorf("the value for %s is not quoted", key)
+		}
+
+		tag = tag[i+1:]
+
+		// Find the closing quote, skipping escaped characters
+		i = 1
+
+		for i < len(tag) && tag[i] != '"' {
+
+			if tag[i] == '\\' {
+				i++
+			}
+
+			i++
+		}
+
+		if i >= len(tag) {
+			return fmt.Errorf("the value for %s has no closing quote", key)
+		}
+
+		if _, err := strconv.Unquote(tag[:i+1]); err != nil {
+			return fmt.Errorf("the value for %s is not a valid string", key)
+		}
+
+		tag = tag[i+1:]
+
+		if tag != "" && tag[0] != ' ' {
+			return fmt.Errorf("the value for %s is not followed by a space", key)
+		}
+	}
+
+	return nil
+}
+
+// fieldName returns the name of a field, or the name of its type if it is embedded
+func fieldName(f *ast.Field) string {
+
+	if len(f.Names) > 0 {
+		return f.Names[0].Name
+	}
+
+	t := f.Type
+
+	if s, okay := t.(*ast.StarExpr); okay {
+		t = s.X
+	}
+
+	if s, okay := t.(*ast.SelectorExpr); okay {
+		return s.Sel.Name
+	}
+
+	if id, okay := t.(*ast.Ident); okay {
+		return id.Name
+	}
+
+	return "(embedded)"
+}
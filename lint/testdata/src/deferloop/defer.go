@@ -0,0 +1,54 @@
+package deferloop
+
+import (
+	"fmt"
+)
+
+// From functions/defer.go
+
+func loopDefer() {
+
+	for i := 0; i < 3; i++ {
+		openResource()
+		defer closeResource() // want `defer inside a loop`
+	}
+
+}
+
+func fixedLoopDefer() {
+
+	for i := 0; i < 3; i++ {
+		func() {
+			openResource()
+			defer closeResource()
+		}()
+	}
+
+}
+
+func nested(names []string) {
+
+	defer closeResource()
+
+	for _, n := range names {
+		if n != "" {
+			defer fmt.Println(n) // want `defer inside a loop`
+		}
+	}
+
+	go func() {
+		defer closeResource()
+
+		for {
+			defer closeResource() // want `defer inside a loop`
+		}
+	}()
+}
+
+func openResource() {
+	fmt.Println("Resource opened")
+}
+
+func closeResource() {
+	fmt.Println("Resource closed")
+}
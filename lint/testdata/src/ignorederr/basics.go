@@ -0,0 +1,46 @@
+package ignorederr
+
+import (
+	"errors"
+	"os"
+)
+
+// From functions/basics.go
+
+func MultiReturn() (bool, error) {
+	return false, nil
+}
+
+func CallingMultiReturnValueFunctions() {
+
+	var result bool
+
+	result, _ = MultiReturn() // want `an error is assigned to _ and never checked`
+
+	r, _ := MultiReturn() // want `an error is assigned to _ and never checked`
+
+	_, _ = result, r
+}
+
+// From errorhandling/panic.go
+
+func panicSource(i int) {
+	_ = 4 / i
+}
+
+type myError struct{}
+
+func (*myError) Error() string { return "" }
+
+func others(m map[string]error, v interface{}) {
+
+	_ = os.Remove("file")         // want `an error is assigned to _ and never checked`
+	_ = errors.New("not checked") // want `an error is assigned to _ and never checked`
+	_ = &myError{}                // want `an error is assigned to _ and never checked`
+
+	_, found := m["key"]
+	_, okay := v.(error)
+	_ = nil == v
+
+	_, _ = found, okay
+}
@@ -0,0 +1,68 @@
+package loopclosure
+
+import (
+	"fmt"
+	"sync"
+)
+
+// From concurrency/exercises/testdata
+
+func startGoroutines() {
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 100; i++ {
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			fmt.Println(i) // want `loop variable i captured by func literal in go statement`
+		}()
+	}
+
+	wg.Wait()
+}
+
+func fixed(names []string) {
+
+	var wg sync.WaitGroup
+
+	for i, n := range names {
+
+		wg.Add(1)
+
+		go func(i int, n string) {
+			defer wg.Done()
+			fmt.Println(i, n)
+		}(i, n)
+	}
+
+	for _, n := range names {
+
+		n := n
+
+		go func() {
+			fmt.Println(n)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func ranges(names []string) {
+
+	for i, n := range names {
+		go func() {
+			fmt.Println(i, n) // want `loop variable i captured` `loop variable n captured`
+		}()
+	}
+
+	for i := range 3 {
+		for _, n := range names {
+			go func() {
+				fmt.Println(i, n) // want `loop variable i captured` `loop variable n captured`
+			}()
+		}
+	}
+}
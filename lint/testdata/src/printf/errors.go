@@ -0,0 +1,38 @@
+package printf
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+)
+
+// From errorhandling/errors.go
+
+type ConnectionError struct {
+	Server string
+	Port   int
+}
+
+func (de ConnectionError) Error() string {
+	return fmt.Sprintf("Could not connect to server %s on port %d\n") // want `Sprintf format reads 2 args but the call has 0 args`
+}
+
+func (de ConnectionError) Fixed() string {
+	return fmt.Sprintf("Could not connect to server %s on port %d\n", de.Server, de.Port)
+}
+
+func others(t *testing.T, l *log.Logger, args []interface{}) {
+
+	fmt.Printf("%d%%\n", 100)
+	fmt.Printf("%d%%\n")                     // want `Printf format reads 1 arg but the call has 0 args`
+	fmt.Fprintf(os.Stdout, "%s\n", "a", "b") // want `Fprintf format reads 1 arg but the call has 2 args`
+	fmt.Printf("%*d|%-8.*f\n", 4, 1, 2, 3.14)
+	fmt.Printf("%[2]d %[1]d\n", 1, 2)
+	fmt.Printf("%v %v\n", args...)
+
+	l.Printf("%s")          // want `Printf format reads 1 arg but the call has 0 args`
+	t.Errorf("%d != %d", 1) // want `Errorf format reads 2 args but the call has 1 arg`
+
+	fmt.Println("%s")
+}
@@ -0,0 +1,23 @@
+package structtags
+
+// From essential/json.go
+
+type Target struct {
+	NumberVal   float64   `json:numberVal`   // want `struct field tag .json:numberVal. is not of the form key:"value": the value for json is not quoted`
+	BoolVal     bool      `json:boolVal`     // want `not of the form key:"value"`
+	StringVal   string    `json:stringVal`   // want `not of the form key:"value"`
+	NumArray    []float64 `json:numArray`    // want `not of the form key:"value"`
+	BoolArray   []bool    `json:boolArray`   // want `not of the form key:"value"`
+	StringArray []string  `json:stringArray` // want `not of the form key:"value"`
+	ObjectVal   *Target   `json:objectVal`   // want `not of the form key:"value"`
+	ObjectArray []Target  `json:objectArray` // want `not of the form key:"value"`
+}
+
+type Fixed struct {
+	NumberVal float64 `json:"numberVal"`
+	BoolVal   bool    `json:"boolVal,omitempty"`
+	Ignored   string  `json:"-"`
+	Other     string  `json:"-"`
+	Empty     string  `json:",omitempty"`
+	Unnamed   string  `json:",omitempty"`
+}
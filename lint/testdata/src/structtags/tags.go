@@ -0,0 +1,23 @@
+package structtags
+
+// From structures/tags.go
+
+type Person struct {
+	First  string `json:"firstname" xml:"first-name"`
+	Middle string `json:"middle" xml:"middle-name"`
+	Last   string `json:"firstname" xml:"first-name"` // want `struct field Last repeats json tag "firstname" also used by First` `struct field Last repeats xml tag "first-name" also used by First`
+	Age    int    `json:"age,string"`
+}
+
+type Custom struct {
+	MyField string `MyTag:value` // want `the value for MyTag is not quoted`
+}
+
+type Others struct {
+	A string `json:"a"xml:"a"` // want `the value for json is not followed by a space`
+	B string `json:"b`         // want `the value for json has no closing quote`
+	C string `:"c"`            // want `missing key`
+	D string `json`            // want `the key json has no value`
+	E string `json:"e" xml:"a"`
+	F string `json:"f\"quoted\""`
+}
@@ -0,0 +1,102 @@
+// Package localhttp protects the servers that learners run on their own machines, like the playground and the quiz
+// runner, from requests sent by other web sites open in the same browser.
+//
+// A page on another site can't read the responses of a server on localhost, but it can still send it requests. A
+// "simple" POST (with a Content-Type of text/plain, for example) is sent without asking the server first, and a site
+// whose name has been pointed at 127.0.0.1 (DNS rebinding) can send any request it likes. AllowJSON rejects both.
+package localhttp
+
+import (
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AllowJSON reports whether a request that changes or runs something should be served, writing an error response if
+// it shouldn't. addr is the address the server is listening on, or "" if it isn't known. The request must:
+//
+//   - have a Host that matches addr (or is a loopback or IP address, if addr is "" or doesn't name a host)
+//   - have no Origin, or come from a page served from the same host
+//   - have a Content-Type of application/json, which browsers won't send to another site without its permission
+func AllowJSON(w http.ResponseWriter, r *http.Request, addr string) bool {
+
+	if !HostMatches(r.Host, addr) {
+		http.Error(w, "requests must be sent to "+describe(addr), http.StatusForbidden)
+		return false
+	}
+
+	if origin := r.Header.Get("Origin"); origin != "" {
+
+		u, err := url.Parse(origin)
+
+		if err != nil || u.Scheme != "http" || u.Host != r.Host {
+			http.Error(w, "requests must come from a page served by this server, not "+origin, http.StatusForbidden)
+			return false
+		}
+	}
+
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		http.Error(w, "requests must have a Content-Type of application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	return true
+}
+
+// HostMatches reports whether the Host of a request matches the address a server is listening on. A server listening
+// on every interface (e.g. ":8080") accepts its port on localhost or any IP address, as IP addresses can't be pointed
+// somewhere else the way names can.
+func HostMatches(host, addr string) bool {
+
+	name, port := split(host)
+	listenName, listenPort := split(addr)
+
+	if listenPort != "" && listenPort != "0" && port != listenPort {
+		return false
+	}
+
+	if listenName == "" || isUnspecified(listenName) {
+		return name == "localhost" || net.ParseIP(name) != nil
+	}
+
+	return strings.EqualFold(name, listenName) || (isLoopback(name) && isLoopback(listenName))
+}
+
+// split splits a host and port, either of which may be missing
+func split(hostport string) (string, string) {
+
+	if host, port, err := net.SplitHostPort(hostport); err == nil {
+		return strings.Trim(host, "[]"), port
+	}
+
+	return strings.Trim(hostport, "[]"), ""
+}
+
+func isLoopback(name string) bool {
+
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(name)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func isUnspecified(name string) bool {
+
+	ip := net.ParseIP(name)
+
+	return ip != nil && ip.IsUnspecified()
+}
+
+func describe(addr string) string {
+
+	if addr == "" {
+		return "localhost"
+	}
+
+	return addr
+}
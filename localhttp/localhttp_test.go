@@ -0,0 +1,32 @@
+package localhttp
+
+import "testing"
+
+func TestHostMatches(t *testing.T) {
+
+	tests := []struct {
+		host    string
+		addr    string
+		matches bool
+	}{
+		{"localhost:8081", "localhost:8081", true},
+		{"127.0.0.1:8081", "localhost:8081", true},
+		{"[::1]:8081", "localhost:8081", true},
+		{"LOCALHOST:8081", "127.0.0.1:8081", true},
+		{"localhost:8082", "localhost:8081", false},
+		{"localhost", "localhost:8081", false},
+		{"example.com:8081", "localhost:8081", false},
+		{"example.com:8081", "example.com:8081", true},
+		{"192.168.1.2:8081", ":8081", true},
+		{"localhost:8081", "0.0.0.0:8081", true},
+		{"example.com:8081", ":8081", false},
+		{"127.0.0.1:54321", "", true},
+		{"example.com:80", "", false},
+	}
+
+	for _, test := range tests {
+		if got := HostMatches(test.host, test.addr); got != test.matches {
+			t.Errorf("Expected HostMatches(%q, %q) to be %t", test.host, test.addr, test.matches)
+		}
+	}
+}
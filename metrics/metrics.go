@@ -0,0 +1,408 @@
+// Package metrics keeps counters, gauges, histograms and timers for a running program and serves them in the
+// Prometheus text format.
+//
+// Metrics are created (or found, if they already exist) through a Registry, identified by a name and a set of labels:
+//
+//	requests := metrics.Default.Counter("http_requests_total", "Requests received", metrics.Labels{"method": "GET"})
+//
+// Looking a metric up takes a lock, so keep hold of it rather than looking it up every time it changes. Changing a
+// metric (Inc, Add, Set, Observe) never takes a lock: it is a handful of atomic operations, so it is cheap enough to do
+// on every request.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Type is the kind of a metric
+type Type string
+
+const (
+	CounterType   Type = "counter"
+	GaugeType     Type = "gauge"
+	HistogramType Type = "histogram"
+)
+
+// Labels distinguish metrics with the same name, e.g. {"method": "GET"} and {"method": "POST"}
+type Labels map[string]string
+
+// key returns a string that identifies a set of labels, regardless of the order they were added in
+func (l Labels) key() string {
+
+	names := l.names()
+	parts := make([]string, len(names))
+
+	for i, n := range names {
+		parts[i] = n + "\x00" + l[n]
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+func (l Labels) names() []string {
+
+	names := make([]string, 0, len(l))
+
+	for n := range l {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (l Labels) copy() Labels {
+
+	c := make(Labels, len(l))
+
+	for k, v := range l {
+		c[k] = v
+	}
+
+	return c
+}
+
+// A Counter is a count that only goes up, like the number of requests handled
+type Counter struct {
+	v int64
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc() {
+	atomic.AddInt64(&c.v, 1)
+}
+
+// Add adds n to the counter. n must not be negative.
+func (c *Counter) Add(n int64) {
+
+	if n < 0 {
+		panic("metrics: counters can't go down")
+	}
+
+	atomic.AddInt64(&c.v, n)
+}
+
+// Value returns the current count
+func (c *Counter) Value() int64 {
+	return atomic.LoadInt64(&c.v)
+}
+
+// A Gauge is a value that can go up and down, like the number of requests in progress
+type Gauge struct {
+	v int64
+}
+
+// Inc adds one to the gauge and returns its new value
+func (g *Gauge) Inc() int64 {
+	return atomic.AddInt64(&g.v, 1)
+}
+
+// Dec subtracts one from the gauge and returns its new value
+func (g *Gauge) Dec() int64 {
+	return atomic.AddInt64(&g.v, -1)
+}
+
+// Add adds n (which may be negative) to the gauge and returns its new value
+func (g *Gauge) Add(n int64) int64 {
+	return atomic.AddInt64(&g.v, n)
+}
+
+// Set changes the gauge's value
+func (g *Gauge) Set(n int64) {
+	atomic.StoreInt64(&g.v, n)
+}
+
+// Value returns the gauge's current value
+func (g *Gauge) Value() int64 {
+	return atomic.LoadInt64(&g.v)
+}
+
+// DefaultBuckets are the upper bounds of the buckets used by Timers, in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// A Histogram counts observations (like the size of responses) in buckets, and keeps their total
+type Histogram struct {
+	// Upper bounds, in increasing order
+	bounds []float64
+
+	// counts[i] is the number of observations <= bounds[i] but > bounds[i-1], and the last count is everything larger
+	// than the last bound
+	counts []uint64
+
+	count uint64
+
+	// A float64, stored as its bits so it can be updated atomically
+	sum uint64
+}
+
+func newHistogram(bounds []float64) *Histogram {
+
+	b := append([]float64(nil), bounds...)
+	sort.Float64s(b)
+
+	return &Histogram{bounds: b, counts: make([]uint64, len(b)+1)}
+}
+
+// Observe records a value
+func (h *Histogram) Observe(v float64) {
+
+	i := sort.SearchFloat64s(h.bounds, v)
+
+	atomic.AddUint64(&h.counts[i], 1)
+
+	for {
+
+		old := atomic.LoadUint64(&h.sum)
+
+		if atomic.CompareAndSwapUint64(&h.sum, old, math.Float64bits(math.Float64frombits(old)+v)) {
+			break
+		}
+	}
+
+	// Counted last, so a snapshot never sees more observations than there are in the buckets
+	atomic.AddUint64(&h.count, 1)
+}
+
+// A Timer is a Histogram of durations, in seconds
+type Timer struct {
+	*Histogram
+}
+
+// ObserveDuration records a duration
+func (t *Timer) ObserveDuration(d time.Duration) {
+	t.Observe(d.Seconds())
+}
+
+// Time records how long fn takes to run
+func (t *Timer) Time(fn func()) {
+
+	start := time.Now()
+	defer func() { t.ObserveDuration(time.Since(start)) }()
+
+	fn()
+}
+
+// Start returns a function that records the time since Start was called, for use with defer:
+//
+//	defer timer.Start()()
+func (t *Timer) Start() func() {
+
+	start := time.Now()
+
+	return func() {
+		t.ObserveDuration(time.Since(start))
+	}
+}
+
+// A Registry holds a program's metrics
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// A family is every metric with the same name
+type family struct {
+	name    string
+	help    string
+	typ     Type
+	metrics map[string]*metric
+}
+
+type metric struct {
+	labels Labels
+
+	// Exactly one of these is set, depending on the family's type
+	counter   *Counter
+	gauge     *Gauge
+	histogram *Histogram
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Default is the Registry used by this repository's packages and lessons
+var Default = NewRegistry()
+
+// Counter returns the counter with a name and labels, creating it if necessary
+func (r *Registry) Counter(name, help string, labels Labels) *Counter {
+	return r.get(name, help, CounterType, labels, nil).counter
+}
+
+// Gauge returns the gauge with a name and labels, creating it if necessary
+func (r *Registry) Gauge(name, help string, labels Labels) *Gauge {
+	return r.get(name, help, GaugeType, labels, nil).gauge
+}
+
+// Histogram returns the histogram with a name and labels, creating it with buckets (a list of upper bounds) if
+// necessary
+func (r *Registry) Histogram(name, help string, labels Labels, buckets []float64) *Histogram {
+	return r.get(name, help, HistogramType, labels, buckets).histogram
+}
+
+// Timer returns the timer with a name and labels, creating it with DefaultBuckets if necessary. Timers are histograms
+// of seconds, so the name should end in _seconds.
+func (r *Registry) Timer(name, help string, labels Labels) *Timer {
+	return &Timer{r.Histogram(name, help, labels, DefaultBuckets)}
+}
+
+// get finds or creates a metric. Using the same name for metrics of different types is a programming error, so it
+// panics.
+func (r *Registry) get(name, help string, typ Type, labels Labels, buckets []float64) *metric {
+
+	key := labels.key()
+
+	r.mu.RLock()
+	f := r.families[name]
+
+	if f != nil && f.typ == typ {
+		if m := f.metrics[key]; m != nil {
+			r.mu.RUnlock()
+			return m
+		}
+	}
+
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f = r.families[name]
+
+	if f == nil {
+		f = &family{name: name, help: help, typ: typ, metrics: make(map[string]*metric)}
+		r.families[name] = f
+	} else if f.typ != typ {
+		panic(fmt.Sprintf("metrics: %s is a %s, not a %s", name, f.typ, typ))
+	}
+
+	m := f.metrics[key]
+
+	if m == nil {
+
+		m = &metric{labels: labels.copy()}
+
+		switch typ {
+		case CounterType:
+			m.counter = new(Counter)
+		case GaugeType:
+			m.gauge = new(Gauge)
+		case HistogramType:
+			m.histogram = newHistogram(buckets)
+		}
+
+		f.metrics[key] = m
+	}
+
+	return m
+}
+
+// A Family is a snapshot of every metric with the same name
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Metrics []Sample
+}
+
+// A Sample is a snapshot of a single metric
+type Sample struct {
+	Labels Labels
+
+	// The value of a counter or gauge
+	Value int64
+
+	// The state of a histogram
+	Histogram *HistogramSample
+}
+
+// A HistogramSample is a snapshot of a histogram
+type HistogramSample struct {
+	Buckets []Bucket
+	Count   uint64
+	Sum     float64
+}
+
+// A Bucket is the number of observations less than or equal to an upper bound (including those in smaller buckets)
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// Snapshot returns the current value of every metric, ordered by name and then by labels
+func (r *Registry) Snapshot() []Family {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	families := make([]Family, 0, len(r.families))
+
+	for _, f := range r.families {
+
+		s := Family{Name: f.name, Help: f.help, Type: f.typ}
+
+		keys := make([]string, 0, len(f.metrics))
+
+		for k := range f.metrics {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			s.Metrics = append(s.Metrics, f.metrics[k].sample())
+		}
+
+		families = append(families, s)
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+
+	return families
+}
+
+func (m *metric) sample() Sample {
+
+	s := Sample{Labels: m.labels.copy()}
+
+	switch {
+	case m.counter != nil:
+		s.Value = m.counter.Value()
+	case m.gauge != nil:
+		s.Value = m.gauge.Value()
+	case m.histogram != nil:
+		s.Histogram = m.histogram.sample()
+	}
+
+	return s
+}
+
+func (h *Histogram) sample() *HistogramSample {
+
+	s := &HistogramSample{
+		Count: atomic.LoadUint64(&h.count),
+		Sum:   math.Float64frombits(atomic.LoadUint64(&h.sum)),
+	}
+
+	var cumulative uint64
+
+	for i, b := range h.bounds {
+		cumulative += atomic.LoadUint64(&h.counts[i])
+		s.Buckets = append(s.Buckets, Bucket{UpperBound: b, Count: cumulative})
+	}
+
+	cumulative += atomic.LoadUint64(&h.counts[len(h.bounds)])
+	s.Buckets = append(s.Buckets, Bucket{UpperBound: math.Inf(1), Count: cumulative})
+
+	return s
+}
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLabelsIdentifyMetrics(t *testing.T) {
+
+	r := NewRegistry()
+
+	get := r.Counter("requests_total", "Requests", Labels{"method": "GET", "code": "200"})
+	get.Inc()
+
+	// The same labels in a different order are the same metric
+	r.Counter("requests_total", "Requests", Labels{"code": "200", "method": "GET"}).Add(2)
+
+	post := r.Counter("requests_total", "Requests", Labels{"method": "POST", "code": "200"})
+	post.Inc()
+
+	if get.Value() != 3 || post.Value() != 1 {
+		t.Errorf("Expected 3 GETs and 1 POST, got %d and %d", get.Value(), post.Value())
+	}
+
+	g := r.Gauge("active", "Active", nil)
+
+	if g.Inc() != 1 || g.Inc() != 2 || g.Dec() != 1 {
+		t.Errorf("Unexpected gauge values")
+	}
+
+	g.Set(-5)
+
+	if v := r.Gauge("active", "Active", nil).Value(); v != -5 {
+		t.Errorf("Expected -5, got %d", v)
+	}
+}
+
+func TestTypeConflictsPanic(t *testing.T) {
+
+	r := NewRegistry()
+	r.Counter("things", "", nil)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected using a counter's name for a gauge to panic")
+		}
+	}()
+
+	r.Gauge("things", "", Labels{"a": "b"})
+}
+
+func TestHistogram(t *testing.T) {
+
+	r := NewRegistry()
+	h := r.Histogram("size_bytes", "Sizes", nil, []float64{10, 1, 100})
+
+	for _, v := range []float64{0.5, 1, 5, 50, 500} {
+		h.Observe(v)
+	}
+
+	s := r.Snapshot()[0].Metrics[0].Histogram
+
+	if s.Count != 5 || s.Sum != 556.5 {
+		t.Errorf("Expected 5 observations adding up to 556.5, got %d and %v", s.Count, s.Sum)
+	}
+
+	want := []uint64{2, 3, 4, 5}
+
+	for i, b := range s.Buckets {
+		if b.Count != want[i] {
+			t.Errorf("Expected %d observations <= %v, got %d", want[i], b.UpperBound, b.Count)
+		}
+	}
+
+	timer := r.Timer("work_seconds", "Work", nil)
+	timer.ObserveDuration(30 * time.Millisecond)
+	timer.Time(func() {})
+
+	if c := r.Snapshot()[1].Metrics[0].Histogram.Count; c != 2 {
+		t.Errorf("Expected 2 timings, got %d", c)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+
+	r := NewRegistry()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			c := r.Counter("ops_total", "", nil)
+			h := r.Histogram("latency", "", nil, []float64{1})
+
+			for j := 0; j < 1000; j++ {
+				c.Inc()
+				h.Observe(0.5)
+				r.Snapshot()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	s := r.Snapshot()
+
+	if h := s[0].Metrics[0].Histogram; h.Count != 8000 || h.Sum != 4000 {
+		t.Errorf("Expected 8000 observations adding up to 4000, got %d and %v", h.Count, h.Sum)
+	}
+
+	if v := s[1].Metrics[0].Value; v != 8000 {
+		t.Errorf("Expected 8000, got %d", v)
+	}
+}
+
+func TestHandler(t *testing.T) {
+
+	r := NewRegistry()
+
+	r.Counter("requests_total", "Requests handled,\nby path", Labels{"path": `/a"b`}).Add(3)
+	r.Gauge("active_requests", "Requests in progress", nil).Set(2)
+	r.Histogram("duration_seconds", "", Labels{"path": "/"}, []float64{0.1, 1}).Observe(0.25)
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP active_requests Requests in progress
+# TYPE active_requests gauge
+active_requests 2
+# TYPE duration_seconds histogram
+duration_seconds_bucket{path="/",le="0.1"} 0
+duration_seconds_bucket{path="/",le="1"} 1
+duration_seconds_bucket{path="/",le="+Inf"} 1
+duration_seconds_sum{path="/"} 0.25
+duration_seconds_count{path="/"} 1
+# HELP requests_total Requests handled,\nby path
+# TYPE requests_total counter
+requests_total{path="/a\"b"} 3
+`
+
+	if string(b) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, b)
+	}
+
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %s", ct)
+	}
+}
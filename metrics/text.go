@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WriteText writes every metric in the Prometheus text exposition format
+// (https://prometheus.io/docs/instrumenting/exposition_formats/)
+func (r *Registry) WriteText(w io.Writer) error {
+
+	b := bufio.NewWriter(w)
+
+	for _, f := range r.Snapshot() {
+
+		if f.Help != "" {
+			fmt.Fprintf(b, "# HELP %s %s\n", f.Name, escapeHelp(f.Help))
+		}
+
+		fmt.Fprintf(b, "# TYPE %s %s\n", f.Name, f.Type)
+
+		for _, s := range f.Metrics {
+
+			if s.Histogram == nil {
+				fmt.Fprintf(b, "%s%s %d\n", f.Name, labelText(s.Labels, "", ""), s.Value)
+				continue
+			}
+
+			for _, bucket := range s.Histogram.Buckets {
+				fmt.Fprintf(b, "%s_bucket%s %d\n", f.Name, labelText(s.Labels, "le", formatFloat(bucket.UpperBound)), bucket.Count)
+			}
+
+			fmt.Fprintf(b, "%s_sum%s %s\n", f.Name, labelText(s.Labels, "", ""), formatFloat(s.Histogram.Sum))
+			fmt.Fprintf(b, "%s_count%s %d\n", f.Name, labelText(s.Labels, "", ""), s.Histogram.Count)
+		}
+	}
+
+	return b.Flush()
+}
+
+// labelText formats labels as {name="value",...}, adding an extra label if extraName isn't empty
+func labelText(l Labels, extraName, extraValue string) string {
+
+	var parts []string
+
+	for _, n := range l.names() {
+		parts = append(parts, n+`="`+escapeLabel(l[n])+`"`)
+	}
+
+	if extraName != "" {
+		parts = append(parts, extraName+`="`+escapeLabel(extraValue)+`"`)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Handler serves the registry's metrics in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		// The response has already started, so there is nothing useful to do with an error
+		r.WriteText(w)
+	})
+}
@@ -0,0 +1,23 @@
+// Package exercises contains exercises for the output lesson (output/output.go). Replace the body of each function so
+// that it does what its comment describes, then check your answers with:
+//
+//	gotraining grade output
+package exercises
+
+// Greeting returns a greeting of the form "Hello, <name>!", built with fmt.Sprintf
+func Greeting(name string) string {
+	// TODO
+	return ""
+}
+
+// Describe returns the value and type of i in the form "<value> is a <type>", so Describe(3) returns "3 is a int"
+func Describe(i interface{}) string {
+	// TODO
+	return ""
+}
+
+// OpenError returns an error with the message "could not open <file>", created with fmt.Errorf
+func OpenError(file string) error {
+	// TODO
+	return nil
+}
@@ -0,0 +1,39 @@
+package exercises
+
+import "testing"
+
+// Hint: fmt.Sprintf works like fmt.Printf but returns the string instead of printing it.
+func TestGreeting(t *testing.T) {
+
+	result := Greeting("World")
+
+	if result != "Hello, World!" {
+		t.Errorf("Expected \"Hello, World!\" but found %q", result)
+	}
+}
+
+// Hint: %v formats any value in its default format and %T gives the name of its type.
+func TestDescribe(t *testing.T) {
+
+	if result := Describe(3); result != "3 is a int" {
+		t.Errorf("Expected \"3 is a int\" but found %q", result)
+	}
+
+	if result := Describe(true); result != "true is a bool" {
+		t.Errorf("Expected \"true is a bool\" but found %q", result)
+	}
+}
+
+// Hint: fmt.Errorf takes the same verbs as fmt.Printf and wraps the result in an error.
+func TestOpenError(t *testing.T) {
+
+	err := OpenError("a.txt")
+
+	if err == nil {
+		t.Fatalf("Expected an error but found nil")
+	}
+
+	if err.Error() != "could not open a.txt" {
+		t.Errorf("Expected \"could not open a.txt\" but found %q", err.Error())
+	}
+}
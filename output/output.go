@@ -2,11 +2,19 @@ package main
 
 import (
 	"fmt"
+	"github.com/benhalstead/gotraining/tutorial"
 	"time"
 )
 
 func main() {
 
+	tutorial.Register("output", "output").
+		Section("printing", printingExample).
+		Run()
+}
+
+func printingExample() {
+
 	// The built-in package fmt contains string formatting functions heavily based on those found in C
 	// Many of those functions output directly to stdout, so are commonly used for debugging code (but not for logging,
 	// there is a separate package for that
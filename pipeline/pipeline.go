@@ -0,0 +1,432 @@
+// Package pipeline builds chains of goroutines connected by channels, where each stage reads values from the channel
+// returned by the stage before it and sends its results on a channel of its own.
+//
+// Every stage belongs to a Pipeline, found through the context passed to it. If any stage fails, the Pipeline records
+// the error and cancels its context, so every other stage stops and closes its output. A consumer that doesn't want
+// any more values calls Stop, which does the same without an error. Either way Wait returns once every stage's
+// goroutine has exited, so nothing is left running.
+//
+//	p, ctx := pipeline.New(context.Background())
+//
+//	numbers := pipeline.Generate(ctx, func(ctx context.Context, emit func(int) bool) error {
+//
+//		for i := 1; emit(i); i++ {
+//		}
+//
+//		return nil
+//	})
+//
+//	squares := pipeline.Map(ctx, numbers, func(ctx context.Context, n int) (int, error) {
+//		return n * n, nil
+//	})
+//
+//	for s := range squares {
+//
+//		if s > 100 {
+//			p.Stop()
+//		}
+//	}
+//
+//	err := p.Wait()
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// A Pipeline tracks the goroutines of a set of stages and the first error any of them returned
+type Pipeline struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+type pipelineKey struct{}
+
+// New creates a Pipeline and the context its stages must be given. The pipeline is cancelled if parent is.
+func New(parent context.Context) (*Pipeline, context.Context) {
+
+	p := &Pipeline{parent: parent}
+
+	ctx, cancel := context.WithCancel(parent)
+
+	p.ctx = context.WithValue(ctx, pipelineKey{}, p)
+	p.cancel = cancel
+
+	return p, p.ctx
+}
+
+// from returns the Pipeline a context belongs to. Building a stage without one is a programming error (the stage's
+// errors would be lost), so it panics.
+func from(ctx context.Context) *Pipeline {
+
+	p, okay := ctx.Value(pipelineKey{}).(*Pipeline)
+
+	if !okay {
+		panic("pipeline: the context wasn't created by pipeline.New")
+	}
+
+	return p
+}
+
+// Stop cancels every stage. Values already sent may still be received, but every output is closed soon after.
+func (p *Pipeline) Stop() {
+	p.cancel()
+}
+
+// Wait waits for every stage to finish and returns the first error a stage returned. If the pipeline ended because its
+// parent context was cancelled, that context's error is returned instead. Stopping the pipeline is not an error.
+func (p *Pipeline) Wait() error {
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	return p.parent.Err()
+}
+
+// Err returns the first error a stage returned so far, if any
+func (p *Pipeline) Err() error {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+// fail records an error (if it is the first) and cancels every stage
+func (p *Pipeline) fail(err error) {
+
+	p.mu.Lock()
+
+	if p.err == nil {
+		p.err = err
+	}
+
+	p.mu.Unlock()
+
+	p.cancel()
+}
+
+// run starts a stage's goroutine, which closes out when fn returns
+func run[T any](ctx context.Context, out chan T, fn func() error) {
+
+	p := from(ctx)
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+		defer close(out)
+
+		if err := fn(); err != nil {
+			p.fail(err)
+		}
+	}()
+}
+
+// send sends v on out, giving up (and returning false) if ctx is cancelled first
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// receive reads the next value from in, returning false if in is closed or ctx is cancelled first
+func receive[T any](ctx context.Context, in <-chan T) (T, bool) {
+
+	select {
+	case v, okay := <-in:
+		return v, okay
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// Generate calls fn in a new goroutine and sends every value it emits on the returned channel. emit returns false once
+// the pipeline has been stopped, after which fn should return. The channel is closed when fn returns.
+func Generate[T any](ctx context.Context, fn func(ctx context.Context, emit func(T) bool) error) <-chan T {
+
+	out := make(chan T)
+
+	run(ctx, out, func() error {
+
+		emit := func(v T) bool {
+			return send(ctx, out, v)
+		}
+
+		return fn(ctx, emit)
+	})
+
+	return out
+}
+
+// Map sends the result of calling fn on each value from in
+func Map[In, Out any](ctx context.Context, in <-chan In, fn func(ctx context.Context, v In) (Out, error)) <-chan Out {
+
+	out := make(chan Out)
+
+	run(ctx, out, func() error {
+
+		for {
+
+			v, okay := receive(ctx, in)
+
+			if !okay {
+				return nil
+			}
+
+			r, err := fn(ctx, v)
+
+			if err != nil {
+				return err
+			}
+
+			if !send(ctx, out, r) {
+				return nil
+			}
+		}
+	})
+
+	return out
+}
+
+// Filter sends the values from in for which fn returns true
+func Filter[T any](ctx context.Context, in <-chan T, fn func(ctx context.Context, v T) (bool, error)) <-chan T {
+
+	out := make(chan T)
+
+	run(ctx, out, func() error {
+
+		for {
+
+			v, okay := receive(ctx, in)
+
+			if !okay {
+				return nil
+			}
+
+			keep, err := fn(ctx, v)
+
+			if err != nil {
+				return err
+			}
+
+			if keep && !send(ctx, out, v) {
+				return nil
+			}
+		}
+	})
+
+	return out
+}
+
+// Batch groups the values from in into slices of up to size values. If wait is more than zero, a batch is sent once
+// wait has passed since its first value arrived, even if it isn't full. Whatever is left when in closes is sent as a
+// final, smaller batch.
+func Batch[T any](ctx context.Context, in <-chan T, size int, wait time.Duration) <-chan []T {
+
+	if size < 1 {
+		panic("pipeline: batches must hold at least one value")
+	}
+
+	out := make(chan []T)
+
+	run(ctx, out, func() error {
+
+		var batch []T
+
+		// nil (so never ready) unless there is a partial batch and wait is set
+		var timeout <-chan time.Time
+		var timer *time.Timer
+
+		flush := func() bool {
+
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+
+			if len(batch) == 0 {
+				return true
+			}
+
+			b := batch
+			batch = nil
+
+			return send(ctx, out, b)
+		}
+
+		for {
+			select {
+			case v, okay := <-in:
+
+				if !okay {
+					flush()
+					return nil
+				}
+
+				batch = append(batch, v)
+
+				if len(batch) >= size {
+
+					if !flush() {
+						return nil
+					}
+
+				} else if len(batch) == 1 && wait > 0 {
+					timer = time.NewTimer(wait)
+					timeout = timer.C
+				}
+
+			case <-timeout:
+
+				if !flush() {
+					return nil
+				}
+
+			case <-ctx.Done():
+
+				if timer != nil {
+					timer.Stop()
+				}
+
+				return nil
+			}
+		}
+	})
+
+	return out
+}
+
+// FanOut starts n goroutines that each read from in, so the values are shared between n outputs (each value is sent
+// on exactly one of them, whichever is ready first). It is used to spread slow work over several goroutines, usually
+// followed by Merge to bring the results back together.
+func FanOut[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+
+	outs := make([]<-chan T, n)
+
+	for i := range outs {
+
+		out := make(chan T)
+		outs[i] = out
+
+		run(ctx, out, func() error {
+			forward(ctx, in, out)
+			return nil
+		})
+	}
+
+	return outs
+}
+
+// Merge sends every value from every input on a single channel, which is closed once all of the inputs are closed.
+// Values from different inputs are sent in no particular order.
+func Merge[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+
+	out := make(chan T)
+
+	run(ctx, out, func() error {
+
+		var wg sync.WaitGroup
+
+		for _, in := range ins {
+
+			wg.Add(1)
+
+			go func(in <-chan T) {
+				defer wg.Done()
+				forward(ctx, in, out)
+			}(in)
+		}
+
+		wg.Wait()
+
+		return nil
+	})
+
+	return out
+}
+
+// Tee sends every value from in on both of the returned channels. A value isn't read from in until both channels
+// have received the previous one, so the slower consumer sets the pace.
+func Tee[T any](ctx context.Context, in <-chan T) (<-chan T, <-chan T) {
+
+	first := make(chan T)
+	second := make(chan T)
+
+	// One stage closes both outputs. second is closed by a deferred call registered here, which runs after the stage
+	// returns but before run closes first.
+	run(ctx, first, func() error {
+
+		defer close(second)
+
+		for {
+
+			v, okay := receive(ctx, in)
+
+			if !okay {
+				return nil
+			}
+
+			// Send to whichever is ready first, then to the other one
+			a, b := first, second
+
+			for a != nil || b != nil {
+				select {
+				case a <- v:
+					a = nil
+				case b <- v:
+					b = nil
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	})
+
+	return first, second
+}
+
+// OrDone forwards the values from in until in is closed or the pipeline is stopped. It lets a stage read from a
+// channel that isn't part of the pipeline (and so might never be closed) without having to check ctx itself.
+func OrDone[T any](ctx context.Context, in <-chan T) <-chan T {
+
+	out := make(chan T)
+
+	run(ctx, out, func() error {
+		forward(ctx, in, out)
+		return nil
+	})
+
+	return out
+}
+
+// forward sends values from in to out until in is closed or ctx is cancelled
+func forward[T any](ctx context.Context, in <-chan T, out chan<- T) {
+
+	for {
+
+		v, okay := receive(ctx, in)
+
+		if !okay || !send(ctx, out, v) {
+			return
+		}
+	}
+}
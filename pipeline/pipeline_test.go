@@ -0,0 +1,263 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"github.com/benhalstead/gotraining/leak"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// count emits 1, 2, 3... up to max, or forever if max is 0
+func count(ctx context.Context, max int) <-chan int {
+
+	return Generate(ctx, func(ctx context.Context, emit func(int) bool) error {
+
+		for i := 1; max == 0 || i <= max; i++ {
+			if !emit(i) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+func collect[T any](in <-chan T) []T {
+
+	var all []T
+
+	for v := range in {
+		all = append(all, v)
+	}
+
+	return all
+}
+
+func TestMapAndFilter(t *testing.T) {
+	leak.VerifyNone(t)
+
+	p, ctx := New(context.Background())
+
+	even := Filter(ctx, count(ctx, 10), func(ctx context.Context, n int) (bool, error) {
+		return n%2 == 0, nil
+	})
+
+	squares := Map(ctx, even, func(ctx context.Context, n int) (int, error) {
+		return n * n, nil
+	})
+
+	if got := collect(squares); !reflect.DeepEqual(got, []int{4, 16, 36, 64, 100}) {
+		t.Errorf("Unexpected values %v", got)
+	}
+
+	if err := p.Wait(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestErrorsStopEveryStage(t *testing.T) {
+	leak.VerifyNone(t)
+
+	p, ctx := New(context.Background())
+	failure := errors.New("seven")
+
+	checked := Map(ctx, count(ctx, 0), func(ctx context.Context, n int) (int, error) {
+
+		if n == 7 {
+			return 0, failure
+		}
+
+		return n, nil
+	})
+
+	got := collect(OrDone(ctx, checked))
+
+	if len(got) > 6 {
+		t.Errorf("Expected no more than 6 values, got %v", got)
+	}
+
+	if err := p.Wait(); err != failure {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+
+	if p.Err() != failure {
+		t.Errorf("Expected Err to return %v, got %v", failure, p.Err())
+	}
+}
+
+func TestStoppingEarlyLeavesNothingRunning(t *testing.T) {
+	leak.VerifyNone(t)
+
+	p, ctx := New(context.Background())
+
+	doubled := Map(ctx, count(ctx, 0), func(ctx context.Context, n int) (int, error) {
+		return n * 2, nil
+	})
+
+	a, b := Tee(ctx, doubled)
+	merged := Merge(ctx, FanOut(ctx, a, 3)...)
+	batches := Batch(ctx, b, 4, time.Second)
+
+	go collect(batches)
+
+	for n := range merged {
+		if n > 100 {
+			p.Stop()
+			break
+		}
+	}
+
+	if err := p.Wait(); err != nil {
+		t.Errorf("Stopping shouldn't be an error, got %v", err)
+	}
+}
+
+func TestParentCancellation(t *testing.T) {
+	leak.VerifyNone(t)
+
+	parent, cancel := context.WithCancel(context.Background())
+	p, ctx := New(parent)
+
+	numbers := count(ctx, 0)
+	<-numbers
+
+	cancel()
+
+	collect(numbers)
+
+	if err := p.Wait(); err != context.Canceled {
+		t.Errorf("Expected the parent's error, got %v", err)
+	}
+}
+
+func TestFanOutAndMerge(t *testing.T) {
+	leak.VerifyNone(t)
+
+	p, ctx := New(context.Background())
+
+	outs := FanOut(ctx, count(ctx, 100), 4)
+
+	if len(outs) != 4 {
+		t.Fatalf("Expected 4 outputs, got %d", len(outs))
+	}
+
+	got := collect(Merge(ctx, outs...))
+	sort.Ints(got)
+
+	if len(got) != 100 || got[0] != 1 || got[99] != 100 {
+		t.Errorf("Expected each value exactly once, got %v", got)
+	}
+
+	if err := p.Wait(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestTee(t *testing.T) {
+	leak.VerifyNone(t)
+
+	p, ctx := New(context.Background())
+
+	a, b := Tee(ctx, count(ctx, 5))
+
+	var fromB []int
+	done := make(chan bool)
+
+	go func() {
+		fromB = collect(b)
+		done <- true
+	}()
+
+	fromA := collect(a)
+	<-done
+
+	expected := []int{1, 2, 3, 4, 5}
+
+	if !reflect.DeepEqual(fromA, expected) || !reflect.DeepEqual(fromB, expected) {
+		t.Errorf("Expected both outputs to get %v, got %v and %v", expected, fromA, fromB)
+	}
+
+	p.Wait()
+}
+
+func TestBatch(t *testing.T) {
+	leak.VerifyNone(t)
+
+	p, ctx := New(context.Background())
+
+	got := collect(Batch(ctx, count(ctx, 7), 3, 0))
+
+	if !reflect.DeepEqual(got, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}) {
+		t.Errorf("Unexpected batches %v", got)
+	}
+
+	p.Wait()
+
+	// A partial batch is sent once wait has passed
+	p, ctx = New(context.Background())
+
+	slow := Generate(ctx, func(ctx context.Context, emit func(int) bool) error {
+
+		emit(1)
+		emit(2)
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+
+		return nil
+	})
+
+	batches := Batch(ctx, slow, 10, 20*time.Millisecond)
+
+	select {
+	case b := <-batches:
+		if !reflect.DeepEqual(b, []int{1, 2}) {
+			t.Errorf("Unexpected batch %v", b)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Errorf("Expected a partial batch after the wait")
+	}
+
+	p.Stop()
+	p.Wait()
+}
+
+func TestOrDone(t *testing.T) {
+	leak.VerifyNone(t)
+
+	p, ctx := New(context.Background())
+
+	// Never closed, so only stopping the pipeline ends OrDone
+	external := make(chan int, 1)
+	external <- 1
+
+	forwarded := OrDone(ctx, external)
+
+	if v := <-forwarded; v != 1 {
+		t.Errorf("Expected 1, got %d", v)
+	}
+
+	p.Stop()
+
+	if _, okay := <-forwarded; okay {
+		t.Errorf("Expected the output to be closed")
+	}
+
+	p.Wait()
+}
+
+func TestContextWithoutPipeline(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic")
+		}
+	}()
+
+	OrDone(context.Background(), make(chan int))
+}
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package playground
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// limitedCommand creates a command that runs binary with its CPU time limited by the shell's ulimit
+func limitedCommand(ctx context.Context, limits Limits, binary string, args []string) *exec.Cmd {
+
+	if limits.CPUTime <= 0 {
+		return exec.CommandContext(ctx, binary, args...)
+	}
+
+	seconds := int(math.Ceil(limits.CPUTime.Seconds()))
+
+	// The shell sets the limit then replaces itself with the program, which inherits it
+	script := fmt.Sprintf(`ulimit -t %d && exec "$0" "$@"`, seconds)
+
+	return exec.CommandContext(ctx, "/bin/sh", append([]string{"-c", script, binary}, args...)...)
+}
+
+// cpuExceeded reports whether a program was stopped for using too much CPU time. Go programs ignore the SIGXCPU sent
+// when the limit is reached, so are stopped by the SIGKILL that follows.
+func cpuExceeded(ps *os.ProcessState, limit time.Duration) bool {
+
+	ws, okay := ps.Sys().(syscall.WaitStatus)
+
+	if !okay || !ws.Signaled() || (ws.Signal() != syscall.SIGXCPU && ws.Signal() != syscall.SIGKILL) {
+		return false
+	}
+
+	// The kernel's accounting is only accurate to a scheduler tick or so
+	return limit > 0 && ps.UserTime()+ps.SystemTime() >= limit*9/10
+}
@@ -0,0 +1,18 @@
+package playground
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// limitedCommand creates a command that runs binary. CPU time is not limited on Windows, so programs are only stopped
+// by the RunTimeout.
+func limitedCommand(ctx context.Context, limits Limits, binary string, args []string) *exec.Cmd {
+	return exec.CommandContext(ctx, binary, args...)
+}
+
+func cpuExceeded(ps *os.ProcessState, limit time.Duration) bool {
+	return false
+}
@@ -0,0 +1,120 @@
+package playground
+
+// page is the playground's user interface. It loads a lesson into an editor, posts it to /run and appends each
+// Event to the output as it arrives.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Go training playground</title>
+<style>
+body { margin: 1em; font-family: sans-serif; }
+#bar { margin-bottom: 0.5em; }
+#source { width: 100%; height: 55vh; font-family: Menlo, Consolas, monospace; font-size: 0.9em; tab-size: 4; }
+#output { height: 30vh; overflow-y: auto; background: #f6f8fa; padding: 0.5em; margin: 0.5em 0 0 0; white-space: pre-wrap; }
+.stderr, .build, .error { color: #b00020; }
+.limit, .exit { color: #666; font-style: italic; }
+</style>
+</head>
+<body>
+<div id="bar">
+<select id="lesson"></select>
+<label>Sections <input id="args" placeholder="all" size="24"></label>
+<button id="run">Run</button>
+<button id="reset">Reset</button>
+</div>
+<textarea id="source" spellcheck="false"></textarea>
+<pre id="output"></pre>
+<script>
+const lesson = document.getElementById("lesson");
+const source = document.getElementById("source");
+const output = document.getElementById("output");
+const run = document.getElementById("run");
+
+async function load() {
+	const res = await fetch("source?lesson=" + encodeURIComponent(lesson.value));
+	source.value = await res.text();
+	output.textContent = "";
+}
+
+function show(e) {
+	const span = document.createElement("span");
+	span.className = e.kind;
+
+	switch (e.kind) {
+	case "exit":
+		span.textContent = "\nProgram exited" + (e.code >= 0 ? " with code " + e.code : "") + "\n";
+		break;
+	case "limit":
+		span.textContent = "\n" + e.data + "\n";
+		break;
+	default:
+		span.textContent = e.data;
+	}
+
+	output.appendChild(span);
+	output.scrollTop = output.scrollHeight;
+}
+
+async function execute() {
+	run.disabled = true;
+	output.textContent = "";
+
+	const args = document.getElementById("args").value.split(/\s+/).filter(a => a != "");
+	const res = await fetch("run", {method: "POST", headers: {"Content-Type": "application/json"}, body: JSON.stringify({source: source.value, args: args})});
+
+	if (!res.ok) {
+		show({kind: "error", data: await res.text()});
+		run.disabled = false;
+		return;
+	}
+
+	const reader = res.body.getReader();
+	const decoder = new TextDecoder();
+	let buffered = "";
+
+	for (;;) {
+		const {done, value} = await reader.read();
+
+		if (done) {
+			break;
+		}
+
+		buffered += decoder.decode(value, {stream: true});
+
+		let i;
+
+		while ((i = buffered.indexOf("\n")) >= 0) {
+			const line = buffered.slice(0, i);
+			buffered = buffered.slice(i + 1);
+
+			if (line != "") {
+				show(JSON.parse(line));
+			}
+		}
+	}
+
+	run.disabled = false;
+}
+
+fetch("lessons").then(res => res.json()).then(ids => {
+	for (const id of ids) {
+		lesson.add(new Option(id, id));
+	}
+
+	const wanted = new URLSearchParams(location.search).get("lesson");
+
+	if (wanted) {
+		lesson.value = wanted;
+	}
+
+	load();
+});
+
+lesson.addEventListener("change", load);
+document.getElementById("reset").addEventListener("click", load);
+run.addEventListener("click", execute);
+</script>
+</body>
+</html>
+`
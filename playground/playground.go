@@ -0,0 +1,311 @@
+// Package playground builds and runs Go programs submitted from a browser, so learners can change a lesson and see
+// what happens without leaving the page.
+//
+// Each program is copied into a new temporary module alongside the packages in this repository that it imports (so
+// lessons that use them build unchanged), compiled offline with the local Go toolchain and run with limits on how long it can take,
+// how much CPU it can use and how much it can print. Everything the program writes to stdout and stderr is streamed
+// back as it happens.
+//
+// The playground runs whatever code it is sent with the same permissions as the user running it, so it only ever
+// listens on the loopback interface unless told otherwise.
+package playground
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/benhalstead/gotraining/deps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits restrict the resources a program may use
+type Limits struct {
+	// How long compiling a program may take
+	BuildTimeout time.Duration
+
+	// How long a program may run for (real time)
+	RunTimeout time.Duration
+
+	// How much CPU time a program may use. Only enforced on Unix-like systems.
+	CPUTime time.Duration
+
+	// The most a program may write to stdout and stderr combined, in bytes
+	MaxOutput int
+}
+
+// DefaultLimits are suitable for the lessons in this repository, the slowest of which take a few seconds
+var DefaultLimits = Limits{
+	BuildTimeout: 30 * time.Second,
+	RunTimeout:   10 * time.Second,
+	CPUTime:      5 * time.Second,
+	MaxOutput:    64 * 1024,
+}
+
+// EventKind identifies what an Event describes
+type EventKind string
+
+const (
+	// Output from the compiler when a program doesn't build
+	KindBuild EventKind = "build"
+
+	// Something the program wrote to stdout or stderr
+	KindStdout EventKind = "stdout"
+	KindStderr EventKind = "stderr"
+
+	// The program hit one of its Limits and was stopped
+	KindLimit EventKind = "limit"
+
+	// Something went wrong in the playground itself
+	KindError EventKind = "error"
+
+	// The program finished. Always the last Event for a run.
+	KindExit EventKind = "exit"
+)
+
+// An Event is one step in building and running a program
+type Event struct {
+	Kind EventKind `json:"kind"`
+	Data string    `json:"data,omitempty"`
+
+	// The program's exit code, for KindExit. -1 if it was stopped or never started.
+	Code int `json:"code"`
+}
+
+// A Playground builds and runs programs
+type Playground struct {
+	root   string
+	limits Limits
+
+	// Limits how many programs are built or run at once
+	slots chan bool
+
+	// The address the playground is listening on, if it was started with ListenAndServe
+	addr string
+}
+
+// New creates a Playground for the repository in root that allows up to concurrent programs to be built or run at
+// once
+func New(root string, limits Limits, concurrent int) *Playground {
+
+	if concurrent < 1 {
+		concurrent = 1
+	}
+
+	return &Playground{
+		root:   root,
+		limits: limits,
+		slots:  make(chan bool, concurrent),
+	}
+}
+
+// ErrBusy is returned by Run if ctx ends while waiting for another program to finish
+var ErrBusy = errors.New("the playground is busy running other programs")
+
+// Run builds source (a complete main package in a single file) and runs it with args, calling send with each Event as
+// it happens. send is never called concurrently. The error is only non-nil if something went wrong with the
+// playground itself: programs that don't build or that fail are reported through Events.
+func (p *Playground) Run(ctx context.Context, source []byte, args []string, send func(Event)) error {
+
+	select {
+	case p.slots <- true:
+		defer func() { <-p.slots }()
+	case <-ctx.Done():
+		return ErrBusy
+	}
+
+	dir, err := os.MkdirTemp("", "gotraining-playground-")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(dir)
+
+	if err := p.prepare(dir, source); err != nil {
+		return err
+	}
+
+	binary, built, err := p.build(ctx, dir)
+
+	if err != nil {
+		return err
+	}
+
+	if !built.ok {
+		send(Event{Kind: KindBuild, Data: built.output})
+		send(Event{Kind: KindExit, Code: -1})
+		return nil
+	}
+
+	return p.run(ctx, binary, args, send)
+}
+
+// prepare creates a module in dir containing the program and a copy of each package in this repository it imports
+func (p *Playground) prepare(dir string, source []byte) error {
+
+	if err := deps.WriteModule(dir); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "cmd", "playground"), 0755); err != nil {
+		return err
+	}
+
+	main := filepath.Join(dir, "cmd", "playground", "main.go")
+
+	if err := os.WriteFile(main, source, 0644); err != nil {
+		return err
+	}
+
+	return deps.Copy(p.root, dir, main)
+}
+
+type buildResult struct {
+	ok     bool
+	output string
+}
+
+func (p *Playground) build(ctx context.Context, dir string) (string, buildResult, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, p.limits.BuildTimeout)
+	defer cancel()
+
+	binary := filepath.Join(dir, "program")
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", binary, "./cmd/playground")
+	cmd.Dir = dir
+
+	// Make sure nothing is downloaded and nothing outside the temporary module affects the build
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off", "GO111MODULE=on", "GOTOOLCHAIN=local")
+
+	out, err := cmd.CombinedOutput()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", buildResult{output: fmt.Sprintf("building took longer than %s", p.limits.BuildTimeout)}, nil
+	}
+
+	if _, okay := err.(*exec.ExitError); okay {
+		// Paths in the compiler's output are relative to the temporary module
+		return "", buildResult{output: strings.ReplaceAll(string(out), "cmd/playground/", "")}, nil
+	} else if err != nil {
+		return "", buildResult{}, err
+	}
+
+	return binary, buildResult{ok: true}, nil
+}
+
+func (p *Playground) run(ctx context.Context, binary string, args []string, send func(Event)) error {
+
+	ctx, cancel := context.WithTimeout(ctx, p.limits.RunTimeout)
+	defer cancel()
+
+	s := &stream{send: send, remaining: p.limits.MaxOutput, stop: cancel}
+
+	cmd := limitedCommand(ctx, p.limits, binary, args)
+	cmd.Dir = filepath.Dir(binary)
+	cmd.Stdout = &streamWriter{stream: s, kind: KindStdout}
+	cmd.Stderr = &streamWriter{stream: s, kind: KindStderr}
+
+	// Don't wait forever for output from any processes the program started itself
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+
+	if cmd.ProcessState == nil {
+		return err
+	}
+
+	switch {
+	case s.exceeded:
+		s.emit(Event{Kind: KindLimit, Data: fmt.Sprintf("the program printed more than %d bytes and was stopped", p.limits.MaxOutput)})
+	case ctx.Err() == context.DeadlineExceeded:
+		s.emit(Event{Kind: KindLimit, Data: fmt.Sprintf("the program ran for longer than %s and was stopped", p.limits.RunTimeout)})
+	case cpuExceeded(cmd.ProcessState, p.limits.CPUTime):
+		s.emit(Event{Kind: KindLimit, Data: fmt.Sprintf("the program used more than %s of CPU time and was stopped", p.limits.CPUTime)})
+	}
+
+	s.emit(Event{Kind: KindExit, Code: cmd.ProcessState.ExitCode()})
+
+	return nil
+}
+
+// stream sends output from a program's stdout and stderr as Events, stopping the program if it prints too much
+type stream struct {
+	mu        sync.Mutex
+	send      func(Event)
+	remaining int
+	exceeded  bool
+	stop      func()
+}
+
+func (s *stream) emit(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.send(e)
+}
+
+func (s *stream) write(kind EventKind, b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.exceeded {
+		return
+	}
+
+	if len(b) > s.remaining {
+		b = b[:s.remaining]
+		s.exceeded = true
+		s.stop()
+	}
+
+	s.remaining -= len(b)
+
+	if len(b) > 0 {
+		s.send(Event{Kind: kind, Data: string(bytes.ToValidUTF8(b, []byte("\uFFFD")))})
+	}
+}
+
+// streamWriter passes everything written to one of a program's outputs to a stream, holding back any UTF-8 sequence
+// split between writes until the rest of it arrives
+type streamWriter struct {
+	stream  *stream
+	kind    EventKind
+	pending []byte
+}
+
+func (w *streamWriter) Write(b []byte) (int, error) {
+
+	data := append(w.pending, b...)
+	n := completeRunes(data)
+
+	w.stream.write(w.kind, data[:n])
+	w.pending = append([]byte(nil), data[n:]...)
+
+	// Output beyond the limit is thrown away rather than reported as an error, as the program is being stopped anyway
+	return len(b), nil
+}
+
+// completeRunes returns the length of the longest prefix of b that doesn't end part way through a UTF-8 sequence
+func completeRunes(b []byte) int {
+
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+
+			if utf8.FullRune(b[i:]) {
+				return len(b)
+			}
+
+			return i
+		}
+	}
+
+	return len(b)
+}
@@ -0,0 +1,190 @@
+package playground
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The repository root, relative to this package
+const root = ".."
+
+func run(t *testing.T, limits Limits, source string, args ...string) []Event {
+
+	if testing.Short() {
+		t.Skip("building programs takes several seconds")
+	}
+
+	var events []Event
+
+	err := New(root, limits, 1).Run(context.Background(), []byte(source), args, func(e Event) {
+		events = append(events, e)
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) == 0 || events[len(events)-1].Kind != KindExit {
+		t.Fatalf("Expected the last event to be an exit, got %+v", events)
+	}
+
+	return events
+}
+
+func output(events []Event, kind EventKind) string {
+
+	var b strings.Builder
+
+	for _, e := range events {
+		if e.Kind == kind {
+			b.WriteString(e.Data)
+		}
+	}
+
+	return b.String()
+}
+
+func TestLessonRuns(t *testing.T) {
+
+	p := New(root, DefaultLimits, 1)
+
+	ids := p.Lessons()
+
+	if len(ids) == 0 || ids[0] != "output/output" {
+		t.Fatalf("Expected lessons to start with output/output, got %v", ids)
+	}
+
+	b, err := os.ReadFile(p.lessonFile("functions/closures"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := strings.Replace(string(b), "pw(4, 2)", "pw(2, 10)", 1)
+	events := run(t, DefaultLimits, src, "closures")
+
+	if got := output(events, KindStdout); !strings.Contains(got, "1024\n") {
+		t.Errorf("Expected the edited lesson to print 1024, got:\n%s", got)
+	}
+
+	if code := events[len(events)-1].Code; code != 0 {
+		t.Errorf("Expected exit code 0, got %d", code)
+	}
+}
+
+func TestBuildErrorsAreReported(t *testing.T) {
+
+	events := run(t, DefaultLimits, "package main\n\nfunc main() {\n\tx := 1\n}\n")
+
+	if got := output(events, KindBuild); !strings.Contains(got, "main.go:4:2") || !strings.Contains(got, "declared and not used") {
+		t.Errorf("Expected the compiler error, got:\n%s", got)
+	}
+
+	if code := events[len(events)-1].Code; code != -1 {
+		t.Errorf("Expected exit code -1 for a program that didn't build, got %d", code)
+	}
+}
+
+func TestLimits(t *testing.T) {
+
+	limits := DefaultLimits
+	limits.RunTimeout = time.Second
+	limits.CPUTime = 0
+	limits.MaxOutput = 100
+
+	events := run(t, limits, "package main\n\nimport \"time\"\n\nfunc main() {\n\ttime.Sleep(time.Minute)\n}\n")
+
+	if got := output(events, KindLimit); !strings.Contains(got, "longer than 1s") {
+		t.Errorf("Expected the program to be stopped for taking too long, got %+v", events)
+	}
+
+	events = run(t, limits, "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfor {\n\t\tfmt.Println(\"Tick\")\n\t}\n}\n")
+
+	if got := output(events, KindStdout); len(got) != 100 {
+		t.Errorf("Expected exactly 100 bytes of output, got %d", len(got))
+	}
+
+	if got := output(events, KindLimit); !strings.Contains(got, "more than 100 bytes") {
+		t.Errorf("Expected the program to be stopped for printing too much, got %+v", events)
+	}
+}
+
+func TestCPULimit(t *testing.T) {
+
+	if runtime.GOOS == "windows" {
+		t.Skip("CPU time is not limited on Windows")
+	}
+
+	limits := DefaultLimits
+	limits.CPUTime = time.Second
+
+	events := run(t, limits, "package main\n\nfunc main() {\n\tfor {\n\t}\n}\n")
+
+	if got := output(events, KindLimit); !strings.Contains(got, "more than 1s of CPU time") {
+		t.Errorf("Expected the program to be stopped for using too much CPU, got %+v", events)
+	}
+}
+
+func TestCompleteRunes(t *testing.T) {
+
+	b := []byte("Go ✓")
+
+	for i := 0; i <= len(b); i++ {
+
+		want := i
+
+		if i == len(b)-1 || i == len(b)-2 {
+			// Part way through ✓, which is three bytes
+			want = len(b) - 3
+		}
+
+		if got := completeRunes(b[:i]); got != want {
+			t.Errorf("completeRunes of %d bytes: expected %d, got %d", i, want, got)
+		}
+	}
+}
+
+func TestRunRejectsRequestsFromOtherSites(t *testing.T) {
+
+	p := New(root, DefaultLimits, 1)
+	p.addr = "localhost:8081"
+
+	tests := []struct {
+		name        string
+		host        string
+		origin      string
+		contentType string
+		status      int
+	}{
+		{"a form or no-cors fetch", "localhost:8081", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"another site", "localhost:8081", "http://example.com", "application/json", http.StatusForbidden},
+		{"DNS rebinding", "example.com:8081", "http://example.com:8081", "application/json", http.StatusForbidden},
+		// Gets as far as decoding the (empty) request
+		{"the playground's page", "localhost:8081", "http://localhost:8081", "application/json", http.StatusBadRequest},
+		{"curl", "127.0.0.1:8081", "", "application/json; charset=utf-8", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+
+		r := httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(""))
+		r.Host = test.host
+		r.Header.Set("Content-Type", test.contentType)
+
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("Expected status %d for %s, got %d: %s", test.status, test.name, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package playground
+
+import (
+	"encoding/json"
+	"github.com/benhalstead/gotraining/localhttp"
+	"github.com/benhalstead/gotraining/tutorial"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// The most source code a single request may contain
+const maxSource = 1 << 20
+
+// A Request asks the playground to run a program
+type Request struct {
+	Source string   `json:"source"`
+	Args   []string `json:"args"`
+}
+
+// ListenAndServe serves the playground on addr. Programs are only run for requests sent to addr by the playground's
+// own page.
+func (p *Playground) ListenAndServe(addr string) error {
+
+	p.addr = addr
+
+	return http.ListenAndServe(addr, p)
+}
+
+// ServeHTTP serves the playground:
+//
+//	GET  /                          the page learners use to edit and run programs
+//	GET  /lessons                   a JSON array of the IDs of lessons that can be used as templates
+//	GET  /source?lesson=<id>        the source of a lesson
+//	POST /run                       runs the program in a JSON Request, streaming back Events as NDJSON
+//
+// Requests to /run are rejected unless they are JSON and come from the playground's own page (see localhttp)
+func (p *Playground) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	case "/lessons":
+		p.serveLessons(w)
+	case "/source":
+		p.serveSource(w, r)
+	case "/run":
+		p.serveRun(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Lessons returns the ID of every lesson that is a program, in reading order
+func (p *Playground) Lessons() []string {
+
+	var ids []string
+
+	for _, id := range tutorial.LessonIDs() {
+
+		f, err := parser.ParseFile(token.NewFileSet(), p.lessonFile(id), nil, parser.PackageClauseOnly)
+
+		if err == nil && f.Name.Name == "main" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func (p *Playground) lessonFile(id string) string {
+	return filepath.Join(p.root, filepath.FromSlash(id+".go"))
+}
+
+func (p *Playground) serveLessons(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.Lessons())
+}
+
+func (p *Playground) serveSource(w http.ResponseWriter, r *http.Request) {
+
+	id := r.URL.Query().Get("lesson")
+
+	// Only lessons can be read, so the request can't name any other file
+	for _, l := range p.Lessons() {
+
+		if l != id {
+			continue
+		}
+
+		b, err := os.ReadFile(p.lessonFile(id))
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(b)
+
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+func (p *Playground) serveRun(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "use POST to run a program", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !localhttp.AllowJSON(w, r, p.addr) {
+		return
+	}
+
+	var req Request
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSource)).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	send := func(e Event) {
+
+		enc.Encode(e)
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if err := p.Run(r.Context(), []byte(req.Source), req.Args, send); err != nil {
+		send(Event{Kind: KindError, Data: err.Error()})
+		send(Event{Kind: KindExit, Code: -1})
+	}
+}
@@ -0,0 +1,295 @@
+// Package progress records how far each learner has got through the lessons and exercises in this repository.
+//
+// Progress is kept in a single JSON file, by default progress.json in a gotraining directory under the user's
+// configuration directory (see os.UserConfigDir). A file can hold the progress of any number of learners, so a
+// shared machine can be used by a whole group.
+package progress
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Learner is everything recorded about one person
+type Learner struct {
+	Name string `json:"name"`
+
+	// When each lesson (by ID, e.g. concurrency/channels) was completed
+	Lessons map[string]time.Time `json:"lessons"`
+
+	// The most recent result for each exercise, keyed by topic/exercise (e.g. functions/Sum)
+	Exercises map[string]ExerciseResult `json:"exercises"`
+
+	// Scores for the quiz on each lesson, keyed by lesson ID
+	Quizzes map[string]QuizResult `json:"quizzes,omitempty"`
+}
+
+// An ExerciseResult is the outcome of the most recent attempt at an exercise
+type ExerciseResult struct {
+	Passed   bool      `json:"passed"`
+	Attempts int       `json:"attempts"`
+	Last     time.Time `json:"last"`
+
+	// When the exercise was first passed (zero if it never has been)
+	FirstPassed time.Time `json:"firstPassed,omitempty"`
+}
+
+// A QuizResult records a learner's attempts at the quiz on a lesson
+type QuizResult struct {
+	Score    int       `json:"score"`
+	Total    int       `json:"total"`
+	Best     int       `json:"best"`
+	Attempts int       `json:"attempts"`
+	Last     time.Time `json:"last"`
+}
+
+// Completed reports whether the learner has completed the lesson with the supplied ID
+func (l Learner) Completed(id string) bool {
+	_, okay := l.Lessons[id]
+	return okay
+}
+
+// Next returns the first lesson in order that the learner hasn't completed. The bool is false if every lesson has
+// been completed.
+func (l Learner) Next(order []string) (string, bool) {
+
+	for _, id := range order {
+		if !l.Completed(id) {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// ExerciseKey returns the key used in Learner.Exercises for an exercise
+func ExerciseKey(topic, exercise string) string {
+	return topic + "/" + exercise
+}
+
+type file struct {
+	Learners map[string]*Learner `json:"learners"`
+}
+
+// A Store is a progress file that has been loaded into memory. Every change is written back to the file immediately.
+// A Store is goroutine-safe, but two processes updating the same file at once may lose each other's changes.
+type Store struct {
+	mu   sync.Mutex
+	path string
+	data file
+}
+
+// DefaultPath returns the location of the progress file used when no other is specified
+func DefaultPath() (string, error) {
+
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "gotraining", "progress.json"), nil
+}
+
+// Open loads the progress file at path. A file that doesn't exist yet is treated as empty and will be created by the
+// first change.
+func Open(path string) (*Store, error) {
+
+	s := &Store{
+		path: path,
+		data: file{Learners: make(map[string]*Learner)},
+	}
+
+	b, err := os.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &s.data); err != nil {
+		return nil, err
+	}
+
+	if s.data.Learners == nil {
+		s.data.Learners = make(map[string]*Learner)
+	}
+
+	return s, nil
+}
+
+// Learner returns a copy of the progress recorded for the named learner. A learner with no recorded progress is
+// returned with empty maps.
+func (s *Store) Learner(name string) Learner {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.find(name)
+
+	c := Learner{
+		Name:      l.Name,
+		Lessons:   make(map[string]time.Time, len(l.Lessons)),
+		Exercises: make(map[string]ExerciseResult, len(l.Exercises)),
+		Quizzes:   make(map[string]QuizResult, len(l.Quizzes)),
+	}
+
+	for k, v := range l.Lessons {
+		c.Lessons[k] = v
+	}
+
+	for k, v := range l.Exercises {
+		c.Exercises[k] = v
+	}
+
+	for k, v := range l.Quizzes {
+		c.Quizzes[k] = v
+	}
+
+	return c
+}
+
+// Learners returns the names of everyone with recorded progress, in alphabetical order
+func (s *Store) Learners() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+
+	for n := range s.data.Learners {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// CompleteLesson records that the learner completed a lesson at the supplied time. Completing a lesson again does not
+// change the time it was first completed.
+func (s *Store) CompleteLesson(learner, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.find(learner)
+
+	if _, okay := l.Lessons[id]; !okay {
+		l.Lessons[id] = at
+	}
+
+	s.data.Learners[learner] = l
+
+	return s.save()
+}
+
+// RecordExercise records the result of an attempt at an exercise
+func (s *Store) RecordExercise(learner, topic, exercise string, passed bool, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.find(learner)
+	k := ExerciseKey(topic, exercise)
+
+	r := l.Exercises[k]
+	r.Passed = passed
+	r.Attempts++
+	r.Last = at
+
+	if passed && r.FirstPassed.IsZero() {
+		r.FirstPassed = at
+	}
+
+	l.Exercises[k] = r
+	s.data.Learners[learner] = l
+
+	return s.save()
+}
+
+// RecordQuiz records the score from an attempt at the quiz on a lesson
+func (s *Store) RecordQuiz(learner, lesson string, score, total int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.find(learner)
+
+	r := l.Quizzes[lesson]
+	r.Score = score
+	r.Total = total
+	r.Attempts++
+	r.Last = at
+
+	if score > r.Best {
+		r.Best = score
+	}
+
+	l.Quizzes[lesson] = r
+	s.data.Learners[learner] = l
+
+	return s.save()
+}
+
+// Reset removes everything recorded for the learner
+func (s *Store) Reset(learner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data.Learners, learner)
+
+	return s.save()
+}
+
+// find returns the learner's record, creating (but not storing) an empty one if necessary. Must be called with the
+// lock held.
+func (s *Store) find(name string) *Learner {
+
+	if l, okay := s.data.Learners[name]; okay {
+
+		if l.Lessons == nil {
+			l.Lessons = make(map[string]time.Time)
+		}
+
+		if l.Exercises == nil {
+			l.Exercises = make(map[string]ExerciseResult)
+		}
+
+		if l.Quizzes == nil {
+			l.Quizzes = make(map[string]QuizResult)
+		}
+
+		return l
+	}
+
+	return &Learner{
+		Name:      name,
+		Lessons:   make(map[string]time.Time),
+		Exercises: make(map[string]ExerciseResult),
+		Quizzes:   make(map[string]QuizResult),
+	}
+}
+
+// save writes the store to a temporary file and renames it over the real one, so a crash can't leave a half-written
+// file behind. Must be called with the lock held.
+func (s *Store) save() error {
+
+	b, err := json.MarshalIndent(s.data, "", "\t")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,175 @@
+package progress
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	first  = time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	second = first.Add(time.Hour)
+)
+
+// open opens a store in a new temporary directory
+func open(t *testing.T) (*Store, string) {
+
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "progress.json")
+
+	s, err := Open(path)
+
+	if err != nil {
+		t.Fatalf("Unexpected error opening %s: %v", path, err)
+	}
+
+	return s, path
+}
+
+// reopen loads the store again from disk, to make sure everything was written
+func reopen(t *testing.T, path string) *Store {
+
+	t.Helper()
+
+	s, err := Open(path)
+
+	if err != nil {
+		t.Fatalf("Unexpected error reopening %s: %v", path, err)
+	}
+
+	return s
+}
+
+func check(t *testing.T, err error) {
+
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
+
+func TestCompleteLesson(t *testing.T) {
+
+	s, path := open(t)
+
+	check(t, s.CompleteLesson("ann", "output/output", first))
+	check(t, s.CompleteLesson("ann", "output/output", second))
+	check(t, s.CompleteLesson("bob", "variablestypes/variables", first))
+
+	s = reopen(t, path)
+
+	if l := s.Learners(); len(l) != 2 || l[0] != "ann" || l[1] != "bob" {
+		t.Errorf("Expected learners [ann bob], got %v", l)
+	}
+
+	if at := s.Learner("ann").Lessons["output/output"]; !at.Equal(first) {
+		t.Errorf("Completing a lesson again should not change when it was first completed, got %v", at)
+	}
+
+	if s.Learner("ann").Completed("variablestypes/variables") || !s.Learner("bob").Completed("variablestypes/variables") {
+		t.Errorf("Expected only bob to have completed variablestypes/variables")
+	}
+}
+
+func TestRecordExercise(t *testing.T) {
+
+	s, path := open(t)
+
+	check(t, s.RecordExercise("ann", "functions", "Sum", false, first))
+	check(t, s.RecordExercise("ann", "functions", "Sum", true, second))
+	check(t, s.RecordExercise("ann", "functions", "Sum", false, second.Add(time.Hour)))
+
+	r := reopen(t, path).Learner("ann").Exercises[ExerciseKey("functions", "Sum")]
+
+	// The result is the most recent attempt's, but failing after passing doesn't change when it was first passed
+	if r.Passed || r.Attempts != 3 || !r.Last.Equal(second.Add(time.Hour)) || !r.FirstPassed.Equal(second) {
+		t.Errorf("Unexpected exercise result %+v", r)
+	}
+}
+
+func TestRecordQuiz(t *testing.T) {
+
+	s, path := open(t)
+
+	check(t, s.RecordQuiz("ann", "structures/maps", 3, 4, first))
+	check(t, s.RecordQuiz("ann", "structures/maps", 2, 4, second))
+
+	q := reopen(t, path).Learner("ann").Quizzes["structures/maps"]
+
+	if q.Score != 2 || q.Best != 3 || q.Total != 4 || q.Attempts != 2 || !q.Last.Equal(second) {
+		t.Errorf("Unexpected quiz result %+v", q)
+	}
+}
+
+func TestNext(t *testing.T) {
+
+	s, _ := open(t)
+
+	order := []string{"output/output", "variablestypes/variables"}
+
+	if next, okay := s.Learner("ann").Next(order); !okay || next != "output/output" {
+		t.Errorf("Expected a new learner to start with output/output, got %s", next)
+	}
+
+	check(t, s.CompleteLesson("ann", "output/output", first))
+
+	if next, okay := s.Learner("ann").Next(order); !okay || next != "variablestypes/variables" {
+		t.Errorf("Expected the next lesson to be variablestypes/variables, got %s", next)
+	}
+
+	check(t, s.CompleteLesson("ann", "variablestypes/variables", first))
+
+	if next, okay := s.Learner("ann").Next(order); okay {
+		t.Errorf("Expected every lesson to be completed, got %s", next)
+	}
+}
+
+func TestReset(t *testing.T) {
+
+	s, path := open(t)
+
+	check(t, s.CompleteLesson("ann", "output/output", first))
+	check(t, s.RecordExercise("ann", "functions", "Sum", true, first))
+	check(t, s.RecordQuiz("ann", "structures/maps", 3, 4, first))
+	check(t, s.CompleteLesson("bob", "variablestypes/variables", first))
+
+	check(t, s.Reset("ann"))
+
+	s = reopen(t, path)
+
+	if ann := s.Learner("ann"); len(ann.Lessons) != 0 || len(ann.Exercises) != 0 || len(ann.Quizzes) != 0 {
+		t.Errorf("Expected no progress after a reset, got %+v", ann)
+	}
+
+	if !s.Learner("bob").Completed("variablestypes/variables") {
+		t.Errorf("Resetting one learner should not affect another")
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "progress.json")
+
+	check(t, os.WriteFile(path, []byte("{"), 0600))
+
+	if _, err := Open(path); err == nil {
+		t.Errorf("Expected an error opening a file that isn't JSON")
+	}
+
+	s, err := Open(filepath.Join(dir, "config", "progress.json"))
+
+	if err != nil {
+		t.Fatalf("Unexpected error opening a file that doesn't exist yet %v", err)
+	}
+
+	// The progress file can't be written inside something that isn't a directory
+	check(t, os.WriteFile(filepath.Join(dir, "config"), nil, 0600))
+
+	if err := s.CompleteLesson("ann", "output/output", first); err == nil {
+		t.Errorf("Expected an error saving to a path that can't be written")
+	}
+}
@@ -0,0 +1,473 @@
+// Package pubsub is an in-process publish/subscribe broker: goroutines publish messages to topics, and every
+// subscriber whose pattern matches a message's topic receives it on its own channel.
+//
+// Topics are made of dot-separated words, like "orders.created". A pattern can use * to match exactly one word and >
+// (at the end only) to match one or more words, so "orders.*" matches "orders.created" but not "orders.created.eu",
+// and "orders.>" matches both.
+//
+// Each subscription has a buffer, so a slow subscriber doesn't hold up the publisher (or other subscribers) until its
+// buffer is full. What happens then depends on the subscription's Policy.
+//
+//	b := pubsub.New()
+//
+//	s, _ := b.Subscribe("orders.*", pubsub.Options{Buffer: 100, Policy: pubsub.DropOldest})
+//
+//	go func() {
+//		for m := range s.C() {
+//			fmt.Println(m.Topic, m.Payload)
+//		}
+//	}()
+//
+//	b.Publish(ctx, "orders.created", order)
+//
+//	b.Shutdown(ctx) // delivers what has already been published, then closes every subscription's channel
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy is what happens when a message is published to a subscription whose buffer is full
+type Policy int
+
+const (
+	// The publisher waits until there is room (or its context is cancelled)
+	Block Policy = iota
+
+	// The oldest message in the buffer is thrown away to make room
+	DropOldest
+
+	// The new message is thrown away
+	DropNewest
+
+	// The subscription is closed, and its Err method returns ErrSlowConsumer
+	Disconnect
+)
+
+func (p Policy) String() string {
+
+	switch p {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop oldest"
+	case DropNewest:
+		return "drop newest"
+	case Disconnect:
+		return "disconnect"
+	}
+
+	return "unknown"
+}
+
+var (
+	// ErrClosed is returned when publishing to or subscribing to a broker that has been shut down
+	ErrClosed = errors.New("the broker is closed")
+
+	// ErrSlowConsumer is the error of a subscription that was disconnected because its buffer was full
+	ErrSlowConsumer = errors.New("the subscriber didn't keep up with the messages published to it")
+)
+
+// DefaultBuffer is the size of a subscription's buffer if Options doesn't give one
+const DefaultBuffer = 16
+
+// A Message is something published to a topic
+type Message struct {
+	Topic   string
+	Payload interface{}
+}
+
+// Options configure a subscription
+type Options struct {
+	// The number of messages that can be waiting for the subscriber (DefaultBuffer if zero)
+	Buffer int
+
+	// What to do when the buffer is full
+	Policy Policy
+}
+
+// A Broker delivers published messages to matching subscriptions. It is safe to use from multiple goroutines.
+type Broker struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]bool
+	closed bool
+
+	// Counts the goroutines delivering messages to subscribers
+	wg sync.WaitGroup
+}
+
+// New creates a Broker
+func New() *Broker {
+	return &Broker{subs: make(map[*Subscription]bool)}
+}
+
+// Subscribe creates a subscription to every topic matching pattern
+func (b *Broker) Subscribe(pattern string, opts Options) (*Subscription, error) {
+
+	if err := validate(pattern, true); err != nil {
+		return nil, err
+	}
+
+	size := opts.Buffer
+
+	if size <= 0 {
+		size = DefaultBuffer
+	}
+
+	s := &Subscription{
+		pattern: strings.Split(pattern, "."),
+		policy:  opts.Policy,
+		b:       b,
+		buffer:  make(chan Message, size),
+		out:     make(chan Message),
+		stop:    make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	b.subs[s] = true
+	b.wg.Add(1)
+
+	go s.deliver()
+
+	return s, nil
+}
+
+// Publish sends a message to every subscription matching topic, which must not contain wildcards. It only waits if a
+// matching subscription has the Block policy and a full buffer, and returns ctx's error if ctx is cancelled while it
+// waits.
+func (b *Broker) Publish(ctx context.Context, topic string, payload interface{}) error {
+
+	if err := validate(topic, false); err != nil {
+		return err
+	}
+
+	words := strings.Split(topic, ".")
+	m := Message{Topic: topic, Payload: payload}
+
+	b.mu.RLock()
+
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+
+	var matching []*Subscription
+
+	for s := range b.subs {
+		if match(s.pattern, words) {
+			matching = append(matching, s)
+		}
+	}
+
+	b.mu.RUnlock()
+
+	for _, s := range matching {
+
+		if err := s.publish(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops the broker accepting messages and subscriptions, waits until every message that has already been
+// published has been received by its subscriber, then closes every subscription's channel. If ctx is cancelled first,
+// the remaining messages are thrown away and ctx's error is returned.
+func (b *Broker) Shutdown(ctx context.Context) error {
+
+	subs := b.closeAll()
+	done := make(chan struct{})
+
+	go func() {
+
+		// Closing a subscription's buffer lets its delivery goroutine finish once the buffer is empty. This waits for
+		// publishers blocked on a full buffer, so it happens here rather than holding up the select below.
+		for _, s := range subs {
+			s.close(nil, false)
+		}
+
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	for _, s := range subs {
+		s.close(nil, true)
+	}
+
+	<-done
+
+	return ctx.Err()
+}
+
+// Close stops the broker, throwing away any messages that haven't been received and closing every subscription's
+// channel
+func (b *Broker) Close() {
+
+	for _, s := range b.closeAll() {
+		s.close(nil, true)
+	}
+
+	b.wg.Wait()
+}
+
+// closeAll marks the broker as closed and returns its subscriptions
+func (b *Broker) closeAll() []*Subscription {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	var subs []*Subscription
+
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+
+	b.subs = make(map[*Subscription]bool)
+
+	return subs
+}
+
+// remove forgets a subscription, so no more messages are published to it
+func (b *Broker) remove(s *Subscription) {
+
+	b.mu.Lock()
+	delete(b.subs, s)
+	b.mu.Unlock()
+}
+
+// A Subscription receives the messages published to topics matching its pattern
+type Subscription struct {
+	pattern []string
+	policy  Policy
+	b       *Broker
+
+	// Messages waiting for the subscriber. Publishers send to it with mu read-locked, and it is closed with mu locked, so
+	// nothing is sent to it once it is closed.
+	buffer chan Message
+	mu     sync.RWMutex
+	closed bool
+
+	// The channel the subscriber reads from, fed from buffer by the delivery goroutine
+	out chan Message
+
+	// Closed to throw away the buffer and release publishers waiting for room in it
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	dropped int64
+
+	errMu sync.Mutex
+	err   error
+}
+
+// C returns the channel messages are received on. It is closed when the subscription ends.
+func (s *Subscription) C() <-chan Message {
+	return s.out
+}
+
+// Pattern returns the pattern the subscription was created with
+func (s *Subscription) Pattern() string {
+	return strings.Join(s.pattern, ".")
+}
+
+// Dropped returns the number of messages thrown away because the buffer was full
+func (s *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Err returns ErrSlowConsumer if the subscription was disconnected because its buffer was full
+func (s *Subscription) Err() error {
+
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	return s.err
+}
+
+// Unsubscribe ends the subscription, throwing away any messages that haven't been received. Its channel is closed soon
+// after.
+func (s *Subscription) Unsubscribe() {
+	s.b.remove(s)
+	s.close(nil, true)
+}
+
+// publish adds a message to the buffer, following the subscription's policy if it is full
+func (s *Subscription) publish(ctx context.Context, m Message) error {
+
+	s.mu.RLock()
+
+	if s.closed {
+		s.mu.RUnlock()
+		return nil
+	}
+
+	// Most of the time there is room
+	select {
+	case s.buffer <- m:
+		s.mu.RUnlock()
+		return nil
+	default:
+	}
+
+	var err error
+	disconnect := false
+
+	switch s.policy {
+	case Block:
+
+		select {
+		case s.buffer <- m:
+		case <-s.stop:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+	case DropOldest:
+
+		// Another publisher might fill the gap first, so keep trying
+		for sent := false; !sent; {
+
+			select {
+			case <-s.buffer:
+				atomic.AddInt64(&s.dropped, 1)
+			default:
+			}
+
+			select {
+			case s.buffer <- m:
+				sent = true
+			default:
+			}
+		}
+
+	case DropNewest:
+		atomic.AddInt64(&s.dropped, 1)
+
+	case Disconnect:
+		disconnect = true
+	}
+
+	s.mu.RUnlock()
+
+	if disconnect {
+		s.b.remove(s)
+		s.close(ErrSlowConsumer, true)
+	}
+
+	return err
+}
+
+// close stops any more messages being added to the buffer. If discard is true, messages that haven't been received are
+// thrown away, otherwise they are still delivered. Safe to call more than once.
+func (s *Subscription) close(err error, discard bool) {
+
+	if discard {
+		// Before taking the lock, which publishers waiting for room hold
+		s.stopOnce.Do(func() { close(s.stop) })
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.buffer)
+
+	if err != nil {
+		s.errMu.Lock()
+		s.err = err
+		s.errMu.Unlock()
+	}
+}
+
+// deliver sends messages from the buffer to the subscriber until the buffer is closed and empty, or the subscription
+// is stopped
+func (s *Subscription) deliver() {
+
+	defer s.b.wg.Done()
+	defer close(s.out)
+
+	for {
+
+		var m Message
+		var okay bool
+
+		select {
+		case m, okay = <-s.buffer:
+		case <-s.stop:
+			return
+		}
+
+		if !okay {
+			return
+		}
+
+		select {
+		case s.out <- m:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// validate checks a topic, or a pattern if wildcards are allowed
+func validate(topic string, wildcards bool) error {
+
+	words := strings.Split(topic, ".")
+
+	for i, w := range words {
+
+		switch {
+		case w == "":
+			return fmt.Errorf("%q has an empty word", topic)
+		case !wildcards && (w == "*" || w == ">"):
+			return fmt.Errorf("%q is a pattern, not a topic", topic)
+		case w == ">" && i != len(words)-1:
+			return fmt.Errorf("%q has > before the last word", topic)
+		case len(w) > 1 && strings.ContainsAny(w, "*>"):
+			return fmt.Errorf("%q has a wildcard inside a word", topic)
+		}
+	}
+
+	return nil
+}
+
+// match reports whether the words of a topic match the words of a pattern
+func match(pattern, topic []string) bool {
+
+	for i, p := range pattern {
+
+		if p == ">" {
+			return len(topic) > i
+		}
+
+		if i >= len(topic) || (p != "*" && p != topic[i]) {
+			return false
+		}
+	}
+
+	return len(pattern) == len(topic)
+}
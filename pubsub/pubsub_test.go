@@ -0,0 +1,284 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"github.com/benhalstead/gotraining/leak"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func subscribe(t *testing.T, b *Broker, pattern string, opts Options) *Subscription {
+
+	s, err := b.Subscribe(pattern, opts)
+
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing to %s: %v", pattern, err)
+	}
+
+	return s
+}
+
+func publish(t *testing.T, b *Broker, topic string, payload interface{}) {
+
+	if err := b.Publish(context.Background(), topic, payload); err != nil {
+		t.Fatalf("Unexpected error publishing to %s: %v", topic, err)
+	}
+}
+
+// collect reads every message from a subscription in the background. The returned channel receives the payloads once
+// the subscription's channel is closed.
+func collect(s *Subscription) <-chan []interface{} {
+
+	result := make(chan []interface{}, 1)
+
+	go func() {
+
+		var payloads []interface{}
+
+		for m := range s.C() {
+			payloads = append(payloads, m.Payload)
+		}
+
+		result <- payloads
+	}()
+
+	return result
+}
+
+// fill publishes to a subscription that nobody is reading until its buffer is full and the delivery goroutine is
+// waiting for the subscriber with one more message
+func fill(t *testing.T, b *Broker, topic string, n int) {
+
+	publish(t, b, topic, 1)
+
+	// Give the delivery goroutine time to take the first message from the buffer
+	time.Sleep(20 * time.Millisecond)
+
+	for i := 2; i <= n; i++ {
+		publish(t, b, topic, i)
+	}
+}
+
+func TestMatch(t *testing.T) {
+
+	tests := []struct {
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.deleted", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.eu", false},
+		{"orders.*", "orders", false},
+		{"*.created", "users.created", true},
+		{"orders.>", "orders.created", true},
+		{"orders.>", "orders.created.eu", true},
+		{"orders.>", "orders", false},
+		{">", "anything.at.all", true},
+	}
+
+	for _, test := range tests {
+
+		if m := match(strings.Split(test.pattern, "."), strings.Split(test.topic, ".")); m != test.match {
+			t.Errorf("Expected %s matching %s to be %t", test.pattern, test.topic, test.match)
+		}
+	}
+}
+
+func TestInvalidTopics(t *testing.T) {
+	leak.VerifyNone(t)
+
+	b := New()
+	defer b.Close()
+
+	for _, p := range []string{"", "orders..created", "orders.>.eu", "orders.cr*ated"} {
+		if _, err := b.Subscribe(p, Options{}); err == nil {
+			t.Errorf("Expected an error subscribing to %q", p)
+		}
+	}
+
+	if err := b.Publish(context.Background(), "orders.*", 1); err == nil {
+		t.Errorf("Expected an error publishing to a pattern")
+	}
+}
+
+func TestPublishAndSubscribe(t *testing.T) {
+	leak.VerifyNone(t)
+
+	b := New()
+
+	all := collect(subscribe(t, b, "orders.>", Options{}))
+	created := collect(subscribe(t, b, "orders.*.created", Options{}))
+	users := collect(subscribe(t, b, "users.*", Options{}))
+
+	publish(t, b, "orders.eu.created", 1)
+	publish(t, b, "orders.eu.shipped", 2)
+	publish(t, b, "orders.us.created", 3)
+
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error shutting down: %v", err)
+	}
+
+	if got := <-all; !reflect.DeepEqual(got, []interface{}{1, 2, 3}) {
+		t.Errorf("Expected every order, got %v", got)
+	}
+
+	if got := <-created; !reflect.DeepEqual(got, []interface{}{1, 3}) {
+		t.Errorf("Expected created orders, got %v", got)
+	}
+
+	if got := <-users; got != nil {
+		t.Errorf("Expected nothing, got %v", got)
+	}
+
+	if err := b.Publish(context.Background(), "orders.eu.created", 4); err != ErrClosed {
+		t.Errorf("Expected publishing after shutdown to fail, got %v", err)
+	}
+
+	if _, err := b.Subscribe("orders.>", Options{}); err != ErrClosed {
+		t.Errorf("Expected subscribing after shutdown to fail, got %v", err)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	leak.VerifyNone(t)
+
+	b := New()
+	defer b.Close()
+
+	s := subscribe(t, b, "orders.*", Options{})
+	publish(t, b, "orders.created", 1)
+
+	s.Unsubscribe()
+
+	// Anything not yet received is thrown away
+	for range s.C() {
+	}
+
+	publish(t, b, "orders.created", 2)
+}
+
+func TestDropPolicies(t *testing.T) {
+	leak.VerifyNone(t)
+
+	b := New()
+
+	// The delivery goroutine holds one message and the buffer holds two more
+	oldest := subscribe(t, b, "events", Options{Buffer: 2, Policy: DropOldest})
+	newest := subscribe(t, b, "events", Options{Buffer: 2, Policy: DropNewest})
+
+	fill(t, b, "events", 3)
+
+	publish(t, b, "events", 4)
+	publish(t, b, "events", 5)
+
+	fromOldest := collect(oldest)
+	fromNewest := collect(newest)
+
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error shutting down: %v", err)
+	}
+
+	if got := <-fromOldest; !reflect.DeepEqual(got, []interface{}{1, 4, 5}) || oldest.Dropped() != 2 {
+		t.Errorf("Expected 2 and 3 to be dropped, got %v (%d dropped)", got, oldest.Dropped())
+	}
+
+	if got := <-fromNewest; !reflect.DeepEqual(got, []interface{}{1, 2, 3}) || newest.Dropped() != 2 {
+		t.Errorf("Expected 4 and 5 to be dropped, got %v (%d dropped)", got, newest.Dropped())
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	leak.VerifyNone(t)
+
+	b := New()
+	defer b.Close()
+
+	slow := subscribe(t, b, "events", Options{Buffer: 1, Policy: Disconnect})
+	fill(t, b, "events", 2)
+
+	publish(t, b, "events", 3)
+
+	for range slow.C() {
+	}
+
+	if slow.Err() != ErrSlowConsumer {
+		t.Errorf("Expected a slow consumer error, got %v", slow.Err())
+	}
+
+	// Disconnected subscribers don't hold up publishing
+	publish(t, b, "events", 4)
+}
+
+func TestBlock(t *testing.T) {
+	leak.VerifyNone(t)
+
+	b := New()
+	defer b.Close()
+
+	s := subscribe(t, b, "events", Options{Buffer: 1, Policy: Block})
+	fill(t, b, "events", 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := b.Publish(ctx, "events", 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected publishing to a full subscriber to time out, got %v", err)
+	}
+
+	// Once the subscriber reads, there is room again
+	published := make(chan error)
+
+	go func() {
+		published <- b.Publish(context.Background(), "events", 3)
+	}()
+
+	for i := 1; i <= 3; i++ {
+
+		if m := <-s.C(); m.Payload != i {
+			t.Errorf("Expected %d, got %v", i, m.Payload)
+		}
+	}
+
+	if err := <-published; err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestShutdownDiscardsWhenCancelled(t *testing.T) {
+	leak.VerifyNone(t)
+
+	b := New()
+
+	// Nobody reads from s, so its messages can't be delivered
+	s := subscribe(t, b, "events", Options{Buffer: 1, Policy: Block})
+	fill(t, b, "events", 2)
+
+	// A publisher waiting for room is released by the shutdown
+	published := make(chan error)
+
+	go func() {
+		published <- b.Publish(context.Background(), "events", 3)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := b.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the shutdown to time out, got %v", err)
+	}
+
+	if err := <-published; err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if _, okay := <-s.C(); okay {
+		t.Errorf("Expected the subscription to be closed")
+	}
+}
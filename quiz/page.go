@@ -0,0 +1,127 @@
+package quiz
+
+// page is the quiz runner's user interface. It shows the questions for the chosen lesson, posts the learner's
+// responses to /answers and marks each question with the result.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Go training quizzes</title>
+<style>
+body { max-width: 50em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; }
+pre { background: #f6f8fa; padding: 0.75em 1em; overflow-x: auto; }
+textarea { width: 100%; font-family: Menlo, Consolas, monospace; }
+.question { margin-bottom: 1.5em; }
+.correct { color: #1a7f37; }
+.incorrect { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>Go training quizzes</h1>
+<p>
+<label>Lesson <select id="lesson"></select></label>
+<label>Your name <input id="learner" placeholder="(as recorded by gotraining)"></label>
+</p>
+<form id="quiz"></form>
+<p id="score"></p>
+<script>
+const lesson = document.getElementById("lesson");
+const form = document.getElementById("quiz");
+const score = document.getElementById("score");
+
+function el(tag, text, className) {
+	const e = document.createElement(tag);
+
+	if (text) {
+		e.textContent = text;
+	}
+
+	if (className) {
+		e.className = className;
+	}
+
+	return e;
+}
+
+async function load() {
+	const q = await (await fetch("quiz?lesson=" + encodeURIComponent(lesson.value))).json();
+
+	form.textContent = "";
+	score.textContent = "";
+
+	q.questions.forEach((qu, i) => {
+		const div = el("div", "", "question");
+		div.id = "q-" + qu.id;
+		div.appendChild(el("p", (i + 1) + ". " + qu.prompt));
+
+		if (qu.code) {
+			div.appendChild(el("pre", qu.code));
+		}
+
+		if (qu.kind == "choice") {
+			qu.choices.forEach(c => {
+				const label = el("label");
+				const input = el("input");
+				input.type = "radio";
+				input.name = qu.id;
+				input.value = c;
+				label.appendChild(input);
+				label.appendChild(document.createTextNode(" " + c));
+				div.appendChild(label);
+				div.appendChild(el("br"));
+			});
+		} else {
+			const input = el("textarea");
+			input.name = qu.id;
+			input.rows = qu.kind == "output" ? 4 : 1;
+			div.appendChild(input);
+		}
+
+		div.appendChild(el("p", "", "result"));
+		form.appendChild(div);
+	});
+
+	const submit = el("button", "Check my answers");
+	submit.type = "submit";
+	form.appendChild(submit);
+}
+
+form.addEventListener("submit", async e => {
+	e.preventDefault();
+
+	const responses = {};
+
+	for (const [k, v] of new FormData(form)) {
+		responses[k] = v;
+	}
+
+	const res = await fetch("answers", {method: "POST", headers: {"Content-Type": "application/json"}, body: JSON.stringify({
+		learner: document.getElementById("learner").value,
+		lesson: lesson.value,
+		responses: responses
+	})});
+
+	const r = await res.json();
+
+	r.questions.forEach(m => {
+		const p = document.querySelector("#q-" + CSS.escape(m.id) + " .result");
+		p.className = "result " + (m.correct ? "correct" : "incorrect");
+		p.textContent = (m.correct ? "Correct. " : "Not quite - the answer is: " + m.answer + ". ") + (m.explanation || "");
+	});
+
+	score.textContent = "You scored " + r.score + " out of " + r.total;
+});
+
+fetch("quizzes").then(res => res.json()).then(ids => {
+	for (const id of ids) {
+		lesson.add(new Option(id, id));
+	}
+
+	load();
+});
+
+lesson.addEventListener("change", load);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,288 @@
+// Package quiz checks a learner's understanding of a lesson with a few short questions.
+//
+// The questions for a lesson are kept in a JSON file in a quizzes directory alongside the lesson, named after the
+// lesson's file (so the questions for structures/maps.go are in structures/quizzes/maps.json):
+//
+//	{
+//	  "questions": [
+//	    {
+//	      "id": "missing-key",
+//	      "kind": "choice",
+//	      "prompt": "What does sim[\"THREE\"] return?",
+//	      "choices": ["0", "nil", "It panics"],
+//	      "answer": "0",
+//	      "explanation": "Reading a missing key returns the zero value for the map's value type"
+//	    }
+//	  ]
+//	}
+//
+// There are three kinds of question: multiple choice (choice), where the learner picks one of the choices;
+// predict-the-output (output), where the learner types what the question's code prints; and fill-in-the-blank
+// (blank), where the learner types the code that replaces ___ in the question's code.
+package quiz
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/benhalstead/gotraining/tutorial"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The directory inside a topic that holds its quizzes
+const quizzesDir = "quizzes"
+
+// Kind is the type of a question
+type Kind string
+
+const (
+	Choice Kind = "choice"
+	Output Kind = "output"
+	Blank  Kind = "blank"
+)
+
+// A Question is one thing a learner is asked
+type Question struct {
+	ID     string `json:"id"`
+	Kind   Kind   `json:"kind"`
+	Prompt string `json:"prompt"`
+
+	// Code the question is about (optional for choice questions). Blank questions mark the blank with ___.
+	Code string `json:"code,omitempty"`
+
+	// The options for a choice question
+	Choices []string `json:"choices,omitempty"`
+
+	// The correct answer. For choice questions this is the text of the correct choice.
+	Answer string `json:"answer"`
+
+	// Other answers to a blank question that are also correct
+	Accept []string `json:"accept,omitempty"`
+
+	// Shown after the question has been answered
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// A Quiz is the set of questions for one lesson
+type Quiz struct {
+	Lesson    string     `json:"lesson"`
+	Questions []Question `json:"questions"`
+}
+
+// Path returns the path, relative to the root of the repository, of the quiz for a lesson
+func Path(lesson string) string {
+	topic, name := filepath.Split(filepath.FromSlash(lesson))
+	return filepath.Join(topic, quizzesDir, name+".json")
+}
+
+// Load reads the quiz for a lesson. root is the directory containing this repository.
+func Load(root, lesson string) (*Quiz, error) {
+
+	b, err := os.ReadFile(filepath.Join(root, Path(lesson)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	q := new(Quiz)
+
+	if err := json.Unmarshal(b, q); err != nil {
+		return nil, fmt.Errorf("%s: %s", Path(lesson), err.Error())
+	}
+
+	q.Lesson = lesson
+
+	if err := q.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %s", Path(lesson), err.Error())
+	}
+
+	return q, nil
+}
+
+// Lessons returns the ID of every lesson that has a quiz, in reading order
+func Lessons(root string) []string {
+
+	var ids []string
+
+	for _, id := range tutorial.LessonIDs() {
+		if _, err := os.Stat(filepath.Join(root, Path(id))); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// Validate checks that every question is complete and can be answered correctly
+func (q *Quiz) Validate() error {
+
+	if len(q.Questions) == 0 {
+		return fmt.Errorf("the quiz has no questions")
+	}
+
+	seen := make(map[string]bool)
+
+	for i, qu := range q.Questions {
+
+		if qu.ID == "" {
+			return fmt.Errorf("question %d has no id", i+1)
+		}
+
+		if seen[qu.ID] {
+			return fmt.Errorf("there is more than one question with the id %s", qu.ID)
+		}
+
+		seen[qu.ID] = true
+
+		if qu.Prompt == "" || qu.Answer == "" {
+			return fmt.Errorf("question %s needs a prompt and an answer", qu.ID)
+		}
+
+		switch qu.Kind {
+		case Choice:
+			if qu.choiceIndex(qu.Answer) < 0 {
+				return fmt.Errorf("the answer to question %s is not one of its choices", qu.ID)
+			}
+		case Output:
+			if qu.Code == "" {
+				return fmt.Errorf("question %s asks for the output of its code but has none", qu.ID)
+			}
+		case Blank:
+			if !strings.Contains(qu.Code, blank) {
+				return fmt.Errorf("the code for question %s has no %s to fill in", qu.ID, blank)
+			}
+		default:
+			return fmt.Errorf("question %s has an unknown kind %q (expected choice, output or blank)", qu.ID, qu.Kind)
+		}
+	}
+
+	return nil
+}
+
+// The marker for the part of a blank question's code the learner fills in
+const blank = "___"
+
+// Check reports whether response is a correct answer to the question. Choice questions can be answered with the
+// number of the choice (starting at 1), its letter (a, b, c...) or its text. Differences in whitespace are ignored
+// for output and blank questions.
+func (qu Question) Check(response string) bool {
+
+	switch qu.Kind {
+	case Choice:
+		return qu.choiceIndex(response) == qu.choiceIndex(qu.Answer)
+	case Output:
+		return normaliseOutput(response) == normaliseOutput(qu.Answer)
+	case Blank:
+
+		r := strings.Join(strings.Fields(response), " ")
+
+		for _, a := range append([]string{qu.Answer}, qu.Accept...) {
+			if r == strings.Join(strings.Fields(a), " ") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// choiceIndex returns the index of the choice a response refers to or -1 if it doesn't match any of them
+func (qu Question) choiceIndex(response string) int {
+
+	r := strings.TrimSpace(response)
+
+	for i, c := range qu.Choices {
+		if r == c {
+			return i
+		}
+	}
+
+	if n, err := strconv.Atoi(r); err == nil && n >= 1 && n <= len(qu.Choices) {
+		return n - 1
+	}
+
+	if len(r) == 1 {
+		if n := int(strings.ToLower(r)[0] - 'a'); n >= 0 && n < len(qu.Choices) {
+			return n
+		}
+	}
+
+	return -1
+}
+
+// normaliseOutput removes trailing whitespace from every line and any blank lines at the start or end
+func normaliseOutput(s string) string {
+
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// Public returns a copy of the quiz with the answers and explanations removed, so it can be sent to a learner
+func (q *Quiz) Public() *Quiz {
+
+	p := &Quiz{Lesson: q.Lesson}
+
+	for _, qu := range q.Questions {
+		qu.Answer, qu.Accept, qu.Explanation = "", nil, ""
+		p.Questions = append(p.Questions, qu)
+	}
+
+	return p
+}
+
+// A Marked question records whether a learner's response was correct
+type Marked struct {
+	ID          string `json:"id"`
+	Response    string `json:"response"`
+	Correct     bool   `json:"correct"`
+	Answer      string `json:"answer"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// A Result is a marked attempt at a quiz
+type Result struct {
+	Lesson    string   `json:"lesson"`
+	Score     int      `json:"score"`
+	Total     int      `json:"total"`
+	Questions []Marked `json:"questions"`
+}
+
+// Mark checks a learner's responses, keyed by question ID. Unanswered questions are marked as incorrect.
+func (q *Quiz) Mark(responses map[string]string) Result {
+
+	r := Result{Lesson: q.Lesson, Total: len(q.Questions)}
+
+	for _, qu := range q.Questions {
+
+		resp := responses[qu.ID]
+
+		m := Marked{
+			ID:          qu.ID,
+			Response:    resp,
+			Correct:     qu.Check(resp),
+			Answer:      qu.Answer,
+			Explanation: qu.Explanation,
+		}
+
+		if m.Correct {
+			r.Score++
+		}
+
+		r.Questions = append(r.Questions, m)
+	}
+
+	return r
+}
+
+// A Recorder keeps each learner's quiz scores (see progress.Store)
+type Recorder interface {
+	RecordQuiz(learner, lesson string, score, total int, at time.Time) error
+}
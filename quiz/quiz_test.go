@@ -0,0 +1,196 @@
+package quiz
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The repository root, relative to this package
+const root = ".."
+
+func TestQuizzesAreValid(t *testing.T) {
+
+	ids := Lessons(root)
+
+	if len(ids) == 0 {
+		t.Fatal("Expected some lessons to have quizzes")
+	}
+
+	for _, id := range ids {
+
+		q, err := Load(root, id)
+
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		// Every quiz must be passable with its own answers
+		responses := make(map[string]string)
+
+		for _, qu := range q.Questions {
+			responses[qu.ID] = qu.Answer
+		}
+
+		if r := q.Mark(responses); r.Score != r.Total {
+			t.Errorf("%s: the answers only score %d of %d", id, r.Score, r.Total)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+
+	choice := Question{Kind: Choice, Choices: []string{"0", "nil", "It panics"}, Answer: "0"}
+
+	for _, r := range []string{"0", "1", "a", "A", " 0 "} {
+		if !choice.Check(r) {
+			t.Errorf("Expected %q to be a correct answer to a choice question", r)
+		}
+	}
+
+	for _, r := range []string{"nil", "2", "b", "d", "4", ""} {
+		if choice.Check(r) {
+			t.Errorf("Expected %q to be an incorrect answer to a choice question", r)
+		}
+	}
+
+	output := Question{Kind: Output, Answer: "Second defer\nFirst defer"}
+
+	if !output.Check("\nSecond defer  \r\nFirst defer\n\n") {
+		t.Errorf("Expected differences in trailing whitespace to be ignored")
+	}
+
+	if output.Check("First defer\nSecond defer") {
+		t.Errorf("Expected output in the wrong order to be incorrect")
+	}
+
+	blank := Question{Kind: Blank, Answer: "contains", Accept: []string{"found"}}
+
+	if !blank.Check(" contains") || !blank.Check("found") || blank.Check("ok") {
+		t.Errorf("Expected only the answer or an accepted alternative to be correct")
+	}
+}
+
+func TestValidate(t *testing.T) {
+
+	q := &Quiz{Questions: []Question{{ID: "a", Kind: Choice, Prompt: "?", Choices: []string{"x"}, Answer: "y"}}}
+
+	if q.Validate() == nil {
+		t.Errorf("Expected a choice question whose answer isn't a choice to be invalid")
+	}
+
+	q.Questions[0] = Question{ID: "a", Kind: Blank, Prompt: "?", Code: "x := 1", Answer: "1"}
+
+	if q.Validate() == nil {
+		t.Errorf("Expected a blank question with no blank to be invalid")
+	}
+}
+
+type recorder struct {
+	learner, lesson string
+	score, total    int
+}
+
+func (r *recorder) RecordQuiz(learner, lesson string, score, total int, at time.Time) error {
+	r.learner, r.lesson, r.score, r.total = learner, lesson, score, total
+	return nil
+}
+
+func TestServer(t *testing.T) {
+
+	rec := new(recorder)
+	srv := httptest.NewServer(NewServer(root, rec, "default"))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/quiz?lesson=functions/defer")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var q Quiz
+	json.NewDecoder(res.Body).Decode(&q)
+	res.Body.Close()
+
+	if len(q.Questions) == 0 || q.Questions[0].Answer != "" {
+		t.Fatalf("Expected questions without answers, got %+v", q)
+	}
+
+	b, err := json.Marshal(Submission{Lesson: "functions/defer", Responses: map[string]string{"return-values": "2"}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err = http.Post(srv.URL+"/answers", "application/json", bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+
+	var r Result
+	json.NewDecoder(res.Body).Decode(&r)
+	res.Body.Close()
+
+	if r.Score != 1 || r.Total != len(q.Questions) {
+		t.Errorf("Expected a score of 1 of %d, got %d of %d", len(q.Questions), r.Score, r.Total)
+	}
+
+	if rec.learner != "default" || rec.lesson != "functions/defer" || rec.score != 1 {
+		t.Errorf("Expected the score to be recorded for the default learner, got %+v", rec)
+	}
+
+	if res, err = http.Get(srv.URL + "/quiz?lesson=../README"); err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a quiz that doesn't exist to be not found, got %d", res.StatusCode)
+	}
+}
+
+func TestServerRejectsAnswersFromOtherSites(t *testing.T) {
+
+	rec := new(recorder)
+	s := NewServer(root, rec, "default")
+	s.addr = "localhost:8082"
+
+	tests := []struct {
+		name        string
+		host        string
+		origin      string
+		contentType string
+		status      int
+	}{
+		{"a form or no-cors fetch", "localhost:8082", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"another site", "localhost:8082", "http://example.com", "application/json", http.StatusForbidden},
+		{"DNS rebinding", "example.com:8082", "http://example.com:8082", "application/json", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+
+		r := httptest.NewRequest(http.MethodPost, "/answers", strings.NewReader(`{"learner": "mallory", "lesson": "functions/defer"}`))
+		r.Host = test.host
+		r.Header.Set("Content-Type", test.contentType)
+
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("Expected status %d for %s, got %d: %s", test.status, test.name, w.Code, w.Body)
+		}
+	}
+
+	if rec.learner != "" {
+		t.Errorf("Expected nothing to be recorded, got %+v", rec)
+	}
+}
@@ -0,0 +1,145 @@
+package quiz
+
+import (
+	"encoding/json"
+	"github.com/benhalstead/gotraining/localhttp"
+	"net/http"
+	"time"
+)
+
+// A Server lets learners take quizzes in a browser
+type Server struct {
+	root     string
+	recorder Recorder
+	learner  string
+
+	// The address the server is listening on, if it was started with ListenAndServe
+	addr string
+}
+
+// NewServer creates a Server for the quizzes in the repository at root. Scores are recorded with recorder (which may
+// be nil) against the learner named in each submission, or learner if none is given.
+func NewServer(root string, recorder Recorder, learner string) *Server {
+	return &Server{root: root, recorder: recorder, learner: learner}
+}
+
+// A Submission is a learner's responses to a quiz, keyed by question ID
+type Submission struct {
+	Learner   string            `json:"learner"`
+	Lesson    string            `json:"lesson"`
+	Responses map[string]string `json:"responses"`
+}
+
+// ListenAndServe serves the quiz runner on addr. Answers are only accepted from the quiz runner's own page, sent to
+// addr.
+func (s *Server) ListenAndServe(addr string) error {
+
+	s.addr = addr
+
+	return http.ListenAndServe(addr, s)
+}
+
+// ServeHTTP serves the quiz runner:
+//
+//	GET  /                          the page learners use to take quizzes
+//	GET  /quizzes                   a JSON array of the IDs of lessons with quizzes
+//	GET  /quiz?lesson=<id>          the questions for a lesson, without their answers
+//	POST /answers                   marks a JSON Submission, returning a Result
+//
+// Submissions are rejected unless they are JSON and come from the quiz runner's own page (see localhttp)
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	case "/quizzes":
+		writeJSON(w, Lessons(s.root))
+	case "/quiz":
+		s.serveQuiz(w, r)
+	case "/answers":
+		s.serveAnswers(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// load returns the quiz for a lesson, writing an error response if there isn't one
+func (s *Server) load(w http.ResponseWriter, lesson string) *Quiz {
+
+	// Only lessons with quizzes can be loaded, so the request can't name any other file
+	for _, l := range Lessons(s.root) {
+
+		if l != lesson {
+			continue
+		}
+
+		q, err := Load(s.root, lesson)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return nil
+		}
+
+		return q
+	}
+
+	http.Error(w, "there is no quiz for "+lesson, http.StatusNotFound)
+
+	return nil
+}
+
+func (s *Server) serveQuiz(w http.ResponseWriter, r *http.Request) {
+
+	if q := s.load(w, r.URL.Query().Get("lesson")); q != nil {
+		writeJSON(w, q.Public())
+	}
+}
+
+func (s *Server) serveAnswers(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "use POST to submit answers", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !localhttp.AllowJSON(w, r, s.addr) {
+		return
+	}
+
+	var sub Submission
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&sub); err != nil {
+		http.Error(w, "invalid submission: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	q := s.load(w, sub.Lesson)
+
+	if q == nil {
+		return
+	}
+
+	res := q.Mark(sub.Responses)
+
+	learner := sub.Learner
+
+	if learner == "" {
+		learner = s.learner
+	}
+
+	if s.recorder != nil {
+		if err := s.recorder.RecordQuiz(learner, q.Lesson, res.Score, res.Total, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, res)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,214 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A cron schedule, with the set of allowed values of each field
+type cron struct {
+	minute, hour, dom, month, dow uint64
+
+	// Whether the day of the month and day of the week fields were restricted (didn't start with *, so */2 isn't
+	// restricted). If both are, a day matches if either of them does, as in Vixie cron.
+	domRestricted, dowRestricted bool
+}
+
+// Shorthands for common schedules
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Cron parses a cron expression: five fields giving the minute (0-59), hour (0-23), day of the month (1-31), month
+// (1-12) and day of the week (0-6, Sunday is 0 or 7) a job runs at. Each field is * (any value), a number, a range
+// (1-5), or a list of them (1,3,5), and a * or range can be followed by a step (*/15, 9-17/2). The shorthands @hourly,
+// @daily, @weekly, @monthly and @yearly are also allowed.
+//
+// Times are in the location of the time the schedule starts from.
+func Cron(expr string) (Schedule, error) {
+
+	if m, okay := cronMacros[expr]; okay {
+		expr = m
+	}
+
+	fields := strings.Fields(expr)
+
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%q should have 5 fields, has %d", expr, len(fields))
+	}
+
+	c := &cron{}
+
+	ranges := []struct {
+		name     string
+		min, max int
+		bits     *uint64
+	}{
+		{"minute", 0, 59, &c.minute},
+		{"hour", 0, 23, &c.hour},
+		{"day of the month", 1, 31, &c.dom},
+		{"month", 1, 12, &c.month},
+		{"day of the week", 0, 7, &c.dow},
+	}
+
+	for i, r := range ranges {
+
+		bits, err := parseField(fields[i], r.min, r.max)
+
+		if err != nil {
+			return nil, fmt.Errorf("%q has an invalid %s: %s", expr, r.name, err.Error())
+		}
+
+		*r.bits = bits
+	}
+
+	// 7 is another way of writing Sunday
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+
+	c.domRestricted = !strings.HasPrefix(fields[2], "*")
+	c.dowRestricted = !strings.HasPrefix(fields[4], "*")
+
+	return c, nil
+}
+
+// MustCron is like Cron but panics if expr is invalid. It is intended for expressions that are known to be valid.
+func MustCron(expr string) Schedule {
+
+	s, err := Cron(expr)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// parseField returns a bit set of the values a field allows
+func parseField(field string, min, max int) (uint64, error) {
+
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+
+		rng, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+
+			s, err := strconv.Atoi(part[i+1:])
+
+			if err != nil || s < 1 {
+				return 0, fmt.Errorf("%q has an invalid step", part)
+			}
+
+			rng, step = part[:i], s
+		}
+
+		lo, hi := min, max
+
+		switch {
+		case rng == "*":
+
+		case strings.Contains(rng, "-"):
+
+			bounds := strings.SplitN(rng, "-", 2)
+
+			var err error
+
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("%q isn't a number", bounds[0])
+			}
+
+			if hi, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("%q isn't a number", bounds[1])
+			}
+
+		default:
+
+			v, err := strconv.Atoi(rng)
+
+			if err != nil {
+				return 0, fmt.Errorf("%q isn't a number", rng)
+			}
+
+			if step != 1 {
+				return 0, fmt.Errorf("%q has a step without a range", part)
+			}
+
+			lo, hi = v, v
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is outside %d-%d", rng, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// Next returns the first time after t that matches the schedule, or the zero time if there isn't one in the next five
+// years (e.g. for the 30th of February)
+func (c *cron) Next(t time.Time) time.Time {
+
+	loc := t.Location()
+
+	// Cron schedules run on the minute
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+
+		if !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !has(c.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+
+	return dom && dow
+}
@@ -0,0 +1,531 @@
+// Package schedule runs jobs periodically: at fixed intervals, with a fixed delay between runs, or at the times given
+// by a cron expression.
+//
+// Each job runs in its own goroutine with its own context, which is cancelled if the job is cancelled or the
+// scheduler is stopped. A job whose run is due while its previous run is still going follows its Overlap policy.
+//
+//	s := schedule.New(nil)
+//
+//	s.Add(schedule.Job{
+//		Name:     "cleanup",
+//		Schedule: schedule.Every(time.Minute),
+//		Jitter:   5 * time.Second,
+//		Run: func(ctx context.Context) error {
+//			return removeExpiredSessions(ctx)
+//		},
+//	})
+//
+//	...
+//
+//	s.Shutdown(ctx) // waits for runs in progress to finish
+//
+// The scheduler tells the time with a clock.Clock, so tests (and lessons) can give it a VirtualClock that doesn't need
+// real time to pass.
+package schedule
+
+import (
+	"context"
+	"errors"
+	"github.com/benhalstead/gotraining/clock"
+	"github.com/benhalstead/gotraining/group"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// A Schedule decides when a job runs
+type Schedule interface {
+	// Next returns the time of the run after t, or the zero time if there are no more runs
+	Next(t time.Time) time.Time
+}
+
+type interval time.Duration
+
+// Every runs a job at a fixed interval, starting one interval after it is added. If a run takes longer than the
+// interval, the next one is due as soon as it finishes (or while it is still going, see Overlap).
+func Every(d time.Duration) Schedule {
+
+	if d <= 0 {
+		panic("schedule: the interval must be positive")
+	}
+
+	return interval(d)
+}
+
+func (i interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+type delay time.Duration
+
+// AfterDelay runs a job with a fixed delay between the end of one run and the start of the next, starting one delay
+// after it is added. Runs never overlap.
+func AfterDelay(d time.Duration) Schedule {
+
+	if d <= 0 {
+		panic("schedule: the delay must be positive")
+	}
+
+	return delay(d)
+}
+
+func (d delay) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
+
+// Overlap is what happens when a job's run is due while its previous run is still going
+type Overlap int
+
+const (
+	// The run is skipped
+	Skip Overlap = iota
+
+	// The run starts as soon as the previous one finishes. Any number of runs can be queued.
+	Queue
+
+	// The run starts straight away, alongside the previous one
+	Allow
+)
+
+func (o Overlap) String() string {
+
+	switch o {
+	case Skip:
+		return "skip"
+	case Queue:
+		return "queue"
+	case Allow:
+		return "allow"
+	}
+
+	return "unknown"
+}
+
+// A Job is work to be done on a schedule
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+
+	// Each run is delayed by a random duration up to Jitter, so jobs on the same schedule (perhaps in many copies of a
+	// program) don't all run at exactly the same moment
+	Jitter time.Duration
+
+	Overlap Overlap
+
+	// How long each run may take before its context is cancelled with context.DeadlineExceeded (no limit if zero). The
+	// time is measured with the scheduler's Clock, so the context doesn't report it as a deadline.
+	Timeout time.Duration
+
+	// Called with the error of each run that fails (optional). A run that panics fails with a *group.PanicError. Must
+	// be safe to call concurrently.
+	OnError func(err error)
+}
+
+// ErrStopped is returned when adding a job to a scheduler that has been shut down
+var ErrStopped = errors.New("the scheduler has been stopped")
+
+// A Scheduler runs jobs on their schedules. It is safe to use from multiple goroutines.
+type Scheduler struct {
+	clock clock.Clock
+
+	// Returns a random number in [0, n), for jitter
+	random func(n int64) int64
+
+	// Cancelled to cancel every job's context
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Closed to stop starting runs
+	stopping chan struct{}
+
+	mu      sync.Mutex
+	stopped bool
+	entries []*Entry
+
+	// Counts the goroutines that wait for each job's runs to be due
+	loops sync.WaitGroup
+
+	// Counts runs in progress
+	runs sync.WaitGroup
+}
+
+// New creates a Scheduler that tells the time with c
+func New(c clock.Clock) *Scheduler {
+
+	if c == nil {
+		c = clock.RealClock{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var randMu sync.Mutex
+
+	return &Scheduler{
+		clock:    c,
+		ctx:      ctx,
+		cancel:   cancel,
+		stopping: make(chan struct{}),
+		random: func(n int64) int64 {
+			randMu.Lock()
+			defer randMu.Unlock()
+
+			return r.Int63n(n)
+		},
+	}
+}
+
+// An Entry is a job that has been added to a Scheduler
+type Entry struct {
+	job Job
+	s   *Scheduler
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	running int
+	queued  int
+	runs    int
+	skipped int
+	next    time.Time
+	last    time.Time
+	lastErr error
+
+	// Receives a value each time a run finishes, for schedules that count from the end of a run
+	finished chan struct{}
+}
+
+// Add starts running a job on its schedule
+func (s *Scheduler) Add(job Job) (*Entry, error) {
+
+	if job.Schedule == nil || job.Run == nil {
+		return nil, errors.New("a job needs a Schedule and a Run function")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, ErrStopped
+	}
+
+	e := &Entry{job: job, s: s, finished: make(chan struct{}, 1)}
+	e.ctx, e.cancel = context.WithCancel(s.ctx)
+
+	s.entries = append(s.entries, e)
+	s.loops.Add(1)
+
+	go e.loop(s.clock.Now())
+
+	return e, nil
+}
+
+// Entries returns every job that has been added, in the order they were added
+func (s *Scheduler) Entries() []*Entry {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Entry(nil), s.entries...)
+}
+
+// Shutdown stops starting runs (queued runs are dropped) and waits for runs in progress to finish. If ctx is cancelled
+// first, the contexts of the runs still in progress are cancelled and ctx's error is returned once they have finished.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+
+	s.stopStarting()
+
+	done := make(chan struct{})
+
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.cancel()
+	<-done
+
+	return ctx.Err()
+}
+
+// Stop stops starting runs, cancels the contexts of the runs in progress and waits for them to finish
+func (s *Scheduler) Stop() {
+
+	s.stopStarting()
+	s.cancel()
+	s.runs.Wait()
+}
+
+func (s *Scheduler) stopStarting() {
+
+	s.mu.Lock()
+
+	if !s.stopped {
+		s.stopped = true
+		close(s.stopping)
+	}
+
+	s.mu.Unlock()
+
+	s.loops.Wait()
+}
+
+// Name returns the job's name
+func (e *Entry) Name() string {
+	return e.job.Name
+}
+
+// Cancel stops the job: no more runs start, and the context of any run in progress is cancelled
+func (e *Entry) Cancel() {
+	e.cancel()
+}
+
+// Runs returns the number of runs that have started
+func (e *Entry) Runs() int {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.runs
+}
+
+// Skipped returns the number of runs skipped because the previous run was still going
+func (e *Entry) Skipped() int {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.skipped
+}
+
+// Next returns the time the next run is due (before any jitter), or the zero time if there are no more runs
+func (e *Entry) Next() time.Time {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.next
+}
+
+// Last returns the time the last run finished and the error it returned
+func (e *Entry) Last() (time.Time, error) {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.last, e.lastErr
+}
+
+// loop waits for each run to be due and starts it
+func (e *Entry) loop(start time.Time) {
+
+	s := e.s
+	defer s.loops.Done()
+
+	// There are no more runs once the loop ends, however it ends
+	defer func() {
+		e.mu.Lock()
+		e.next = time.Time{}
+		e.mu.Unlock()
+	}()
+
+	_, fromEnd := e.job.Schedule.(delay)
+	next := e.job.Schedule.Next(start)
+
+	for !next.IsZero() {
+
+		e.mu.Lock()
+		e.next = next
+		e.mu.Unlock()
+
+		wait := next.Sub(s.clock.Now())
+
+		if e.job.Jitter > 0 {
+			wait += time.Duration(s.random(int64(e.job.Jitter)))
+		}
+
+		timer := s.clock.NewTimer(wait)
+
+		select {
+		case <-timer.C():
+		case <-s.stopping:
+			timer.Stop()
+			return
+		case <-e.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		e.due()
+
+		if fromEnd {
+
+			select {
+			case <-e.finished:
+			case <-s.stopping:
+				return
+			case <-e.ctx.Done():
+				return
+			}
+
+			next = e.job.Schedule.Next(s.clock.Now())
+
+		} else {
+			next = e.job.Schedule.Next(next)
+		}
+	}
+}
+
+// due starts a run, or skips or queues it if the previous run is still going
+func (e *Entry) due() {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running > 0 {
+
+		switch e.job.Overlap {
+		case Skip:
+			e.skipped++
+			return
+		case Queue:
+			e.queued++
+			return
+		}
+	}
+
+	e.start()
+}
+
+// start starts a run. Must be called with the lock held.
+func (e *Entry) start() {
+
+	// Holding the scheduler's lock stops a run starting once Shutdown has started waiting for runs
+	s := e.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped || e.ctx.Err() != nil {
+		e.queued = 0
+		return
+	}
+
+	e.running++
+	e.runs++
+	s.runs.Add(1)
+
+	go e.run()
+}
+
+func (e *Entry) run() {
+
+	defer e.s.runs.Done()
+
+	ctx, cancel := e.ctx, context.CancelFunc(func() {})
+
+	if e.job.Timeout > 0 {
+		ctx, cancel = withTimeout(ctx, e.s.clock, e.job.Timeout)
+	}
+
+	err := call(ctx, e.job.Run)
+	cancel()
+
+	if err != nil && e.job.OnError != nil {
+		e.job.OnError(err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.running--
+	e.last, e.lastErr = e.s.clock.Now(), err
+
+	select {
+	case e.finished <- struct{}{}:
+	default:
+	}
+
+	if e.queued > 0 {
+		e.queued--
+		e.start()
+	}
+}
+
+// call calls fn, turning a panic into a *group.PanicError so one bad job doesn't stop the others
+func call(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = &group.PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// A timeoutContext is cancelled when its parent is, or with context.DeadlineExceeded once a Clock's timer fires
+type timeoutContext struct {
+	context.Context
+
+	done chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// withTimeout is like context.WithTimeout, but measures the timeout with c
+func withTimeout(parent context.Context, c clock.Clock, d time.Duration) (context.Context, context.CancelFunc) {
+
+	ctx := &timeoutContext{Context: parent, done: make(chan struct{})}
+	timer := c.NewTimer(d)
+
+	go func() {
+
+		select {
+		case <-timer.C():
+			ctx.cancel(context.DeadlineExceeded)
+			return
+		case <-parent.Done():
+			ctx.cancel(parent.Err())
+		case <-ctx.done:
+		}
+
+		timer.Stop()
+	}()
+
+	return ctx, func() { ctx.cancel(context.Canceled) }
+}
+
+// Done has its own channel, rather than the parent's, so contexts derived from this one see its error
+func (c *timeoutContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *timeoutContext) Err() error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *timeoutContext) cancel(err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
@@ -0,0 +1,451 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"github.com/benhalstead/gotraining/clock"
+	"github.com/benhalstead/gotraining/group"
+	"github.com/benhalstead/gotraining/leak"
+	"sync"
+	"testing"
+	"time"
+)
+
+func add(t *testing.T, s *Scheduler, job Job) *Entry {
+
+	t.Helper()
+
+	e, err := s.Add(job)
+
+	if err != nil {
+		t.Fatalf("Unexpected error adding a job: %v", err)
+	}
+
+	return e
+}
+
+// step advances the clock by d once the job's loop is waiting for its next run
+func step(c *clock.VirtualClock, d time.Duration) {
+	c.WaitUntilSleeping(1)
+	c.Advance(d)
+}
+
+// A blockingJob's runs wait for a value on release (or for their context to be cancelled)
+type blockingJob struct {
+	release chan bool
+
+	// Receives a value as each run starts
+	started chan bool
+
+	mu      sync.Mutex
+	running int
+}
+
+func newBlockingJob() *blockingJob {
+	return &blockingJob{release: make(chan bool), started: make(chan bool, 10)}
+}
+
+func (b *blockingJob) run(ctx context.Context) error {
+
+	b.mu.Lock()
+	b.running++
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.running--
+		b.mu.Unlock()
+	}()
+
+	b.started <- true
+
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// count returns the number of runs in progress
+func (b *blockingJob) count() int {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.running
+}
+
+func TestEvery(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	s := New(c)
+	defer s.Stop()
+
+	ran := make(chan time.Time, 10)
+
+	e := add(t, s, Job{Name: "tick", Schedule: Every(time.Second), Run: func(ctx context.Context) error {
+		ran <- c.Now()
+		return nil
+	}})
+
+	for i := 1; i <= 3; i++ {
+
+		step(c, time.Second)
+
+		if at := <-ran; at.Sub(clock.Epoch) != time.Duration(i)*time.Second {
+			t.Errorf("Expected run %d after %ds, was after %v", i, i, at.Sub(clock.Epoch))
+		}
+	}
+
+	c.WaitUntilSleeping(1)
+
+	if next := e.Next(); !next.Equal(clock.Epoch.Add(4 * time.Second)) {
+		t.Errorf("Expected the next run after 4s, got %v", next.Sub(clock.Epoch))
+	}
+
+	if e.Runs() != 3 || e.Name() != "tick" {
+		t.Errorf("Expected 3 runs of tick, got %d of %s", e.Runs(), e.Name())
+	}
+}
+
+func TestAfterDelay(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	s := New(c)
+	defer s.Stop()
+
+	job := newBlockingJob()
+	e := add(t, s, Job{Schedule: AfterDelay(time.Second), Run: job.run})
+
+	step(c, time.Second)
+	<-job.started
+
+	// Nothing is scheduled until the run finishes
+	c.Advance(5 * time.Second)
+	job.release <- true
+
+	c.WaitUntilSleeping(1)
+
+	if next := e.Next(); !next.Equal(clock.Epoch.Add(7 * time.Second)) {
+		t.Errorf("Expected the next run after 7s, got %v", next.Sub(clock.Epoch))
+	}
+}
+
+func TestOverlap(t *testing.T) {
+
+	tests := []struct {
+		overlap Overlap
+
+		// Runs in progress after the second is due
+		running int
+		skipped int
+	}{
+		{Skip, 1, 1},
+		{Queue, 1, 0},
+		{Allow, 2, 0},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.overlap.String(), func(t *testing.T) {
+			leak.VerifyNone(t)
+
+			c := clock.NewVirtualClock(clock.Epoch, 0)
+			s := New(c)
+			defer s.Stop()
+
+			job := newBlockingJob()
+			e := add(t, s, Job{Schedule: Every(time.Second), Overlap: test.overlap, Run: job.run})
+
+			step(c, time.Second)
+			<-job.started
+
+			// The loop goes back to sleep once it has dealt with the second run
+			step(c, time.Second)
+			c.WaitUntilSleeping(1)
+
+			if test.running == 2 {
+				<-job.started
+			}
+
+			if job.count() != test.running || e.Skipped() != test.skipped {
+				t.Fatalf("Expected %d running and %d skipped, got %d and %d", test.running, test.skipped, job.count(), e.Skipped())
+			}
+
+			job.release <- true
+
+			if test.overlap == Queue {
+
+				// The queued run starts once the first finishes
+				<-job.started
+
+				if e.Runs() != 2 || job.count() != 1 {
+					t.Errorf("Expected the queued run to be the only one running, got %d runs and %d running", e.Runs(), job.count())
+				}
+			}
+		})
+	}
+}
+
+func TestJitter(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	s := New(c)
+	defer s.Stop()
+
+	s.random = func(n int64) int64 {
+
+		if n != int64(time.Second) {
+			t.Errorf("Expected jitter up to 1s, got %v", time.Duration(n))
+		}
+
+		return int64(300 * time.Millisecond)
+	}
+
+	ran := make(chan bool, 1)
+
+	e := add(t, s, Job{Schedule: Every(time.Minute), Jitter: time.Second, Run: func(ctx context.Context) error {
+		ran <- true
+		return nil
+	}})
+
+	step(c, time.Minute)
+
+	if e.Runs() != 0 {
+		t.Fatalf("Expected the run to be delayed by the jitter")
+	}
+
+	c.Advance(300 * time.Millisecond)
+	<-ran
+}
+
+func TestCancel(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	s := New(c)
+	defer s.Stop()
+
+	failures := make(chan error, 1)
+	job := newBlockingJob()
+
+	e := add(t, s, Job{Schedule: Every(time.Second), Run: job.run, OnError: func(err error) { failures <- err }})
+
+	step(c, time.Second)
+	<-job.started
+
+	// Cancelling the job cancels its run and stops it being scheduled
+	e.Cancel()
+
+	if err := <-failures; err != context.Canceled {
+		t.Errorf("Expected the run to be cancelled, got %v", err)
+	}
+
+	s.Stop()
+
+	if _, err := e.Last(); !e.Next().IsZero() || err != context.Canceled {
+		t.Errorf("Expected no next run and a cancelled last run, got %v and %v", e.Next(), err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	s := New(c)
+	defer s.Stop()
+
+	failures := make(chan error, 1)
+
+	// Contexts made from the run's context time out too
+	run := func(ctx context.Context) error {
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	add(t, s, Job{Schedule: Every(time.Second), Timeout: 500 * time.Millisecond, Run: run, OnError: func(err error) { failures <- err }})
+
+	// Runs that take too long are cancelled, once the timeout has passed on the scheduler's clock
+	step(c, time.Second)
+	c.WaitUntilSleeping(2)
+	c.Advance(499 * time.Millisecond)
+
+	select {
+	case err := <-failures:
+		t.Fatalf("Expected the run to go on until the timeout, got %v", err)
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+
+	if err := <-failures; err != context.DeadlineExceeded {
+		t.Errorf("Expected the run to time out, got %v", err)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	s := New(c)
+	defer s.Stop()
+
+	failures := make(chan error, 1)
+
+	run := func(ctx context.Context) error {
+		panic("out of gophers")
+	}
+
+	e := add(t, s, Job{Schedule: Every(time.Second), Run: run, OnError: func(err error) { failures <- err }})
+
+	// A run that panics fails, and the job (and the program) carries on
+	for i := 0; i < 2; i++ {
+
+		step(c, time.Second)
+
+		var p *group.PanicError
+
+		if err := <-failures; !errors.As(err, &p) || p.Value != "out of gophers" {
+			t.Fatalf("Expected the panic to be returned as an error, got %v", err)
+		}
+	}
+
+	if e.Runs() != 2 {
+		t.Errorf("Expected 2 runs, got %d", e.Runs())
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	s := New(c)
+
+	job := newBlockingJob()
+	e := add(t, s, Job{Schedule: Every(time.Second), Overlap: Queue, Run: job.run})
+
+	step(c, time.Second)
+	<-job.started
+
+	// This run is queued, and dropped by the shutdown
+	step(c, time.Second)
+	c.WaitUntilSleeping(1)
+
+	// Shutdown waits for the run in progress
+	done := make(chan error)
+
+	go func() {
+		done <- s.Shutdown(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected Shutdown to wait for the run, returned %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	job.release <- true
+
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if e.Runs() != 1 {
+		t.Errorf("Expected the queued run to be dropped, got %d runs", e.Runs())
+	}
+
+	if _, err := s.Add(Job{Schedule: Every(time.Second), Run: job.run}); err != ErrStopped {
+		t.Errorf("Expected adding a job after shutting down to fail, got %v", err)
+	}
+}
+
+func TestShutdownTimesOut(t *testing.T) {
+	leak.VerifyNone(t)
+
+	c := clock.NewVirtualClock(clock.Epoch, 0)
+	s := New(c)
+
+	job := newBlockingJob()
+	add(t, s, Job{Schedule: Every(time.Second), Run: job.run})
+
+	step(c, time.Second)
+	<-job.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// The run only stops when its context is cancelled
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the shutdown to time out, got %v", err)
+	}
+
+	if job.count() != 0 {
+		t.Errorf("Expected the run to have been cancelled")
+	}
+}
+
+func TestCron(t *testing.T) {
+
+	// A Wednesday
+	start := time.Date(2020, time.January, 1, 9, 10, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		next []string
+	}{
+		{"* * * * *", []string{"2020-01-01 09:11", "2020-01-01 09:12"}},
+		{"*/15 * * * *", []string{"2020-01-01 09:15", "2020-01-01 09:30"}},
+		{"0 9-17/4 * * *", []string{"2020-01-01 13:00", "2020-01-01 17:00", "2020-01-02 09:00"}},
+		{"30 9 * * 1-5", []string{"2020-01-01 09:30", "2020-01-02 09:30", "2020-01-03 09:30", "2020-01-06 09:30"}},
+		{"0 0 1,15 * *", []string{"2020-01-15 00:00", "2020-02-01 00:00"}},
+		{"0 12 * * 7", []string{"2020-01-05 12:00"}},
+		{"@monthly", []string{"2020-02-01 00:00", "2020-03-01 00:00"}},
+
+		// With both days restricted, either can match
+		{"0 0 13 * 5", []string{"2020-01-03 00:00", "2020-01-10 00:00", "2020-01-13 00:00"}},
+
+		// A day field starting with * isn't restricted, even with a step, so both days must match
+		{"0 0 */2 * 1", []string{"2020-01-13 00:00", "2020-01-27 00:00"}},
+
+		{"0 0 29 2 *", []string{"2020-02-29 00:00", "2024-02-29 00:00"}},
+		{"0 0 30 2 *", []string{"0001-01-01 00:00"}},
+	}
+
+	for _, test := range tests {
+
+		s, err := Cron(test.expr)
+
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", test.expr, err)
+			continue
+		}
+
+		at := start
+
+		for _, expected := range test.next {
+
+			at = s.Next(at)
+
+			if got := at.Format("2006-01-02 15:04"); got != expected {
+				t.Errorf("Expected %q to run at %s, got %s", test.expr, expected, got)
+				break
+			}
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "5/2 * * * *", "a * * * *", "*/0 * * * *", "5-1 * * * *"} {
+
+		if _, err := Cron(expr); err == nil {
+			t.Errorf("Expected %q to be invalid", expr)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package set
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// A Locked set is a Set guarded by a sync.RWMutex, so it is safe to use from multiple goroutines. Any number of
+// goroutines can read it at once, but writes take turns and wait for reads to finish, so it suits sets that are read
+// more than they are written. The zero value is an empty set, ready to use.
+type Locked[T comparable] struct {
+	mu sync.RWMutex
+	s  Set[T]
+}
+
+// NewLocked creates a Locked set containing elems
+func NewLocked[T comparable](elems ...T) *Locked[T] {
+	return &Locked[T]{s: New(elems...)}
+}
+
+// Add adds elems to the set
+func (l *Locked[T]) Add(elems ...T) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.s == nil {
+		l.s = make(Set[T])
+	}
+
+	l.s.Add(elems...)
+}
+
+// AddIfAbsent adds e to the set and returns true, or returns false if e was already in the set. Checking with Contains
+// and then calling Add would let another goroutine add e in between.
+func (l *Locked[T]) AddIfAbsent(e T) bool {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.s.Contains(e) {
+		return false
+	}
+
+	if l.s == nil {
+		l.s = make(Set[T])
+	}
+
+	l.s.Add(e)
+
+	return true
+}
+
+// Remove removes elems from the set
+func (l *Locked[T]) Remove(elems ...T) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.s.Remove(elems...)
+}
+
+// Contains reports whether e is in the set
+func (l *Locked[T]) Contains(e T) bool {
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.s.Contains(e)
+}
+
+// Len returns the number of elements in the set
+func (l *Locked[T]) Len() int {
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.s)
+}
+
+// Snapshot returns a copy of the set as it is now
+func (l *Locked[T]) Snapshot() Set[T] {
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.s.Clone()
+}
+
+// Sorted returns the elements in order
+func (l *Locked[T]) Sorted() []T {
+	return l.Snapshot().Sorted()
+}
+
+// MarshalJSON encodes the set as an array of its sorted elements
+func (l *Locked[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Sorted())
+}
+
+// UnmarshalJSON replaces the contents of the set with the elements of a JSON array
+func (l *Locked[T]) UnmarshalJSON(data []byte) error {
+
+	var s Set[T]
+
+	if err := s.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.s = s
+	l.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,227 @@
+// Package set provides sets of any comparable type, in three variants:
+//
+//	Set      a map with methods. Like any map, it isn't safe to use from more than one goroutine at once.
+//	Locked   a Set guarded by a sync.RWMutex, for sets that are mostly read.
+//	Sharded  a set split into shards, each with its own lock, for sets that many goroutines write to at once.
+//
+// Set has the usual set operations (union, intersection, difference and subset tests). The other two variants are
+// for sharing a set between goroutines; use their Snapshot method to get a Set to do set operations on.
+//
+//	fruit := set.New("apple", "pear")
+//	veg := set.New("potato", "tomato")
+//
+//	fruit.Add("tomato")
+//	fruit.Intersection(veg).Sorted() // [tomato]
+//
+// Sorted returns the elements in order: by value for numbers, strings and bools (and types based on them), and by
+// their formatted value (with %v) for anything else. Sets are marshalled to JSON as sorted arrays.
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// A Set is an unordered collection of distinct elements. The zero value is a nil map, so use New or make before adding
+// elements.
+type Set[T comparable] map[T]struct{}
+
+// New creates a set containing elems
+func New[T comparable](elems ...T) Set[T] {
+
+	s := make(Set[T], len(elems))
+	s.Add(elems...)
+
+	return s
+}
+
+// Add adds elems to the set
+func (s Set[T]) Add(elems ...T) {
+
+	for _, e := range elems {
+		s[e] = struct{}{}
+	}
+}
+
+// Remove removes elems from the set
+func (s Set[T]) Remove(elems ...T) {
+
+	for _, e := range elems {
+		delete(s, e)
+	}
+}
+
+// Contains reports whether e is in the set
+func (s Set[T]) Contains(e T) bool {
+
+	_, okay := s[e]
+
+	return okay
+}
+
+// Len returns the number of elements in the set
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Clone returns a copy of the set
+func (s Set[T]) Clone() Set[T] {
+
+	c := make(Set[T], len(s))
+
+	for e := range s {
+		c[e] = struct{}{}
+	}
+
+	return c
+}
+
+// Union returns a new set of the elements in either set
+func (s Set[T]) Union(other Set[T]) Set[T] {
+
+	u := s.Clone()
+
+	for e := range other {
+		u[e] = struct{}{}
+	}
+
+	return u
+}
+
+// Intersection returns a new set of the elements in both sets
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+
+	// Loop over the smaller set
+	small, large := s, other
+
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+
+	i := make(Set[T])
+
+	for e := range small {
+		if large.Contains(e) {
+			i[e] = struct{}{}
+		}
+	}
+
+	return i
+}
+
+// Difference returns a new set of the elements in s that aren't in other
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+
+	d := make(Set[T])
+
+	for e := range s {
+		if !other.Contains(e) {
+			d[e] = struct{}{}
+		}
+	}
+
+	return d
+}
+
+// SubsetOf reports whether every element of s is in other
+func (s Set[T]) SubsetOf(other Set[T]) bool {
+
+	if len(s) > len(other) {
+		return false
+	}
+
+	for e := range s {
+		if !other.Contains(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SupersetOf reports whether every element of other is in s
+func (s Set[T]) SupersetOf(other Set[T]) bool {
+	return other.SubsetOf(s)
+}
+
+// Equal reports whether the sets have the same elements
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.SubsetOf(other)
+}
+
+// Sorted returns the elements in order (see the package documentation for what the order is)
+func (s Set[T]) Sorted() []T {
+	return s.SortedFunc(less[T])
+}
+
+// SortedFunc returns the elements sorted by less
+func (s Set[T]) SortedFunc(less func(a, b T) bool) []T {
+
+	elems := make([]T, 0, len(s))
+
+	for e := range s {
+		elems = append(elems, e)
+	}
+
+	sort.Slice(elems, func(i, j int) bool {
+		return less(elems[i], elems[j])
+	})
+
+	return elems
+}
+
+func (s Set[T]) String() string {
+	return fmt.Sprintf("%v", s.Sorted())
+}
+
+// MarshalJSON encodes the set as an array of its sorted elements
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Sorted())
+}
+
+// UnmarshalJSON replaces the contents of the set with the elements of a JSON array. Duplicates are ignored.
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+
+	var elems []T
+
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+
+	*s = New(elems...)
+
+	return nil
+}
+
+// less orders values of basic kinds by value and anything else by its formatted value. Kinds are compared before
+// values, so that a set of interface{} holding a mixture of types is still sorted consistently.
+func less[T comparable](a, b T) bool {
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	// Interfaces holding nil have no kind
+	if !va.IsValid() || !vb.IsValid() {
+		return !va.IsValid() && vb.IsValid()
+	}
+
+	if va.Kind() != vb.Kind() {
+		return va.Kind() < vb.Kind()
+	}
+
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return va.Int() < vb.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return va.Uint() < vb.Uint()
+	case reflect.Float32, reflect.Float64:
+		return va.Float() < vb.Float()
+	case reflect.String:
+		return va.String() < vb.String()
+	case reflect.Bool:
+		return !va.Bool() && vb.Bool()
+	}
+
+	return fmt.Sprintf("%v", a) < fmt.Sprintf("%v", b)
+}
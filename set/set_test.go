@@ -0,0 +1,353 @@
+package set
+
+import (
+	"encoding/json"
+	"github.com/benhalstead/gotraining/leak"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestOperations(t *testing.T) {
+
+	a := New(1, 2, 3, 4)
+	b := New(3, 4, 5)
+
+	tests := []struct {
+		name     string
+		got      Set[int]
+		expected []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"intersection", a.Intersection(b), []int{3, 4}},
+		{"difference", a.Difference(b), []int{1, 2}},
+		{"reverse difference", b.Difference(a), []int{5}},
+	}
+
+	for _, test := range tests {
+		if got := test.got.Sorted(); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Expected the %s to be %v, got %v", test.name, test.expected, got)
+		}
+	}
+
+	// The operations don't change the sets they are given
+	if a.Len() != 4 || b.Len() != 3 {
+		t.Errorf("Expected the sets to be unchanged, got %v and %v", a, b)
+	}
+
+	small := New(3, 4)
+
+	if !small.SubsetOf(a) || !a.SupersetOf(small) || a.SubsetOf(small) || b.SubsetOf(a) {
+		t.Errorf("Unexpected subset test result")
+	}
+
+	if !New(4, 3).Equal(small) || small.Equal(b) || !New[int]().SubsetOf(small) {
+		t.Errorf("Unexpected equality test result")
+	}
+
+	a.Remove(1, 2)
+
+	if a.Contains(1) || !a.Contains(3) {
+		t.Errorf("Expected 1 to be removed, got %v", a)
+	}
+}
+
+type version struct {
+	major, minor int
+}
+
+func TestSorted(t *testing.T) {
+
+	type colour string
+
+	if got := New[colour]("red", "green", "blue").Sorted(); !reflect.DeepEqual(got, []colour{"blue", "green", "red"}) {
+		t.Errorf("Unexpected order %v", got)
+	}
+
+	if got := New(2.5, -1, 10).Sorted(); !reflect.DeepEqual(got, []float64{-1, 2.5, 10}) {
+		t.Errorf("Unexpected order %v", got)
+	}
+
+	// Other types are ordered by their formatted values
+	if got := New(version{1, 10}, version{1, 2}).String(); got != "[{1 10} {1 2}]" {
+		t.Errorf("Unexpected order %s", got)
+	}
+
+	// Mixed types are ordered by kind first
+	if got := New[interface{}]("a", 2, true, nil, 1).Sorted(); !reflect.DeepEqual(got, []interface{}{nil, true, 1, 2, "a"}) {
+		t.Errorf("Unexpected order %v", got)
+	}
+
+	byLength := func(a, b string) bool {
+		return len(a) < len(b)
+	}
+
+	if got := New("ccc", "a", "bb").SortedFunc(byLength); !reflect.DeepEqual(got, []string{"a", "bb", "ccc"}) {
+		t.Errorf("Unexpected order %v", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+
+	doc := struct {
+		Tags   Set[string]
+		Counts *Locked[int]
+		IDs    *Sharded[int]
+	}{New("b", "a", "c"), NewLocked(3, 1, 2), NewSharded[int](4)}
+
+	doc.IDs.Add(20, 10)
+
+	data, err := json.Marshal(doc)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if expected := `{"Tags":["a","b","c"],"Counts":[1,2,3],"IDs":[10,20]}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	var decoded struct {
+		Tags   Set[string]
+		Counts *Locked[int]
+		IDs    *Sharded[int]
+	}
+
+	if err := json.Unmarshal([]byte(`{"Tags":["x","y","x"],"Counts":[7],"IDs":[5,6]}`), &decoded); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !decoded.Tags.Equal(New("x", "y")) || !decoded.Counts.Snapshot().Equal(New(7)) || !decoded.IDs.Snapshot().Equal(New(5, 6)) {
+		t.Errorf("Unexpected sets %v %v %v", decoded.Tags, decoded.Counts.Sorted(), decoded.IDs.Sorted())
+	}
+
+	if err := json.Unmarshal([]byte(`["x", 1]`), &decoded.Tags); err == nil {
+		t.Errorf("Expected an error decoding a number into a set of strings")
+	}
+}
+
+// concurrent is implemented by both concurrency-safe sets
+type concurrent interface {
+	Add(elems ...int)
+	AddIfAbsent(e int) bool
+	Remove(elems ...int)
+	Contains(e int) bool
+	Len() int
+	Snapshot() Set[int]
+}
+
+func TestConcurrent(t *testing.T) {
+
+	sets := map[string]func() concurrent{
+		"locked":  func() concurrent { return new(Locked[int]) },
+		"sharded": func() concurrent { return NewSharded[int](8) },
+	}
+
+	for name, create := range sets {
+
+		t.Run(name, func(t *testing.T) {
+			leak.VerifyNone(t)
+
+			s := create()
+
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			added := 0
+
+			// Every goroutine tries to add every element, but each is only added once
+			for g := 0; g < 8; g++ {
+
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					for i := 0; i < 1000; i++ {
+
+						if s.AddIfAbsent(i) {
+							mu.Lock()
+							added++
+							mu.Unlock()
+						}
+
+						s.Contains(i)
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			if added != 1000 || s.Len() != 1000 || s.Snapshot().Len() != 1000 {
+				t.Fatalf("Expected 1000 elements to be added once each, %d were added and the set has %d", added, s.Len())
+			}
+
+			s.Remove(0, 1)
+			s.Add(1)
+
+			if s.Contains(0) || !s.Contains(1) || s.Len() != 999 {
+				t.Errorf("Unexpected contents after removing and adding")
+			}
+		})
+	}
+}
+
+func TestShardHashes(t *testing.T) {
+
+	sh := NewSharded[interface{}](64)
+
+	// Values that are == must be in the same shard
+	pairs := [][2]interface{}{
+		{0.0, math.Copysign(0, -1)},
+		{version{1, 2}, version{1, 2}},
+		{[2]string{"a", "b"}, [2]string{"a", "b"}},
+		{"gopher", "gopher"},
+	}
+
+	for _, p := range pairs {
+
+		if sh.shard(p[0]) != sh.shard(p[1]) {
+			t.Errorf("Expected %v and %v to be in the same shard", p[0], p[1])
+		}
+
+		sh.Add(p[0])
+
+		if !sh.Contains(p[1]) {
+			t.Errorf("Expected the set to contain %v", p[1])
+		}
+	}
+}
+
+// The benchmarks compare the sets with the pattern used in the mutex lesson's exampleMutex: a slice guarded by a
+// sync.Mutex. Every goroutine adds elements and checks whether elements are present.
+//
+//	go test -bench . ./set
+const benchElements = 1000
+
+type mutexSlice struct {
+	mu sync.Mutex
+	v  []int
+}
+
+func (m *mutexSlice) Add(e int) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, x := range m.v {
+		if x == e {
+			return
+		}
+	}
+
+	m.v = append(m.v, e)
+}
+
+func (m *mutexSlice) Contains(e int) bool {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, x := range m.v {
+		if x == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+type mutexSet struct {
+	mu sync.Mutex
+	s  Set[int]
+}
+
+func (m *mutexSet) Add(e int) {
+	m.mu.Lock()
+	m.s.Add(e)
+	m.mu.Unlock()
+}
+
+func (m *mutexSet) Contains(e int) bool {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.s.Contains(e)
+}
+
+type lockedAdapter struct {
+	*Locked[int]
+}
+
+func (l lockedAdapter) Add(e int) {
+	l.Locked.Add(e)
+}
+
+type shardedAdapter struct {
+	*Sharded[int]
+}
+
+func (s shardedAdapter) Add(e int) {
+	s.Sharded.Add(e)
+}
+
+type benchSet interface {
+	Add(e int)
+	Contains(e int) bool
+}
+
+func benchmarkSets() []struct {
+	name   string
+	create func() benchSet
+} {
+	return []struct {
+		name   string
+		create func() benchSet
+	}{
+		{"mutex-slice", func() benchSet { return &mutexSlice{} }},
+		{"mutex-set", func() benchSet { return &mutexSet{s: New[int]()} }},
+		{"locked", func() benchSet { return lockedAdapter{NewLocked[int]()} }},
+		{"sharded", func() benchSet { return shardedAdapter{NewSharded[int](0)} }},
+	}
+}
+
+// benchmark runs b.N operations spread over GOMAXPROCS goroutines. writes is the percentage of them that are adds.
+func benchmark(b *testing.B, writes int) {
+
+	for _, bs := range benchmarkSets() {
+
+		b.Run(bs.name, func(b *testing.B) {
+
+			s := bs.create()
+
+			for i := 0; i < benchElements; i += 2 {
+				s.Add(i)
+			}
+
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+
+				for i := 0; pb.Next(); i++ {
+
+					e := i % benchElements
+
+					if i%100 < writes {
+						s.Add(e)
+					} else {
+						s.Contains(e)
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkMostlyReads(b *testing.B) {
+	benchmark(b, 10)
+}
+
+func BenchmarkMostlyWrites(b *testing.B) {
+	benchmark(b, 90)
+}
@@ -0,0 +1,256 @@
+package set
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// DefaultShards is the number of shards used by NewSharded when it isn't given a number
+const DefaultShards = 32
+
+// A Sharded set is split into shards, each a Set with its own lock, and each element belongs to the shard chosen by
+// its hash. Goroutines adding or removing elements in different shards don't wait for each other, so it suits sets
+// that many goroutines write to at once. Operations on the whole set (Len, Snapshot) lock each shard in turn, so they
+// don't see a single moment in the set's history if it is being changed.
+type Sharded[T comparable] struct {
+	seed   maphash.Seed
+	shards []shard[T]
+}
+
+type shard[T comparable] struct {
+	mu sync.RWMutex
+	s  Set[T]
+
+	// Stops neighbouring shards sharing a cache line, which would make goroutines writing to them wait for each other
+	// after all
+	_ [64]byte
+}
+
+// NewSharded creates an empty Sharded set with the given number of shards (DefaultShards if n isn't positive)
+func NewSharded[T comparable](n int) *Sharded[T] {
+
+	if n <= 0 {
+		n = DefaultShards
+	}
+
+	sh := &Sharded[T]{seed: maphash.MakeSeed(), shards: make([]shard[T], n)}
+
+	for i := range sh.shards {
+		sh.shards[i].s = make(Set[T])
+	}
+
+	return sh
+}
+
+func (sh *Sharded[T]) shard(e T) *shard[T] {
+	return &sh.shards[sh.hash(e)%uint64(len(sh.shards))]
+}
+
+func (sh *Sharded[T]) hash(e T) uint64 {
+
+	// Avoid reflection for the most common types
+	switch v := interface{}(e).(type) {
+	case int:
+		return mix(uint64(v))
+	case string:
+		return maphash.String(sh.seed, v)
+	}
+
+	var h maphash.Hash
+	h.SetSeed(sh.seed)
+
+	hashValue(&h, reflect.ValueOf(e))
+
+	return h.Sum64()
+}
+
+// mix spreads the bits of an integer, so that consecutive integers end up in different shards
+func mix(v uint64) uint64 {
+
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+
+	return v
+}
+
+// Add adds elems to the set
+func (sh *Sharded[T]) Add(elems ...T) {
+
+	for _, e := range elems {
+
+		s := sh.shard(e)
+
+		s.mu.Lock()
+		s.s.Add(e)
+		s.mu.Unlock()
+	}
+}
+
+// AddIfAbsent adds e to the set and returns true, or returns false if e was already in the set
+func (sh *Sharded[T]) AddIfAbsent(e T) bool {
+
+	s := sh.shard(e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.s.Contains(e) {
+		return false
+	}
+
+	s.s.Add(e)
+
+	return true
+}
+
+// Remove removes elems from the set
+func (sh *Sharded[T]) Remove(elems ...T) {
+
+	for _, e := range elems {
+
+		s := sh.shard(e)
+
+		s.mu.Lock()
+		s.s.Remove(e)
+		s.mu.Unlock()
+	}
+}
+
+// Contains reports whether e is in the set
+func (sh *Sharded[T]) Contains(e T) bool {
+
+	s := sh.shard(e)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.Contains(e)
+}
+
+// Len returns the number of elements in the set
+func (sh *Sharded[T]) Len() int {
+
+	n := 0
+
+	for i := range sh.shards {
+
+		s := &sh.shards[i]
+
+		s.mu.RLock()
+		n += len(s.s)
+		s.mu.RUnlock()
+	}
+
+	return n
+}
+
+// Snapshot returns a copy of the set
+func (sh *Sharded[T]) Snapshot() Set[T] {
+
+	c := make(Set[T])
+
+	for i := range sh.shards {
+
+		s := &sh.shards[i]
+
+		s.mu.RLock()
+
+		for e := range s.s {
+			c[e] = struct{}{}
+		}
+
+		s.mu.RUnlock()
+	}
+
+	return c
+}
+
+// Sorted returns the elements in order
+func (sh *Sharded[T]) Sorted() []T {
+	return sh.Snapshot().Sorted()
+}
+
+// MarshalJSON encodes the set as an array of its sorted elements
+func (sh *Sharded[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sh.Sorted())
+}
+
+// UnmarshalJSON adds the elements of a JSON array to the set. Unlike Set and Locked it doesn't remove the set's
+// existing elements first, as the set can't be replaced all at once.
+func (sh *Sharded[T]) UnmarshalJSON(data []byte) error {
+
+	var elems []T
+
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+
+	if sh.shards == nil {
+		*sh = *NewSharded[T](0)
+	}
+
+	sh.Add(elems...)
+
+	return nil
+}
+
+func writeUint(h *maphash.Hash, v uint64) {
+
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+
+	h.Write(b[:])
+}
+
+// hashValue writes v to h so that values that are == write the same bytes
+func hashValue(h *maphash.Hash, v reflect.Value) {
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		h.WriteByte(0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(h, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeFloat(h, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		writeFloat(h, real(v.Complex()))
+		writeFloat(h, imag(v.Complex()))
+	case reflect.String:
+		h.WriteString(v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			h.WriteByte(1)
+		} else {
+			h.WriteByte(0)
+		}
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		writeUint(h, uint64(v.Pointer()))
+	case reflect.Interface:
+		hashValue(h, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(h, v.Field(i))
+		}
+	}
+}
+
+func writeFloat(h *maphash.Hash, f float64) {
+
+	// 0 == -0, so they must hash the same
+	if f == 0 {
+		f = 0
+	}
+
+	writeUint(h, math.Float64bits(f))
+}
@@ -0,0 +1,160 @@
+package snapshot
+
+import (
+	"flag"
+	"github.com/benhalstead/gotraining/tutorial"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output of each lesson")
+
+// The repository root, relative to this package
+const root = ".."
+
+// TestLessons runs every section of every lesson in the README's reading order and compares its output with the
+// section's golden file
+func TestLessons(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("lessons take several seconds to run")
+	}
+
+	dir := t.TempDir()
+
+	for _, id := range tutorial.LessonIDs() {
+
+		id := id
+
+		t.Run(id, func(t *testing.T) {
+
+			// Some lessons (like unittests/code) are tests rather than programs
+			if !isMain(t, id) {
+				t.Skipf("%s is not a runnable lesson", id)
+			}
+
+			t.Parallel()
+
+			l, err := Build(root, id, dir)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Each section runs in its own process, so sections can't rely on each other. They run one after another
+			// only so that the lessons running in parallel don't start a process for every section at once.
+			for _, s := range l.Sections {
+				t.Run(s, func(t *testing.T) {
+					checkSection(t, l, s)
+				})
+			}
+
+			checkOrphans(t, l)
+		})
+	}
+}
+
+// TestGoldenFilesHaveLessons checks that every directory of golden files belongs to a lesson
+func TestGoldenFilesHaveLessons(t *testing.T) {
+
+	ids := make(map[string]bool)
+
+	for _, id := range tutorial.LessonIDs() {
+		ids[id] = true
+	}
+
+	err := filepath.WalkDir("testdata", func(p string, d fs.DirEntry, err error) error {
+
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".golden" {
+			return err
+		}
+
+		rel, err := filepath.Rel("testdata", filepath.Dir(p))
+
+		if err != nil {
+			return err
+		}
+
+		if id := filepath.ToSlash(rel); !ids[id] {
+			t.Errorf("%s has no lesson %s (delete it if the lesson was removed or renamed)", p, id)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkOrphans fails if a lesson has a golden file for a section it doesn't have
+func checkOrphans(t *testing.T, l *Lesson) {
+
+	sections := make(map[string]bool)
+
+	for _, s := range l.Sections {
+		sections[s] = true
+	}
+
+	goldens, err := filepath.Glob(filepath.Join("testdata", filepath.FromSlash(l.ID), "*.golden"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, g := range goldens {
+		if s := strings.TrimSuffix(filepath.Base(g), ".golden"); !sections[s] {
+			t.Errorf("%s has no section %s (delete it if the section was removed or renamed)", g, s)
+		}
+	}
+}
+
+func checkSection(t *testing.T, l *Lesson, section string) {
+
+	r := RuleFor(l.ID, section)
+
+	if r.Skip != "" {
+		t.Skip(r.Skip)
+	}
+
+	out, err := l.Run(section)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := r.Normalise(out)
+
+	if *update {
+		if err := WriteGolden(l.ID, section, got); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	want, err := ReadGolden(l.ID, section)
+
+	if err != nil {
+		t.Fatalf("No golden file for %s#%s (run with -update to create it): %s", l.ID, section, err.Error())
+	}
+
+	if !r.Matches(got, want) {
+		t.Errorf("Output of %s#%s does not match %s\n--- got:\n%s\n--- want:\n%s", l.ID, section, GoldenPath(l.ID, section), got, want)
+	}
+}
+
+func isMain(t *testing.T, id string) bool {
+
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(root, filepath.FromSlash(id+".go")), nil, parser.PackageClauseOnly)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name.Name == "main"
+}
@@ -0,0 +1,54 @@
+package snapshot
+
+// Masks applied to the output of every section
+var defaultMasks = []Mask{
+	// Memory addresses (pointers, channels, function values)
+	NewMask(`0x[0-9a-f]+`, "0xADDRESS"),
+
+	// time.Now() printed with %v, which includes the monotonic clock reading. Times parsed from fixed strings have
+	// no monotonic reading so are still compared.
+	NewMask(`\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? [+-]\d{4} \S+ m=[+-]\d+\.\d+`, "TIME"),
+}
+
+// Masks for goroutines appending to a shared slice, where the order of the contents varies between runs
+var sharedSliceMasks = []Mask{
+	NewMask(`\[[\d ]*\]`, "[...]"),
+}
+
+// rules holds the exceptions to an exact comparison, keyed by lesson#section. Every other section must match its
+// golden file exactly once the default masks have been applied.
+var rules = map[string]Rule{
+	// Iterating over a map visits keys in a random order
+	"structures/maps#literals": {Unordered: true},
+
+	// goroutines print concurrently
+	"concurrency/goroutines#outlive": {Unordered: true},
+	"concurrency/goroutines#leaks": {
+		Masks: []Mask{NewMask(`answer \d`, "answer N")},
+	},
+	"concurrency/channels#basics":  {Unordered: true},
+	"concurrency/channels#closing": {Unordered: true},
+	"concurrency/mutex#mutex":      {Unordered: true, Masks: sharedSliceMasks},
+	"concurrency/mutex#atomic": {
+		Unordered: true,
+		Masks:     []Mask{NewMask(`Currently active: \d+`, "Currently active: N")},
+	},
+
+	// The point of this section is that its output can't be predicted
+	"concurrency/mutex#race": {Skip: "exampleDataRace is deliberately nondeterministic"},
+
+	// Sections that need network access
+	"concurrency/channels#select": {Skip: "fetches remote web pages"},
+	"essential/http#get":          {Skip: "calls a remote web service"},
+	"essential/http#post":         {Skip: "calls a remote web service"},
+	"essential/http#request":      {Skip: "calls a remote web service"},
+}
+
+// RuleFor returns the rule for a section, including the masks that apply to every section
+func RuleFor(id, section string) Rule {
+
+	r := rules[id+"#"+section]
+	r.Masks = append(append([]Mask(nil), defaultMasks...), r.Masks...)
+
+	return r
+}
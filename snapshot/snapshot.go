@@ -0,0 +1,163 @@
+// Package snapshot runs the sections of each lesson and compares what they print with golden files checked in
+// under testdata, so that the output promised by the lessons' comments can't silently change.
+//
+// Run the comparison with:
+//
+//	go test ./snapshot
+//
+// and after a deliberate change to a lesson's output, rewrite the golden files with:
+//
+//	go test ./snapshot -update
+//
+// Lessons are run with a replay clock (see tutorial.ClockEnvVar), so sections that sleep finish instantly and the
+// durations they measure are the same on every run. Sections whose output is still not deterministic (because it
+// includes the current time, memory addresses or the interleaving of goroutines) have Rules that mask or relax the
+// comparison. See rules.go.
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/benhalstead/gotraining/tutorial"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A Rule controls how the output of a section is compared with its golden file
+type Rule struct {
+	// If set, the section is not run and this is the reason given
+	Skip string
+
+	// Masks are applied, in order, to the output before it is compared or written to a golden file
+	Masks []Mask
+
+	// If true, the lines of the output are compared without regard to their order. Use this for sections where
+	// goroutines print concurrently.
+	Unordered bool
+}
+
+// A Mask replaces every match of Pattern with Replacement
+type Mask struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// NewMask compiles pattern and creates a Mask. It panics if the pattern is invalid.
+func NewMask(pattern, replacement string) Mask {
+	return Mask{Pattern: regexp.MustCompile(pattern), Replacement: replacement}
+}
+
+// Normalise applies the rule's masks to output
+func (r Rule) Normalise(output string) string {
+
+	for _, m := range r.Masks {
+		output = m.Pattern.ReplaceAllString(output, m.Replacement)
+	}
+
+	return output
+}
+
+// Matches reports whether normalised output is the same as the golden content under this rule
+func (r Rule) Matches(got, golden string) bool {
+
+	if !r.Unordered {
+		return got == golden
+	}
+
+	return sortedLines(got) == sortedLines(golden)
+}
+
+func sortedLines(s string) string {
+
+	lines := strings.Split(s, "\n")
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\n")
+}
+
+// A Lesson is a compiled copy of one lesson's source file
+type Lesson struct {
+	ID       string
+	Sections []string
+	binary   string
+}
+
+// Build compiles the lesson with the supplied ID (e.g. concurrency/channels) into dir and asks it for the names of
+// its sections. root is the directory containing this repository.
+func Build(root, id, dir string) (*Lesson, error) {
+
+	l := &Lesson{
+		ID:     id,
+		binary: filepath.Join(dir, strings.Replace(id, "/", "-", -1)),
+	}
+
+	build := exec.Command("go", "build", "-o", l.binary, filepath.FromSlash(id+".go"))
+	build.Dir = root
+
+	if b, err := build.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("building %s: %s\n%s", id, err.Error(), b)
+	}
+
+	list, err := l.command("-list").Output()
+
+	if err != nil {
+		return nil, fmt.Errorf("listing sections of %s: %s", id, err.Error())
+	}
+
+	l.Sections = strings.Fields(string(list))
+
+	return l, nil
+}
+
+// Run runs a single section of the lesson, returning everything it printed to stdout
+func (l *Lesson) Run(section string) (string, error) {
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := l.command(section)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running %s#%s: %s\n%s", l.ID, section, err.Error(), stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+func (l *Lesson) command(args ...string) *exec.Cmd {
+
+	cmd := exec.Command(l.binary, args...)
+	cmd.Env = append(os.Environ(), tutorial.ClockEnvVar+"=replay")
+
+	return cmd
+}
+
+// GoldenPath returns the path, relative to the snapshot package, of the golden file for a section
+func GoldenPath(id, section string) string {
+	return filepath.Join("testdata", filepath.FromSlash(id), section+".golden")
+}
+
+// ReadGolden returns the content of a section's golden file
+func ReadGolden(id, section string) (string, error) {
+
+	b, err := os.ReadFile(GoldenPath(id, section))
+
+	return string(b), err
+}
+
+// WriteGolden replaces the content of a section's golden file
+func WriteGolden(id, section, content string) error {
+
+	p := GoldenPath(id, section)
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(p, []byte(content), 0644)
+}
@@ -0,0 +1,39 @@
+// Package exercises contains exercises for the data structures lessons (structures/*.go). Replace the body of each
+// function so that it does what its comment describes, then check your answers with:
+//
+//	gotraining grade structures
+package exercises
+
+// Reverse returns a new slice containing the values in s in reverse order. s itself must not be changed.
+func Reverse(s []int) []int {
+	// TODO
+	return nil
+}
+
+// WordCount returns the number of times each word appears in s. Words are separated by whitespace
+// (strings.Fields will split them for you).
+func WordCount(s string) map[string]int {
+	// TODO
+	return nil
+}
+
+// Double doubles the int that i points to
+func Double(i *int) {
+	// TODO
+}
+
+type Rectangle struct {
+	Width  float64
+	Height float64
+}
+
+// Area returns the area of the rectangle
+func (r Rectangle) Area() float64 {
+	// TODO
+	return 0
+}
+
+// Scale multiplies the width and height of the rectangle by f. Think about which kind of receiver this method needs.
+func (r Rectangle) Scale(f float64) {
+	// TODO
+}
@@ -0,0 +1,67 @@
+package exercises
+
+import "testing"
+
+// Hint: make a new slice with make([]int, len(s)) and fill it from the end of s. See slices.go.
+func TestReverse(t *testing.T) {
+
+	s := []int{1, 2, 3}
+
+	r := Reverse(s)
+
+	if len(r) != 3 || r[0] != 3 || r[1] != 2 || r[2] != 1 {
+		t.Errorf("Expected [3 2 1] but found %v", r)
+	}
+
+	if s[0] != 1 {
+		t.Errorf("Reverse should not change its argument, but it is now %v", s)
+	}
+}
+
+// Hint: a map must be created with make before you can write to it, and a missing key reads as zero. See maps.go.
+func TestWordCount(t *testing.T) {
+
+	c := WordCount("the cat sat on the mat")
+
+	if c == nil {
+		t.Fatalf("Expected a map but found nil")
+	}
+
+	if c["the"] != 2 || c["cat"] != 1 || len(c) != 5 {
+		t.Errorf("Expected map[cat:1 mat:1 on:1 sat:1 the:2] but found %v", c)
+	}
+}
+
+// Hint: dereference the pointer with * to read and write the value it points to. See pointers.go.
+func TestDouble(t *testing.T) {
+
+	i := 21
+
+	Double(&i)
+
+	if i != 42 {
+		t.Errorf("Expected 42 but found %d", i)
+	}
+}
+
+// Hint: a value receiver gets a copy of the struct, which is fine when you only need to read it.
+func TestArea(t *testing.T) {
+
+	r := Rectangle{Width: 2, Height: 3}
+
+	if a := r.Area(); a != 6 {
+		t.Errorf("Expected an area of 6 but found %v", a)
+	}
+}
+
+// Hint: a method can only change the struct it is called on if it has a pointer receiver. See methods.go.
+func TestScale(t *testing.T) {
+
+	r := &Rectangle{Width: 2, Height: 3}
+
+	r.Scale(2)
+
+	if r.Width != 4 || r.Height != 6 {
+		t.Errorf("Expected a 4x6 rectangle but found %vx%v", r.Width, r.Height)
+	}
+}
@@ -7,6 +7,14 @@ import (
 
 func main() {
 
+	tutorial.Register("structures", "maps").
+		Section("maps", mapsExample).
+		Section("literals", mapLiteralsExample).
+		Run()
+}
+
+func mapsExample() {
+
 	tutorial.Section("Maps")
 
 	//Maps map a key to a value. Any type can be stored as a value, 'comparable' types can be used as keys
@@ -38,6 +46,9 @@ func main() {
 
 	// You could replace value with _ (blank identifier) in the above example if you just wanted to check that the map
 	// contained the value.
+}
+
+func mapLiteralsExample() {
 
 	//Maps can also be declared as literals - note the trailing comma after the last item - this is required
 	tutorial.Section("Maps literals")
@@ -63,5 +74,9 @@ func main() {
 
 	// Maps are not goroutine safe - behaviour if two goroutines modify a map simultaneously is undefined. Use a mutex to lock access
 	// https://blog.golang.org/go-maps-in-action
+	//
+	// The set package (see the methods lesson) has two sets that do this for you: set.Locked guards a map with a
+	// sync.RWMutex and set.Sharded splits it into pieces with a lock each, so goroutines writing to different pieces
+	// don't wait for each other
 
 }
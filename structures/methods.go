@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/benhalstead/gotraining/set"
 	"github.com/benhalstead/gotraining/tutorial"
 	"strconv"
 	"strings"
@@ -34,17 +35,15 @@ func (mi myInt) ToString() string {
 	return strconv.Itoa(int(mi))
 }
 
-type pseduoSet map[string]bool
-
-func (ps pseduoSet) Add(key string) {
-	ps[key] = true
-}
+func main() {
 
-func (ps pseduoSet) Contains(key string) bool {
-	return ps[key]
+	tutorial.Register("structures", "methods").
+		Section("struct-methods", structMethodsExample).
+		Section("other-types", otherTypesExample).
+		Run()
 }
 
-func main() {
+func structMethodsExample() {
 
 	tutorial.Section("Struct methods")
 
@@ -67,6 +66,9 @@ func main() {
 	// If the method was declared with a pointer receiver, it can modify the contents of that struct
 	pn.Normalise()
 	fmt.Printf("%s\n", pn.FullName())
+}
+
+func otherTypesExample() {
 
 	tutorial.Section("Methods on other types")
 
@@ -74,10 +76,22 @@ func main() {
 
 	fmt.Printf("String val: %s\n", mi.ToString())
 
-	set := make(pseduoSet)
+	// Maps can have methods too. The set package's Set is declared as
+	//
+	//	type Set[T comparable] map[T]struct{}
+	//
+	// and has methods like Add and Contains, so a map of strings can be used as a set of strings
+	fruit := set.New("APPLE", "PEAR")
+
+	fruit.Add("APPLE", "BANANA")
+
+	fmt.Printf("Contains APPLE? %t\n", fruit.Contains("APPLE"))
 
-	set.Add("APPLE")
+	// As it is still a map, len and range work on it. Maps aren't ordered, so Sorted is there for when the order matters.
+	fmt.Printf("%d fruit: %v\n", len(fruit), fruit.Sorted())
 
-	fmt.Printf("Contains APPLE? %t\n", set.Contains("APPLE"))
+	// Sets also have methods that combine them with other sets
+	yellow := set.New("BANANA", "LEMON")
 
+	fmt.Printf("Yellow fruit: %v\n", fruit.Intersection(yellow).Sorted())
 }
@@ -7,6 +7,14 @@ import (
 
 func main() {
 
+	tutorial.Register("structures", "pointers").
+		Section("call-by-value", callByValueExample).
+		Section("pointers", pointersExample).
+		Run()
+}
+
+func callByValueExample() {
+
 	// In Go, when you pass a variable to a function (with the exception of maps, slices and channels), the function will receive
 	// a COPY of that variable - if you change it inside the function, the original value is unaffected.
 
@@ -30,8 +38,14 @@ func main() {
 	addToMap(m)
 
 	fmt.Printf("After: %v\n", m)
+}
+
+func pointersExample() {
 
 	tutorial.Section("Pointers")
+
+	a := 2
+
 	// If we want a function to be able to modify (mutate) the passed variable, we need to pass a pointer to that variable
 	// Formally a pointer is the address in memory of a value
 
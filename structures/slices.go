@@ -7,6 +7,15 @@ import (
 
 func main() {
 
+	tutorial.Register("structures", "slices").
+		Section("creating", creatingSlicesExample).
+		Section("modifying", modifyingSlicesExample).
+		Section("arrays", arraysExample).
+		Run()
+}
+
+func creatingSlicesExample() {
+
 	// Go's name for an ordered, fixed size collection of values is slice. These are subtly different to arrays (which also exist in Go)
 	// and some Go fans get upset if you interchange the terms, but for 90% of use cases, slices behave like arrays
 
@@ -33,9 +42,14 @@ func main() {
 	ms[2] = 3
 
 	fmt.Printf("Length: %d\n", len(ms))
+}
+
+func modifyingSlicesExample() {
 
 	tutorial.Section("Modifying slices")
 
+	ms := []int{1, 2, 3}
+
 	//You can add an item (or several items) to a slice using the append builtin (remeber to assign the result of the function to a variable)
 	ms = append(ms, 4, 5, 6)
 
@@ -68,6 +82,9 @@ func main() {
 	for _, v := range ms {
 		fmt.Printf("%d\n", v)
 	}
+}
+
+func arraysExample() {
 
 	tutorial.Section("Arrays")
 
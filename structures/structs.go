@@ -8,6 +8,15 @@ import (
 
 func main() {
 
+	tutorial.Register("structures", "structs").
+		Section("variables", structVariablesExample).
+		Section("new", structFromNewExample).
+		Section("constructors", constructorsExample).
+		Run()
+}
+
+func structVariablesExample() {
+
 	tutorial.Section("Struct variables")
 
 	//Declaring an variable with a struct type gives you a valid but empty struct of that type
@@ -23,6 +32,9 @@ func main() {
 	}
 
 	fmt.Printf("Populated struct %#v\n", cd)
+}
+
+func structFromNewExample() {
 
 	tutorial.Section("Struct from new")
 
@@ -38,6 +50,9 @@ func main() {
 	ncd.WorkMobile = "+121254556"
 
 	fmt.Printf("Struct pointer %#v\n", ncd)
+}
+
+func constructorsExample() {
 
 	tutorial.Section("Define you own new methods")
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/benhalstead/gotraining/tutorial"
 	"reflect"
 )
 
@@ -18,6 +19,13 @@ type Custom struct {
 
 func main() {
 
+	tutorial.Register("structures", "tags").
+		Section("reflection", readingTagsExample).
+		Run()
+}
+
+func readingTagsExample() {
+
 	//Tags are static meta-data attached to a struct. The most common usage is to give hints to framework code,
 	// code that maps from one data structure to another. The example in Person
 	// above is real tags that instruct Go's JSON and XML decoders to use different names and types when serialising and deserialsing
@@ -0,0 +1,62 @@
+package tutorial
+
+import (
+	"github.com/benhalstead/gotraining/clock"
+	"os"
+	"sync"
+	"time"
+)
+
+// The Clock lessons use. Lessons that wait for something to happen call Now, Sleep and Since in this package rather
+// than the functions in the time package, and give CurrentClock to packages that take a clock.Clock, so that they can
+// be run instantly with a VirtualClock.
+var current = struct {
+	mu sync.Mutex
+	c  clock.Clock
+}{c: clock.RealClock{}}
+
+// SetClock replaces the Clock used by Now, Sleep and Since (and by the helpers in this package)
+func SetClock(c clock.Clock) {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+
+	current.c = c
+}
+
+// CurrentClock returns the Clock used by Now, Sleep and Since
+func CurrentClock() clock.Clock {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+
+	return current.c
+}
+
+// Now is the same as time.Now, but uses the tutorial's Clock
+func Now() time.Time {
+	return CurrentClock().Now()
+}
+
+// Sleep is the same as time.Sleep, but uses the tutorial's Clock
+func Sleep(d time.Duration) {
+	CurrentClock().Sleep(d)
+}
+
+// Since is the same as time.Since, but uses the tutorial's Clock
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// ClockEnvVar is the name of an environment variable that selects the Clock lessons use. If it is set to "replay",
+// lessons use a VirtualClock that advances automatically, so they run without waiting and the times they print are
+// the same on every run. Any other value (or no value) uses the RealClock.
+const ClockEnvVar = "GOTRAINING_CLOCK"
+
+// How long a replay's VirtualClock waits after the clock was last used before checking whether every goroutine is
+// blocked and waking the next one
+const replaySettle = 5 * time.Millisecond
+
+func init() {
+	if os.Getenv(ClockEnvVar) == "replay" {
+		SetClock(clock.NewVirtualClock(clock.Epoch, replaySettle))
+	}
+}
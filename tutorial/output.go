@@ -0,0 +1,415 @@
+package tutorial
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind identifies which helper produced an Output
+type Kind string
+
+const (
+	// KindSection is a heading printed by Section
+	KindSection Kind = "section"
+	// KindTypeValue is a line printed by TypeValue
+	KindTypeValue Kind = "typevalue"
+	// KindTick is a line printed by Tick
+	KindTick Kind = "tick"
+	// KindLine is anything a lesson printed itself (with fmt.Printf and friends)
+	KindLine Kind = "line"
+)
+
+// An Output is a single piece of text printed while a lesson was running, along with where it came from
+type Output struct {
+	Kind Kind      `json:"kind"`
+	Time time.Time `json:"time"`
+
+	// The ID of the lesson and the name of the registered section that were running
+	Lesson  string `json:"lesson,omitempty"`
+	Section string `json:"section,omitempty"`
+
+	// The title passed to the most recent call to Section
+	Heading string `json:"heading,omitempty"`
+
+	// The text exactly as it would have been printed to a terminal
+	Text string `json:"text"`
+
+	// For output from TypeValue, the Go type name and the value formatted with %v
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value,omitempty"`
+
+	// The file and line number in the lesson that called the helper. Not available for lines the lesson printed itself.
+	Source string `json:"source,omitempty"`
+}
+
+// A Sink receives everything printed while a lesson is running. Calls to Write are serialised, so a Sink does not
+// need to be goroutine-safe unless it is read from while a lesson is running.
+type Sink interface {
+	Write(o Output)
+}
+
+// NewWriterSink creates a Sink that prints output to the supplied writer as it would appear in a terminal
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+type writerSink struct {
+	w io.Writer
+}
+
+func (ws *writerSink) Write(o Output) {
+	io.WriteString(ws.w, o.Text)
+}
+
+// NewJSONSink creates a Sink that writes each Output to the supplied writer as a single line of JSON (NDJSON)
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{e: json.NewEncoder(w)}
+}
+
+type jsonSink struct {
+	e *json.Encoder
+}
+
+func (js *jsonSink) Write(o Output) {
+	js.e.Encode(o)
+}
+
+// A Capture is a Sink that keeps everything it is sent in memory. It is goroutine-safe, so it can be inspected while a
+// lesson is still running.
+type Capture struct {
+	mu      sync.Mutex
+	outputs []Output
+}
+
+// A CapturedLine is a single line of captured text and the lesson, section and heading it was printed under
+type CapturedLine struct {
+	Lesson  string
+	Section string
+	Heading string
+	Text    string
+}
+
+// Write records the output
+func (c *Capture) Write(o Output) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.outputs = append(c.outputs, o)
+}
+
+// Outputs returns a copy of everything captured so far
+func (c *Capture) Outputs() []Output {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Output(nil), c.outputs...)
+}
+
+// String returns the captured text as it would have appeared in a terminal
+func (c *Capture) String() string {
+
+	var b strings.Builder
+
+	for _, o := range c.Outputs() {
+		b.WriteString(o.Text)
+	}
+
+	return b.String()
+}
+
+// Lines splits the captured text into lines (without line endings). A line printed in several pieces is attributed to
+// the section that was running when the line was started.
+func (c *Capture) Lines() []CapturedLine {
+
+	var lines []CapturedLine
+	var current *CapturedLine
+
+	for _, o := range c.Outputs() {
+
+		text := o.Text
+
+		for text != "" {
+
+			if current == nil {
+				current = &CapturedLine{Lesson: o.Lesson, Section: o.Section, Heading: o.Heading}
+			}
+
+			i := strings.Index(text, "\n")
+
+			if i < 0 {
+				current.Text += text
+				break
+			}
+
+			current.Text += text[:i]
+			lines = append(lines, *current)
+			current = nil
+			text = text[i+1:]
+		}
+	}
+
+	if current != nil {
+		lines = append(lines, *current)
+	}
+
+	return lines
+}
+
+// SetSink replaces the destination for everything printed by the helpers in this package. While a lesson section
+// is running with a sink other than the default, the lesson's own writes to os.Stdout are routed to the sink too.
+func SetSink(s Sink) {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+
+	out.sink = s
+	out.isDefault = false
+}
+
+// SetOutput is shorthand for SetSink(NewWriterSink(w))
+func SetOutput(w io.Writer) {
+	SetSink(NewWriterSink(w))
+}
+
+// ResetOutput restores the default behaviour of printing straight to os.Stdout
+func ResetOutput() {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+
+	out.sink = NewWriterSink(os.Stdout)
+	out.isDefault = true
+}
+
+var out = &dispatcher{sink: NewWriterSink(os.Stdout), isDefault: true}
+
+// OutputEnvVar is the name of an environment variable that selects how lessons print their output. If it is set to
+// "ndjson", everything is written to stdout as a stream of JSON Outputs, one per line. Any other value (or no value)
+// prints text as normal.
+const OutputEnvVar = "GOTRAINING_OUTPUT"
+
+func init() {
+	if os.Getenv(OutputEnvVar) == "ndjson" {
+		SetSink(NewJSONSink(os.Stdout))
+	}
+}
+
+// caller returns the file (with the directory it is in) and line number of the function that called a helper
+func caller() string {
+
+	// Skip caller itself and the helper
+	_, file, line, okay := runtime.Caller(2)
+
+	if !okay {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d", filepath.ToSlash(filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))), line)
+}
+
+// recordSeparator marks a line written to a redirected stdout as an encoded Output rather than ordinary text
+const recordSeparator = "\x1e"
+
+// dispatcher sends output to the current sink, keeping track of which lesson, section and heading it belongs to.
+type dispatcher struct {
+	mu        sync.Mutex
+	sink      Sink
+	isDefault bool
+
+	lesson  string
+	section string
+	heading string
+
+	// While a section is running with a non-default sink, os.Stdout is replaced with the write end of a pipe. Helpers
+	// encode their output onto the same pipe so it stays in order with the lesson's own fmt.Printf calls.
+	pipe *os.File
+}
+
+func (d *dispatcher) emit(o Output) {
+
+	o.Time = Now()
+
+	d.mu.Lock()
+	pipe := d.pipe
+
+	if pipe == nil {
+		d.deliver(o)
+		d.mu.Unlock()
+		return
+	}
+
+	d.mu.Unlock()
+
+	// The pipe is written to without the lock held, as the goroutine draining it needs the lock to deliver
+	if b, err := json.Marshal(o); err == nil {
+		pipe.Write([]byte(recordSeparator + string(b) + "\n"))
+	}
+}
+
+// deliver must be called with the lock held
+func (d *dispatcher) deliver(o Output) {
+
+	if o.Kind == KindSection {
+		d.heading = o.Heading
+	}
+
+	o.Lesson, o.Section, o.Heading = d.lesson, d.section, d.heading
+
+	d.sink.Write(o)
+}
+
+// stdout guards the replacement of os.Stdout while a section runs. It is held for the whole section, so two sections
+// can't both replace os.Stdout and then restore it in the wrong order.
+var stdout sync.Mutex
+
+// run runs f as the named section of a lesson
+func (d *dispatcher) run(lesson, section string, f func()) {
+
+	d.mu.Lock()
+	d.lesson, d.section, d.heading = lesson, section, ""
+	redirect := !d.isDefault
+	d.mu.Unlock()
+
+	if !redirect {
+		f()
+		return
+	}
+
+	stdout.Lock()
+	defer stdout.Unlock()
+
+	r, w, err := os.Pipe()
+
+	if err != nil {
+		f()
+		return
+	}
+
+	drained := make(chan bool)
+
+	go d.drain(r, drained)
+
+	// The pipe is set and os.Stdout replaced together, so helpers never write to the pipe while the lesson's own
+	// output is still going to the real stdout (or the other way round)
+	d.mu.Lock()
+	original := os.Stdout
+	os.Stdout = w
+	d.pipe = w
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		d.pipe = nil
+		os.Stdout = original
+		d.mu.Unlock()
+
+		w.Close()
+
+		<-drained
+		r.Close()
+	}()
+
+	f()
+}
+
+// drain reads everything written to a redirected stdout and delivers it to the sink.
+//
+// Text the lesson printed itself can't be timestamped as it is written, as os.Stdout has to be an *os.File. Instead
+// each read from the pipe is timestamped as soon as it returns, before waiting for the lock, and a line printed in
+// several pieces gets the time its first piece was read. With a VirtualClock this is the time the text was written:
+// the clock doesn't move while this goroutine has something to read (see clock.VirtualClock). With the RealClock it is later
+// by however long the pipe took to deliver it.
+func (d *dispatcher) drain(r io.Reader, drained chan bool) {
+
+	buf := make([]byte, 32*1024)
+
+	// A line that hasn't been finished yet, and the time its first piece was read
+	var partial string
+	var started time.Time
+
+	for {
+		n, err := r.Read(buf)
+		at := Now()
+
+		if n > 0 {
+
+			if partial == "" {
+				started = at
+			}
+
+			text := partial + string(buf[:n])
+			partial = ""
+
+			var lines []string
+
+			if i := strings.LastIndex(text, "\n"); i < len(text)-1 {
+				text, partial = text[:i+1], text[i+1:]
+			}
+
+			if text != "" {
+				lines = strings.SplitAfter(text, "\n")
+			}
+
+			d.mu.Lock()
+
+			for _, line := range lines {
+				if line != "" {
+					d.decode(line, started)
+					started = at
+				}
+			}
+
+			d.mu.Unlock()
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	if partial != "" {
+		d.mu.Lock()
+		d.decode(partial, started)
+		d.mu.Unlock()
+	}
+
+	drained <- true
+}
+
+// decode splits a line read from the pipe into ordinary text, which is given the time it was read, and encoded
+// Outputs, which carry the time they were written. Must be called with the lock held.
+func (d *dispatcher) decode(line string, read time.Time) {
+
+	for line != "" {
+
+		i := strings.Index(line, recordSeparator)
+
+		if i < 0 {
+			d.deliver(Output{Kind: KindLine, Time: read, Text: line})
+			return
+		}
+
+		if i > 0 {
+			// Text printed without a trailing newline before a helper was called
+			d.deliver(Output{Kind: KindLine, Time: read, Text: line[:i]})
+		}
+
+		record := line[i+len(recordSeparator):]
+		line = ""
+
+		if end := strings.Index(record, "\n"); end >= 0 {
+			record, line = record[:end], record[end+1:]
+		}
+
+		var o Output
+
+		if err := json.Unmarshal([]byte(record), &o); err == nil {
+			d.deliver(o)
+		}
+	}
+}
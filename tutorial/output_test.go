@@ -0,0 +1,91 @@
+package tutorial
+
+import (
+	"fmt"
+	"github.com/benhalstead/gotraining/clock"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutputIsTimestampedWhenWritten(t *testing.T) {
+
+	previous := CurrentClock()
+	defer SetClock(previous)
+
+	SetClock(clock.NewVirtualClock(clock.Epoch, time.Millisecond))
+
+	capture := new(Capture)
+	SetSink(capture)
+	defer ResetOutput()
+
+	original := os.Stdout
+
+	out.run("lesson", "section", func() {
+		fmt.Println("before")
+		Sleep(time.Second)
+		fmt.Print("after")
+		Sleep(time.Second)
+		fmt.Println(", and later")
+	})
+
+	if os.Stdout != original {
+		t.Errorf("Expected os.Stdout to be restored")
+	}
+
+	expected := []struct {
+		text string
+		at   time.Duration
+	}{
+		{"before\n", 0},
+		// A line printed in pieces has the time its first piece was printed
+		{"after, and later\n", time.Second},
+	}
+
+	got := capture.Outputs()
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d outputs, got %v", len(expected), got)
+	}
+
+	for i, e := range expected {
+		if got[i].Text != e.text || !got[i].Time.Equal(clock.Epoch.Add(e.at)) {
+			t.Errorf("Expected %q at %v, got %q at %v", e.text, clock.Epoch.Add(e.at), got[i].Text, got[i].Time)
+		}
+	}
+}
+
+func TestSectionsRunningAtOnceRestoreStdout(t *testing.T) {
+
+	capture := new(Capture)
+	SetSink(capture)
+	defer ResetOutput()
+
+	original := os.Stdout
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			out.run("lesson", fmt.Sprint(i), func() {
+				fmt.Println(i)
+			})
+		}(i)
+	}
+
+	wg.Wait()
+
+	if os.Stdout != original {
+		t.Errorf("Expected os.Stdout to be restored")
+	}
+
+	if lines := capture.Lines(); len(lines) != 4 {
+		t.Errorf("Expected 4 lines, got %v", lines)
+	}
+}
@@ -0,0 +1,97 @@
+package tutorial
+
+// A Chapter is a titled group of lessons from the suggested reading order in the README
+type Chapter struct {
+	Title   string
+	Lessons []string
+}
+
+// ReadingOrder lists the ID of every lesson in the order suggested by the README. Keep the two in step when adding
+// new lessons.
+var ReadingOrder = []Chapter{
+	{
+		Title: "Foundations",
+		Lessons: []string{
+			"output/output",
+		},
+	},
+	{
+		Title: "Types, variables and control structures",
+		Lessons: []string{
+			"variablestypes/variables",
+			"variablestypes/builtin",
+			"variablestypes/consts",
+			"controlstructures/ifelse",
+			"controlstructures/forloop",
+			"controlstructures/switch",
+			"functions/basics",
+		},
+	},
+	{
+		Title: "Data structures",
+		Lessons: []string{
+			"structures/slices",
+			"structures/maps",
+			"structures/pointers",
+			"structures/structs",
+			"structures/methods",
+			"variablestypes/interfaces",
+			"structures/tags",
+		},
+	},
+	{
+		Title: "Error handling",
+		Lessons: []string{
+			"errorhandling/errors",
+			"errorhandling/panic",
+		},
+	},
+	{
+		Title: "Testing",
+		Lessons: []string{
+			"unittests/code",
+		},
+	},
+	{
+		Title: "Advanced functions",
+		Lessons: []string{
+			"functions/variadic",
+			"functions/defer",
+			"functions/types",
+			"functions/closures",
+		},
+	},
+	{
+		Title: "Concurrency",
+		Lessons: []string{
+			"concurrency/goroutines",
+			"concurrency/channels",
+			"concurrency/mutex",
+			"concurrency/context",
+		},
+	},
+	{
+		Title: "Essential packages",
+		Lessons: []string{
+			"essential/bufio",
+			"essential/json",
+			"essential/http",
+			"essential/regexp",
+			"essential/strconv",
+			"essential/strings",
+			"essential/time",
+		},
+	},
+}
+
+// LessonIDs returns the ID of every lesson in reading order
+func LessonIDs() []string {
+
+	var ids []string
+
+	for _, c := range ReadingOrder {
+		ids = append(ids, c.Lessons...)
+	}
+
+	return ids
+}
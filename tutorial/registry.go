@@ -0,0 +1,156 @@
+package tutorial
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// A Lesson is one runnable source file in this repository. It is identified by its topic (the directory the file
+// lives in) and its name (the file name without .go), so the lesson in concurrency/channels.go has the ID
+// concurrency/channels
+type Lesson struct {
+	Topic    string
+	Name     string
+	sections []*section
+}
+
+type section struct {
+	name string
+	run  func()
+}
+
+var registered []*Lesson
+
+// Register creates a new lesson and adds it to the registry. Each lesson's main function should register itself,
+// add its sections and then call Run:
+//
+//	tutorial.Register("concurrency", "channels").
+//		Section("closing", closingExamples).
+//		Section("select", selectExample).
+//		Run()
+func Register(topic, name string) *Lesson {
+
+	l := &Lesson{
+		Topic: topic,
+		Name:  name,
+	}
+
+	if Find(l.ID()) != nil {
+		panic(fmt.Sprintf("lesson %s has already been registered", l.ID()))
+	}
+
+	registered = append(registered, l)
+
+	return l
+}
+
+// Lessons returns every registered lesson in the order they were registered
+func Lessons() []*Lesson {
+	return registered
+}
+
+// Find returns the registered lesson with the supplied ID or nil if there is no such lesson
+func Find(id string) *Lesson {
+
+	for _, l := range registered {
+		if l.ID() == id {
+			return l
+		}
+	}
+
+	return nil
+}
+
+// ID returns the topic and name of the lesson separated by a slash
+func (l *Lesson) ID() string {
+	return l.Topic + "/" + l.Name
+}
+
+// Section adds a named part of the lesson that can be run on its own. Sections run in the order they are added.
+func (l *Lesson) Section(name string, f func()) *Lesson {
+
+	if l.find(name) != nil {
+		panic(fmt.Sprintf("lesson %s already has a section named %s", l.ID(), name))
+	}
+
+	l.sections = append(l.sections, &section{name: name, run: f})
+
+	return l
+}
+
+// Sections returns the names of the lesson's sections in the order they were added
+func (l *Lesson) Sections() []string {
+
+	names := make([]string, len(l.sections))
+
+	for i, s := range l.sections {
+		names[i] = s.name
+	}
+
+	return names
+}
+
+// RunSection runs the named section of the lesson, returning an error if the lesson has no such section
+func (l *Lesson) RunSection(name string) error {
+
+	s := l.find(name)
+
+	if s == nil {
+		return fmt.Errorf("lesson %s has no section named %s (has %s)", l.ID(), name, strings.Join(l.Sections(), ", "))
+	}
+
+	out.run(l.ID(), s.name, s.run)
+
+	return nil
+}
+
+// RunAll runs every section of the lesson in order
+func (l *Lesson) RunAll() {
+	for _, s := range l.sections {
+		out.run(l.ID(), s.name, s.run)
+	}
+}
+
+// Run is called from a lesson's main function. With no command line arguments it runs every section. Otherwise each
+// argument is treated as the name of a section to run. The -list flag prints the names of the sections instead.
+func (l *Lesson) Run() {
+
+	fs := flag.NewFlagSet(l.ID(), flag.ExitOnError)
+	list := fs.Bool("list", false, "print the names of this lesson's sections and exit")
+
+	fs.Parse(os.Args[1:])
+
+	if *list {
+		for _, n := range l.Sections() {
+			fmt.Println(n)
+		}
+
+		// Exit straight away so nothing deferred in the lesson's main function is printed after the list
+		os.Exit(0)
+	}
+
+	if fs.NArg() == 0 {
+		l.RunAll()
+		return
+	}
+
+	for _, n := range fs.Args() {
+		if err := l.RunSection(n); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+func (l *Lesson) find(name string) *section {
+
+	for _, s := range l.sections {
+		if s.name == name {
+			return s
+		}
+	}
+
+	return nil
+}
@@ -6,18 +6,23 @@ import (
 )
 
 func Section(m string) {
-	fmt.Printf("\n\n%s:\n\n", m)
+	out.emit(Output{Kind: KindSection, Heading: m, Text: fmt.Sprintf("\n\n%s:\n\n", m), Source: caller()})
 }
 
 func TypeValue(i interface{}) {
-	fmt.Printf("Type: %T Value: %v\n", i, i)
+
+	t, v := fmt.Sprintf("%T", i), fmt.Sprintf("%v", i)
+
+	out.emit(Output{Kind: KindTypeValue, Text: fmt.Sprintf("Type: %s Value: %s\n", t, v), Type: t, Value: v, Source: caller()})
 }
 
 func Tick(count, ms int) {
 
+	source := caller()
+
 	for i := 0; i < count; i++ {
-		fmt.Println("Tick")
-		time.Sleep(time.Millisecond * time.Duration(ms))
+		out.emit(Output{Kind: KindTick, Text: "Tick\n", Source: source})
+		Sleep(time.Millisecond * time.Duration(ms))
 	}
 
 }
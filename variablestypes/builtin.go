@@ -9,6 +9,16 @@ import (
 // There a small number of built-in, basic or native types
 func main() {
 
+	ann.Register("variablestypes", "builtin").
+		Section("booleans", booleansExample).
+		Section("strings", stringsExample).
+		Section("integers", integersExample).
+		Section("floats", floatsExample).
+		Run()
+}
+
+func booleansExample() {
+
 	//Booleans
 	ann.Section("Booleans")
 	a := true
@@ -25,6 +35,9 @@ func main() {
 	} else {
 		fmt.Println("Unable to parse as a bool")
 	}
+}
+
+func stringsExample() {
 
 	//Strings
 	ann.Section("Strings")
@@ -48,6 +61,9 @@ func main() {
 	c := '😐'
 
 	fmt.Printf("%T\n", c)
+}
+
+func integersExample() {
 
 	//Integers
 	ann.Section("Sized integers")
@@ -77,6 +93,9 @@ func main() {
 	fmt.Printf("%T %T\n", pi, i64)
 
 	//Most Go code just uses int and uint, but I have seen problems with this approach with 32 bit Windows.
+}
+
+func floatsExample() {
 
 	ann.Section("Floats")
 
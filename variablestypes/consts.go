@@ -1,5 +1,7 @@
 package main
 
+import "github.com/benhalstead/gotraining/tutorial"
+
 //Consts are immutable values that can be declared in various scopes
 //They are generally decared at the package level with either package scope (lowercase first character)
 
@@ -42,4 +44,6 @@ const (
 
 func main() {
 
+	// Everything in this lesson is a declaration, so there are no sections to run
+	tutorial.Register("variablestypes", "consts").Run()
 }
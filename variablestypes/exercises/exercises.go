@@ -0,0 +1,41 @@
+// Package exercises contains exercises for the variables, types and interfaces lessons (variablestypes/*.go). Replace
+// the body of each function so that it does what its comment describes, then check your answers with:
+//
+//	gotraining grade variablestypes
+package exercises
+
+type Celsius float64
+type Fahrenheit float64
+
+// ToFahrenheit converts a temperature in Celsius to Fahrenheit (multiply by 9, divide by 5 and add 32)
+func ToFahrenheit(c Celsius) Fahrenheit {
+	// TODO
+	return 0
+}
+
+// RuneCount returns the number of characters (runes) in s, which is not always the same as the number of bytes
+func RuneCount(s string) int {
+	// TODO
+	return 0
+}
+
+// Shape is implemented by anything that has an area
+type Shape interface {
+	Area() float64
+}
+
+type Square struct {
+	Side float64
+}
+
+// Area makes Square implement Shape. It should return the area of the square.
+func (s Square) Area() float64 {
+	// TODO
+	return 0
+}
+
+// IsShape reports whether the value in i implements Shape
+func IsShape(i interface{}) bool {
+	// TODO
+	return false
+}
@@ -0,0 +1,45 @@
+package exercises
+
+import "testing"
+
+// Hint: Celsius and Fahrenheit are both based on float64, so you can convert between them with Fahrenheit(...).
+func TestToFahrenheit(t *testing.T) {
+
+	if f := ToFahrenheit(100); f != 212 {
+		t.Errorf("Expected 100C to be 212F but found %v", f)
+	}
+
+	if f := ToFahrenheit(-40); f != -40 {
+		t.Errorf("Expected -40C to be -40F but found %v", f)
+	}
+}
+
+// Hint: ranging over a string visits each rune, but len(s) counts bytes. See builtin.go.
+func TestRuneCount(t *testing.T) {
+
+	if c := RuneCount("Hello 😐!"); c != 8 {
+		t.Errorf("Expected 8 runes but found %d", c)
+	}
+}
+
+// Hint: a method with a value receiver has a copy of the struct, so s.Side is available.
+func TestArea(t *testing.T) {
+
+	var s Shape = Square{Side: 3}
+
+	if a := s.Area(); a != 9 {
+		t.Errorf("Expected an area of 9 but found %v", a)
+	}
+}
+
+// Hint: use a type assertion with the 'okay' form, like the examples at the end of interfaces.go.
+func TestIsShape(t *testing.T) {
+
+	if !IsShape(Square{}) {
+		t.Errorf("Expected Square to be a Shape")
+	}
+
+	if IsShape(1) {
+		t.Errorf("Expected 1 not to be a Shape")
+	}
+}
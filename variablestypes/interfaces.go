@@ -58,6 +58,17 @@ type Marker interface {
 
 func main() {
 
+	tutorial.Register("variablestypes", "interfaces").
+		Section("underlying-type", underlyingTypeExample).
+		Section("type-assertion", typeAssertionExample).
+		Section("passing", passingToFunctionsExample).
+		Section("inadvertent", inadvertentImplementationExample).
+		Section("converting", convertingToInterfaceExample).
+		Run()
+}
+
+func underlyingTypeExample() {
+
 	// In Go, any type (not just structs) can implement an interface. An interface is considered to be implemented as long
 	// as the type has exactly the same set of methods with the same method signatures as those defined by an interface
 
@@ -75,9 +86,14 @@ func main() {
 	i = mLength(1)
 
 	fmt.Printf("i is %T\n", i)
+}
+
+func typeAssertionExample() {
 
 	tutorial.Section("Type assertion")
 
+	var i Measurable = mLength(1)
+
 	// Given an interface, you can attempt to 'cast' it to an underlying type using a type assertion
 	// This is the safe way if it's possible that the type is something other than you think
 	if length, okay := i.(mLength); okay {
@@ -92,6 +108,9 @@ func main() {
 	fmt.Printf("Value of mLength %d\n", length)
 
 	// If you get that wrong, a panic will be thrown
+}
+
+func passingToFunctionsExample() {
 
 	tutorial.Section("Passing to functions")
 
@@ -100,9 +119,14 @@ func main() {
 
 	var p Person
 	accept(p)
+}
+
+func inadvertentImplementationExample() {
 
 	tutorial.Section("Inadvertent implementations")
 
+	var p Person
+
 	//Sometimes you do not know your code is implementing an interface! If your type has a method
 	// String() string
 	// It is actually implementing the fmt.Stringer interface and the behaviour of the %v verb in the printf functions will change
@@ -111,9 +135,14 @@ func main() {
 	p.Last = "Name"
 
 	fmt.Printf("Value using %%v is %v\n", p)
+}
+
+func convertingToInterfaceExample() {
 
 	tutorial.Section("Converting concrete type back to an interface")
 
+	p := Person{First: "My", Last: "Name"}
+
 	// This is possible using type assertions, but you have to do it via an interface{} variable
 
 	// Convert Person back to Named
@@ -14,6 +14,15 @@ var counter int = 0
 
 func main() {
 
+	tutorial.Register("variablestypes", "variables").
+		Section("declarations", declarationsExample).
+		Section("interface-values", interfaceValuesExample).
+		Section("zero-values", zeroValuesExample).
+		Run()
+}
+
+func declarationsExample() {
+
 	// A variable can be declared with a specific type, declared and initialised in one statement
 	tutorial.Section("Declarations and initialisation")
 
@@ -32,6 +41,9 @@ func main() {
 
 	// fmt.Printf is Go's standard print function. The %T verb allows you to print the type of a variable
 	fmt.Printf("%T %T %T %T\n", x, n, y, z)
+}
+
+func interfaceValuesExample() {
 
 	tutorial.Section("interface{} values")
 
@@ -45,6 +57,9 @@ func main() {
 
 	v = "1"
 	fmt.Printf("%T\n", v)
+}
+
+func zeroValuesExample() {
 
 	// Unlike C, a declared but not initialised variable has a predictable value according to its type
 	tutorial.Section("Zero values")
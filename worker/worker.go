@@ -0,0 +1,268 @@
+// Package worker runs functions in goroutines that can be told to stop.
+//
+// A goroutine can't be stopped from the outside: it has to notice that it should stop and return. Every worker is
+// given a context.Context and is expected to return promptly once ctx.Done() is closed. Stopping a worker cancels its
+// context, and because the contexts of workers started inside another worker are derived from their parent's context,
+// stopping (or timing out) a worker stops everything it started as well. A worker whose function returns stops
+// everything it started too, and waits for them.
+//
+//	w := worker.StartWithTimeout(context.Background(), "poller", time.Second, func(ctx context.Context) error {
+//
+//		for {
+//			select {
+//			case <-ctx.Done():
+//				return ctx.Err()
+//			case <-time.After(100 * time.Millisecond):
+//				fmt.Println("Polling")
+//			}
+//		}
+//	})
+//
+//	fmt.Println(w.Wait()) // deadline exceeded
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Reason describes why a worker stopped
+type Reason int
+
+const (
+	// The worker is still running
+	Running Reason = iota
+
+	// The worker's function returned of its own accord without an error
+	Finished
+
+	// The worker's function returned an error of its own accord
+	Failed
+
+	// The worker (or one of the workers that started it) was stopped
+	Cancelled
+
+	// The worker's deadline (or the deadline of one of the workers that started it) passed
+	DeadlineExceeded
+)
+
+func (r Reason) String() string {
+
+	switch r {
+	case Running:
+		return "running"
+	case Finished:
+		return "finished"
+	case Failed:
+		return "failed"
+	case Cancelled:
+		return "cancelled"
+	case DeadlineExceeded:
+		return "deadline exceeded"
+	}
+
+	return "unknown"
+}
+
+// Func is the work a worker does. It must return once ctx.Done() is closed.
+type Func func(ctx context.Context) error
+
+// A Worker is a function running in its own goroutine
+type Worker struct {
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	reason   Reason
+	err      error
+	children []*Worker
+	running  sync.WaitGroup
+
+	// Set once the worker's function has returned, after which it doesn't adopt any more workers
+	stopping bool
+}
+
+// Start runs fn in a new goroutine until it returns or the worker is stopped
+func Start(parent context.Context, name string, fn Func) *Worker {
+
+	ctx, cancel := context.WithCancel(parent)
+
+	return start(parent, ctx, cancel, name, fn)
+}
+
+// StartWithTimeout runs fn in a new goroutine until it returns, the worker is stopped or timeout has passed
+func StartWithTimeout(parent context.Context, name string, timeout time.Duration, fn Func) *Worker {
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+
+	return start(parent, ctx, cancel, name, fn)
+}
+
+// StartWithDeadline runs fn in a new goroutine until it returns, the worker is stopped or the deadline passes
+func StartWithDeadline(parent context.Context, name string, deadline time.Time, fn Func) *Worker {
+
+	ctx, cancel := context.WithDeadline(parent, deadline)
+
+	return start(parent, ctx, cancel, name, fn)
+}
+
+// The key for the Worker stored in each worker's context
+type workerKey struct{}
+
+func start(parent, ctx context.Context, cancel context.CancelFunc, name string, fn Func) *Worker {
+
+	w := &Worker{
+		name:   name,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	w.ctx = context.WithValue(ctx, workerKey{}, w)
+
+	// Workers started with another worker's context belong to that worker, which stops them and waits for them once
+	// its own function returns
+	if p, okay := parent.Value(workerKey{}).(*Worker); okay {
+		p.adopt(w)
+	}
+
+	go w.run(fn)
+
+	return w
+}
+
+// adopt makes child one of the workers w waits for. Once w's function has returned it is too late: w may already be
+// waiting, and child's context is cancelled (or about to be) anyway, so it isn't adopted.
+func (w *Worker) adopt(child *Worker) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopping {
+		return
+	}
+
+	w.children = append(w.children, child)
+	w.running.Add(1)
+
+	go func() {
+		<-child.done
+		w.running.Done()
+	}()
+}
+
+func (w *Worker) run(fn Func) {
+
+	err := fn(w.ctx)
+
+	// The reason is decided now, as the context may be cancelled while the workers this one started are stopping
+	reason := reasonFor(w.ctx, err)
+
+	w.mu.Lock()
+	w.stopping = true
+	w.mu.Unlock()
+
+	// Stop the workers this one started (releasing the context's resources and stopping any timer it has), then wait
+	// for them before saying it has stopped
+	w.cancel()
+	w.running.Wait()
+
+	w.mu.Lock()
+	w.err = err
+	w.reason = reason
+	w.mu.Unlock()
+
+	close(w.done)
+}
+
+// reasonFor decides why a worker stopped. If its context has ended, the worker is assumed to have returned because of
+// that, even if it returned nil.
+func reasonFor(ctx context.Context, err error) Reason {
+
+	switch ctx.Err() {
+	case context.Canceled:
+		return Cancelled
+	case context.DeadlineExceeded:
+		return DeadlineExceeded
+	}
+
+	if err != nil {
+		return Failed
+	}
+
+	return Finished
+}
+
+// Name returns the name the worker was started with
+func (w *Worker) Name() string {
+	return w.name
+}
+
+// Context returns the worker's context, which can be used to start workers that belong to it
+func (w *Worker) Context() context.Context {
+	return w.ctx
+}
+
+// Cancel tells the worker, and every worker it started, to stop. It does not wait for them to stop.
+func (w *Worker) Cancel() {
+	w.cancel()
+}
+
+// Stop tells the worker, and every worker it started, to stop and waits until they all have
+func (w *Worker) Stop() Reason {
+	w.cancel()
+
+	return w.Wait()
+}
+
+// Wait waits for the worker and every worker it started to stop and returns the reason it stopped
+func (w *Worker) Wait() Reason {
+	<-w.done
+
+	return w.Reason()
+}
+
+// Done returns a channel that is closed once the worker and every worker it started have stopped
+func (w *Worker) Done() <-chan struct{} {
+	return w.done
+}
+
+// Reason returns why the worker stopped, or Running if it hasn't stopped yet
+func (w *Worker) Reason() Reason {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.reason
+}
+
+// Err returns the error the worker's function returned, if it has stopped
+func (w *Worker) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+// Children returns the workers started with this worker's context, in the order they were started
+func (w *Worker) Children() []*Worker {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]*Worker(nil), w.children...)
+}
+
+// Sleep pauses the current goroutine for d or until ctx is done, whichever comes first. It returns ctx.Err() if it
+// was interrupted.
+func Sleep(ctx context.Context, d time.Duration) error {
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestReasons(t *testing.T) {
+
+	before := runtime.NumGoroutine()
+
+	if r := Start(context.Background(), "finished", func(ctx context.Context) error { return nil }).Wait(); r != Finished {
+		t.Errorf("Expected finished, got %s", r)
+	}
+
+	failure := errors.New("failed")
+
+	w := Start(context.Background(), "failed", func(ctx context.Context) error { return failure })
+
+	if r := w.Wait(); r != Failed || w.Err() != failure {
+		t.Errorf("Expected failed with an error, got %s %v", r, w.Err())
+	}
+
+	if r := Start(context.Background(), "cancelled", block).Stop(); r != Cancelled {
+		t.Errorf("Expected cancelled, got %s", r)
+	}
+
+	if r := StartWithTimeout(context.Background(), "timeout", 10*time.Millisecond, block).Wait(); r != DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded, got %s", r)
+	}
+
+	if r := StartWithDeadline(context.Background(), "deadline", time.Now().Add(10*time.Millisecond), block).Wait(); r != DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded, got %s", r)
+	}
+
+	noneLeft(t, before)
+}
+
+func TestStoppingPropagatesToNestedWorkers(t *testing.T) {
+
+	before := runtime.NumGoroutine()
+	started := make(chan bool)
+
+	var inner *Worker
+
+	outer := Start(context.Background(), "outer", func(ctx context.Context) error {
+
+		Start(ctx, "middle", func(ctx context.Context) error {
+
+			inner = Start(ctx, "inner", block)
+			started <- true
+
+			return block(ctx)
+		})
+
+		return block(ctx)
+	})
+
+	<-started
+
+	if r := inner.Reason(); r != Running {
+		t.Fatalf("Expected the inner worker to be running, got %s", r)
+	}
+
+	if r := outer.Stop(); r != Cancelled {
+		t.Errorf("Expected the outer worker to be cancelled, got %s", r)
+	}
+
+	// Stop only returns once every nested worker has stopped
+	middle := outer.Children()[0]
+
+	if middle.Reason() != Cancelled || inner.Reason() != Cancelled {
+		t.Errorf("Expected nested workers to be cancelled, got %s and %s", middle.Reason(), inner.Reason())
+	}
+
+	noneLeft(t, before)
+}
+
+func TestDeadlinePropagatesToNestedWorkers(t *testing.T) {
+
+	before := runtime.NumGoroutine()
+
+	var own *Worker
+
+	outer := StartWithTimeout(context.Background(), "outer", 50*time.Millisecond, func(ctx context.Context) error {
+
+		Start(ctx, "inherited", block)
+		own = StartWithTimeout(ctx, "own", time.Hour, block)
+
+		return block(ctx)
+	})
+
+	if r := outer.Wait(); r != DeadlineExceeded {
+		t.Errorf("Expected the outer worker's deadline to pass, got %s", r)
+	}
+
+	for _, c := range outer.Children() {
+		if c.Reason() != DeadlineExceeded {
+			t.Errorf("Expected %s to stop because its parent's deadline passed, got %s", c.Name(), c.Reason())
+		}
+	}
+
+	if own.Err() != context.DeadlineExceeded {
+		t.Errorf("Expected the worker's function to see the deadline, got %v", own.Err())
+	}
+
+	noneLeft(t, before)
+}
+
+func TestStoppingAChildDoesNotStopItsParent(t *testing.T) {
+
+	child := make(chan *Worker)
+
+	parent := Start(context.Background(), "parent", func(ctx context.Context) error {
+		child <- Start(ctx, "child", block)
+		return block(ctx)
+	})
+
+	if r := (<-child).Stop(); r != Cancelled {
+		t.Errorf("Expected the child to be cancelled, got %s", r)
+	}
+
+	if r := parent.Reason(); r != Running {
+		t.Errorf("Expected the parent to still be running, got %s", r)
+	}
+
+	parent.Stop()
+}
+
+func TestFinishingStopsChildren(t *testing.T) {
+
+	before := runtime.NumGoroutine()
+	waiting := make(chan bool)
+
+	var parent *Worker
+
+	outer := Start(context.Background(), "outer", func(ctx context.Context) error {
+
+		parent = Start(ctx, "parent", func(ctx context.Context) error {
+
+			// Takes a while to stop, so outer is stopped while parent is waiting for it
+			Start(ctx, "slow", func(ctx context.Context) error {
+				<-ctx.Done()
+				waiting <- true
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			})
+
+			return nil
+		})
+
+		return block(ctx)
+	})
+
+	<-waiting
+	outer.Stop()
+
+	// parent's reason is decided when its function returns, not once its children have stopped
+	if r := parent.Reason(); r != Finished {
+		t.Errorf("Expected the parent to have finished, got %s", r)
+	}
+
+	if r := parent.Children()[0].Reason(); r != Cancelled {
+		t.Errorf("Expected the child to be cancelled when its parent finished, got %s", r)
+	}
+
+	noneLeft(t, before)
+}
+
+func TestWorkersStartedAfterTheParentReturnsAreNotAdopted(t *testing.T) {
+
+	contexts := make(chan context.Context, 1)
+
+	parent := Start(context.Background(), "parent", func(ctx context.Context) error {
+		contexts <- ctx
+		return nil
+	})
+
+	ctx := <-contexts
+
+	// Starting workers while the parent is stopping must not make it wait for them
+	for i := 0; i < 100; i++ {
+		Start(ctx, "late", block)
+	}
+
+	parent.Wait()
+
+	if r := Start(ctx, "late", block).Wait(); r != Cancelled {
+		t.Errorf("Expected a worker started with a stopped parent's context to be cancelled, got %s", r)
+	}
+
+	for _, c := range parent.Children() {
+		if r := c.Wait(); r != Cancelled {
+			t.Errorf("Expected %s to be cancelled, got %s", c.Name(), r)
+		}
+	}
+}
+
+// block waits until it is told to stop
+func block(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// noneLeft fails the test if there are more goroutines running than there were before it started. Goroutines that
+// have finished their work can take a moment to exit, so it gives them a second to do so.
+func noneLeft(t *testing.T, before int) {
+
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+
+		if runtime.NumGoroutine() <= before {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	buf := make([]byte, 1<<16)
+	t.Errorf("Expected %d goroutines but found %d:\n%s", before, runtime.NumGoroutine(), buf[:runtime.Stack(buf, true)])
+}